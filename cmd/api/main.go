@@ -24,13 +24,15 @@ func init() {
 }
 
 func main() {
+	cfg := container.NewConfigFromEnv()
 	cacheStrategies := container.NewCacheStrategies(client)
-	repositories := container.NewRepositoryContainer()
-	services := container.NewServicesContainer(repositories)
-	handlers := container.GetHandlers(services)
+	repositories := container.NewRepositoryContainer(cfg)
+	services := container.NewServicesContainer(repositories, cacheStrategies, cfg)
+	handlers := container.GetHandlers(services, cfg)
 
-	if err := serverhttp.StartServer(handlers, cacheStrategies); err != nil {
+	err := serverhttp.StartServer(handlers, cacheStrategies)
+	services.Close()
+	if err != nil {
 		log.Fatal(err)
 	}
-
 }
@@ -0,0 +1,45 @@
+// Command convertstore rewrites the files of a data directory in another
+// format, e.g. from JSON to zstd compressed MessagePack. The service must be
+// stopped while it runs: the locks of the files only hold within a process,
+// and opening the directory removes the temporary files of the writes in
+// progress.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+func main() {
+	dir := flag.String("dir", "assets/tmp", "data directory")
+	layout := flag.String("layout", "flat", "layout of the directory: flat or sharded")
+	to := flag.String("to", "json", "new format: json, msgpack or protobuf, optionally followed by +gzip or +zstd")
+	flag.Parse()
+
+	dirLayout, err := filesystem.ParseLayout(*layout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	format, err := filesystem.ParseFormat(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fs := filesystem.NewFileSystem(
+		filesystem.WithDir(*dir),
+		filesystem.WithLayout(dirLayout),
+		filesystem.WithFormat(format),
+	)
+
+	converted, err := fs.Convert(context.Background(), repository.NewFileValue)
+	if err != nil {
+		log.Fatalf("Failed to convert %s after %d files: %v", *dir, converted, err)
+	}
+
+	log.Printf("converted %d files to %s", converted, format)
+}
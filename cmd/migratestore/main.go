@@ -0,0 +1,47 @@
+// Command migratestore rewrites at the current schema version the files of
+// the enterprises written at an older one. Older files are migrated on
+// each read anyway, this only spares the migration. The service must be
+// stopped while it runs: the locks of the files only hold within a
+// process, and opening the directory removes the temporary files of the
+// writes in progress.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+func main() {
+	dir := flag.String("dir", "assets/tmp", "data directory")
+	layout := flag.String("layout", "flat", "layout of the directory: flat or sharded")
+	format := flag.String("format", "json", "format of the files written, as DATA_FORMAT")
+	flag.Parse()
+
+	dirLayout, err := filesystem.ParseLayout(*layout)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fileFormat, err := filesystem.ParseFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	repo := repository.NewFileSystemRepo(filesystem.NewFileSystem(
+		filesystem.WithDir(*dir),
+		filesystem.WithLayout(dirLayout),
+		filesystem.WithFormat(fileFormat),
+	))
+
+	migrated, err := repo.MigrateEnterprises(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to migrate %s after %d files: %v", *dir, migrated, err)
+	}
+
+	log.Printf("migrated %d files to schema version %d", migrated, schema.CurrentVersion)
+}
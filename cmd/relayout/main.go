@@ -0,0 +1,37 @@
+// Command relayout moves the files of a data directory from one layout to
+// another, e.g. from the flat "assets/tmp/<host>.json" files to the sharded
+// "assets/tmp/ab/cd/<host>.json" ones. The service must be stopped while it runs.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+func main() {
+	dir := flag.String("dir", "assets/tmp", "data directory")
+	from := flag.String("from", "flat", "current layout: flat or sharded")
+	to := flag.String("to", "sharded", "new layout: flat or sharded")
+	flag.Parse()
+
+	fromLayout, err := filesystem.ParseLayout(*from)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	toLayout, err := filesystem.ParseLayout(*to)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fs := filesystem.NewFileSystem(filesystem.WithDir(*dir), filesystem.WithLayout(toLayout))
+	moved, err := fs.Relayout(context.Background(), fromLayout)
+	if err != nil {
+		log.Fatalf("Failed to relayout %s after moving %d files: %v", *dir, moved, err)
+	}
+
+	log.Printf("moved %d files from the %s to the %s layout", moved, fromLayout, toLayout)
+}
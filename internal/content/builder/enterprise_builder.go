@@ -73,6 +73,30 @@ func (eb *EnterpriseBuilder) WithVideo(video entity.Video) *EnterpriseBuilder {
 	return eb
 }
 
+// WithExperiment sets the Experiment field
+func (eb *EnterpriseBuilder) WithExperiment(experiment *entity.Experiment) *EnterpriseBuilder {
+	eb.enterprise.Experiment = experiment
+	return eb
+}
+
+// WithRegions sets the Regions field
+func (eb *EnterpriseBuilder) WithRegions(regions map[entity.RegionCode]entity.Video) *EnterpriseBuilder {
+	eb.enterprise.Regions = regions
+	return eb
+}
+
+// WithPlaylist sets the Playlist field
+func (eb *EnterpriseBuilder) WithPlaylist(playlist []entity.Video) *EnterpriseBuilder {
+	eb.enterprise.Playlist = playlist
+	return eb
+}
+
+// WithFallback sets the Fallback field
+func (eb *EnterpriseBuilder) WithFallback(fallback *entity.Video) *EnterpriseBuilder {
+	eb.enterprise.Fallback = fallback
+	return eb
+}
+
 // Build returns the constructed Enterprise entity
 func (eb *EnterpriseBuilder) Build() entity.Enterprise {
 	return eb.enterprise
@@ -41,6 +41,12 @@ func (vb *VideoBuilder) WithThumbnailUrl(thumbnailUrl string) *VideoBuilder {
 	return vb
 }
 
+// WithMetadata sets the Metadata field
+func (vb *VideoBuilder) WithMetadata(metadata *entity.VideoMetadata) *VideoBuilder {
+	vb.video.Metadata = metadata
+	return vb
+}
+
 // Build returns the constructed Video entity
 func (vb *VideoBuilder) Build() entity.Video {
 	return vb.video
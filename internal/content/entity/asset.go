@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var assetPathPattern = regexp.MustCompile(`^/assets/([0-9a-f]{64})$`)
+
+// Asset is a file uploaded to the service, such as a video or a thumbnail.
+// Assets are content addressed: Id is the SHA-256 of the file, so its url
+// never changes and never points to a different content.
+type Asset struct {
+	Id      string
+	Size    int64
+	ModTime time.Time
+}
+
+func (a Asset) Path() string {
+	return "/assets/" + a.Id
+}
+
+// AssetIdFromUrl returns the id of the asset rawUrl points to, when
+// it is the url of an asset uploaded to this service: a url under
+// publicBaseUrl, or a relative one. The same path on another host is
+// a file of that host.
+func AssetIdFromUrl(rawUrl, publicBaseUrl string) (string, bool) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", false
+	}
+
+	path := u.Path
+	if u.Scheme != "" || u.Host != "" {
+		base, err := url.Parse(publicBaseUrl)
+		if publicBaseUrl == "" || err != nil ||
+			!strings.EqualFold(u.Scheme, base.Scheme) || !strings.EqualFold(u.Host, base.Host) {
+			return "", false
+		}
+
+		var ok bool
+		if path, ok = strings.CutPrefix(u.Path, strings.TrimSuffix(base.Path, "/")); !ok {
+			return "", false
+		}
+	}
+
+	match := assetPathPattern.FindStringSubmatch(path)
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetIdFromUrl(t *testing.T) {
+	id := strings.Repeat("a", 64)
+
+	tests := []struct {
+		name          string
+		url           string
+		publicBaseUrl string
+		wantOk        bool
+	}{
+		{name: "public base url", url: "https://content.com/assets/" + id, publicBaseUrl: "https://content.com", wantOk: true},
+		{name: "public base url with a path", url: "https://content.com/media/assets/" + id, publicBaseUrl: "https://content.com/media/", wantOk: true},
+		{name: "host in another case", url: "https://Content.com/assets/" + id, publicBaseUrl: "https://content.com", wantOk: true},
+		{name: "relative url", url: "/assets/" + id, publicBaseUrl: "https://content.com", wantOk: true},
+		{name: "relative url without a public base url", url: "/assets/" + id, wantOk: true},
+		{name: "foreign host", url: "https://evil.com/assets/" + id, publicBaseUrl: "https://content.com"},
+		{name: "foreign scheme", url: "http://content.com/assets/" + id, publicBaseUrl: "https://content.com"},
+		{name: "scheme relative url on a foreign host", url: "//evil.com/assets/" + id, publicBaseUrl: "https://content.com"},
+		{name: "absolute url without a public base url", url: "https://content.com/assets/" + id},
+		{name: "outside the path of the public base url", url: "https://content.com/assets/" + id, publicBaseUrl: "https://content.com/media"},
+		{name: "not an asset", url: "https://content.com/videos/" + id, publicBaseUrl: "https://content.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := AssetIdFromUrl(tt.url, tt.publicBaseUrl)
+
+			assert.Equal(t, tt.wantOk, ok)
+			if tt.wantOk {
+				assert.Equal(t, id, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// CaptionTrack is an inline WebVTT track stored and served by this service.
+// Its Id is derived from the body, so uploading the same track twice is a no-op.
+type CaptionTrack struct {
+	Id   string
+	Body string
+}
+
+func NewCaptionTrack(body string) CaptionTrack {
+	sum := sha256.Sum256([]byte(body))
+	return CaptionTrack{
+		Id:   hex.EncodeToString(sum[:16]),
+		Body: body,
+	}
+}
+
+// Path is where the track is served by this service.
+func (t CaptionTrack) Path() string {
+	return "/captions/" + t.Id + ".vtt"
+}
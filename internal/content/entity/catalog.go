@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"time"
+)
+
+var catalogIdPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// CatalogVideo is a video registered once and referenced by id from many
+// rules, so changing it changes the content of all of them.
+type CatalogVideo struct {
+	Id        string
+	Video     Video
+	UpdatedAt time.Time
+}
+
+func (c CatalogVideo) Path() string {
+	return "/videos/" + c.Id
+}
+
+// CatalogCacheTag is the tag of the cached responses that join the catalog
+// video with the id, invalidated when the video changes.
+func CatalogCacheTag(id string) string {
+	return "video/" + id
+}
+
+// NewCatalogId returns a random id for a catalog video.
+func NewCatalogId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// IsValidCatalogId reports whether id can identify a catalog video:
+// lowercase letters, digits, "-" and "_", up to 64 characters.
+func IsValidCatalogId(id string) bool {
+	return catalogIdPattern.MatchString(id)
+}
@@ -8,11 +8,16 @@ import (
 type Video struct {
 	VideoUrl    string
 	TambnailUrl string
+	Metadata    *VideoMetadata    `json:",omitempty"`
+	Renditions  []Rendition       `json:",omitempty"`
+	Placeholder *ImagePlaceholder `json:",omitempty"`
+	// CatalogId references a CatalogVideo. Stored rules keep only the id,
+	// the other fields are filled from the catalog when the rule is read.
+	CatalogId string `json:",omitempty"`
 }
 
 func (v Video) IsEmpty() bool {
-	var empty Video
-	return empty == v
+	return v.VideoUrl == "" && v.TambnailUrl == "" && v.Metadata == nil && len(v.Renditions) == 0 && v.CatalogId == ""
 }
 
 type Enterprise struct {
@@ -21,6 +26,120 @@ type Enterprise struct {
 	Paths  []string
 	Path   string
 	Video  Video
+
+	Experiment *Experiment          `json:",omitempty"`
+	Regions    map[RegionCode]Video `json:",omitempty"`
+	Playlist   []Video              `json:",omitempty"`
+	// Fallback replaces the videos whose video or thumbnail url the
+	// dead link checker found broken.
+	Fallback *Video `json:",omitempty"`
+}
+
+// Items returns the ordered videos of the rule. Rules registered
+// with a single video are read as a one-item playlist.
+func (e Enterprise) Items() []Video {
+	if len(e.Playlist) > 0 {
+		return e.Playlist
+	}
+
+	return []Video{e.Video}
+}
+
+// Varies reports whether the content of the rule depends on who is asking,
+// whatever the video picked for a given request: a visitor from another
+// region, or in another variant, gets another video.
+func (e Enterprise) Varies() bool {
+	return len(e.Regions) > 0 || e.Experiment != nil
+}
+
+// Videos returns every video of the rule: the single video, the playlist,
+// the regional videos, the experiment variants and the fallback.
+func (e Enterprise) Videos() []Video {
+	videos := []Video{e.Video}
+	videos = append(videos, e.Playlist...)
+	for _, video := range e.Regions {
+		videos = append(videos, video)
+	}
+
+	if e.Experiment != nil {
+		for _, variant := range e.Experiment.Variants {
+			videos = append(videos, variant.Video)
+		}
+	}
+
+	if e.Fallback != nil {
+		videos = append(videos, *e.Fallback)
+	}
+
+	return videos
+}
+
+// EachVideo calls fn with every video of the rule, which fn may change.
+// Rules shared with other goroutines must be cloned first.
+func (e *Enterprise) EachVideo(fn func(video *Video)) {
+	fn(&e.Video)
+
+	for i := range e.Playlist {
+		fn(&e.Playlist[i])
+	}
+
+	for code, video := range e.Regions {
+		fn(&video)
+		e.Regions[code] = video
+	}
+
+	if e.Experiment != nil {
+		for i := range e.Experiment.Variants {
+			fn(&e.Experiment.Variants[i].Video)
+		}
+	}
+
+	if e.Fallback != nil {
+		fn(e.Fallback)
+	}
+}
+
+// Clone returns a copy of the rule that shares no slice or map of
+// videos with e, so its videos can be changed with EachVideo.
+func (e Enterprise) Clone() Enterprise {
+	if e.Playlist != nil {
+		e.Playlist = append([]Video(nil), e.Playlist...)
+	}
+
+	if e.Regions != nil {
+		regions := make(map[RegionCode]Video, len(e.Regions))
+		for code, video := range e.Regions {
+			regions[code] = video
+		}
+		e.Regions = regions
+	}
+
+	if e.Experiment != nil {
+		experiment := *e.Experiment
+		experiment.Variants = append([]Variant(nil), experiment.Variants...)
+		e.Experiment = &experiment
+	}
+
+	if e.Fallback != nil {
+		fallback := *e.Fallback
+		e.Fallback = &fallback
+	}
+
+	return e
+}
+
+// CatalogIds returns the ids of the catalog videos the rule references, without repetition.
+func (e Enterprise) CatalogIds() []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, video := range e.Videos() {
+		if video.CatalogId != "" && !seen[video.CatalogId] {
+			seen[video.CatalogId] = true
+			ids = append(ids, video.CatalogId)
+		}
+	}
+
+	return ids
 }
 
 type EnterpriseKey string
@@ -39,6 +158,12 @@ func NewPathKey(u *url.URL) PathKey {
 	return PathKey(strings.ReplaceAll(u.Path, "//", "/"))
 }
 
+// RuleCacheTag is the tag of the cached responses of the rule with the url,
+// invalidated when the rule serves another video.
+func RuleCacheTag(u *url.URL) string {
+	return "rule/" + u.Host + (&url.URL{Path: string(NewPathKey(u))}).EscapedPath()
+}
+
 func (pk PathKey) ToListPaths() (output []string) {
 	r := strings.Split(string(pk), "/")
 	for i := range r {
@@ -154,3 +154,40 @@ func TestEnterpriseStructure(t *testing.T) {
 		}
 	})
 }
+
+func TestEnterprise_EachVideoOnClone(t *testing.T) {
+	enterprise := Enterprise{
+		Video:    Video{CatalogId: "intro"},
+		Playlist: []Video{{CatalogId: "intro"}, {VideoUrl: "https://example.com/b.mp4"}},
+		Regions:  map[RegionCode]Video{"BR": {CatalogId: "outro"}},
+		Experiment: &Experiment{Id: "exp", Variants: []Variant{
+			{Id: "a", Weight: 1, Video: Video{CatalogId: "intro"}},
+		}},
+		Fallback: &Video{CatalogId: "outro"},
+	}
+
+	if got, want := enterprise.CatalogIds(), []string{"intro", "outro"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Enterprise.CatalogIds() = %v, want %v", got, want)
+	}
+
+	clone := enterprise.Clone()
+	visited := 0
+	clone.EachVideo(func(video *Video) {
+		visited++
+		video.VideoUrl = "https://example.com/" + video.CatalogId + ".mp4"
+	})
+
+	if visited != 6 {
+		t.Errorf("EachVideo visited %d videos, want 6", visited)
+	}
+
+	if clone.Regions["BR"].VideoUrl != "https://example.com/outro.mp4" || clone.Fallback.VideoUrl != "https://example.com/outro.mp4" {
+		t.Errorf("EachVideo did not change the clone: %+v", clone)
+	}
+
+	for _, video := range enterprise.Videos() {
+		if video.CatalogId != "" && video.VideoUrl != "" {
+			t.Errorf("changing the clone changed the original video %+v", video)
+		}
+	}
+}
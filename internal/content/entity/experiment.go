@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+type Variant struct {
+	Id     string
+	Weight int
+	Video  Video
+}
+
+type Experiment struct {
+	Id       string
+	Variants []Variant
+}
+
+// Assign picks the variant of the experiment for the given visitor.
+// It uses weighted rendezvous hashing, so the same visitor always lands on the
+// same variant and changing the variant list only moves the visitors that
+// belonged to the removed (or re-weighted) variants.
+func (e Experiment) Assign(visitorId string) (Variant, bool) {
+	var (
+		selected  Variant
+		bestScore = math.Inf(-1)
+		found     bool
+	)
+
+	for _, v := range e.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+
+		score := float64(v.Weight) / -math.Log(e.hashToUnit(v.Id, visitorId))
+		if score > bestScore {
+			bestScore = score
+			selected = v
+			found = true
+		}
+	}
+
+	return selected, found
+}
+
+// hashToUnit maps experiment, variant and visitor to a number in the open interval (0, 1).
+func (e Experiment) hashToUnit(variantId, visitorId string) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(e.Id))
+	h.Write([]byte{0})
+	h.Write([]byte(variantId))
+	h.Write([]byte{0})
+	h.Write([]byte(visitorId))
+
+	// fnv alone mixes short keys poorly, so finish with the splitmix64 finalizer
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+
+	return (float64(x>>11) + 0.5) / (1 << 53)
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperiment_Assign(t *testing.T) {
+	experiment := Experiment{
+		Id: "hero-video",
+		Variants: []Variant{
+			{Id: "control", Weight: 50, Video: Video{VideoUrl: "https://cdn.com/a.mp4"}},
+			{Id: "challenger", Weight: 50, Video: Video{VideoUrl: "https://cdn.com/b.mp4"}},
+		},
+	}
+
+	t.Run("sticky assignment", func(t *testing.T) {
+		for i := 0; i < 100; i++ {
+			visitorId := fmt.Sprintf("visitor-%d", i)
+			first, found := experiment.Assign(visitorId)
+			assert.True(t, found)
+
+			second, _ := experiment.Assign(visitorId)
+			assert.Equal(t, first.Id, second.Id)
+		}
+	})
+
+	t.Run("respects weights", func(t *testing.T) {
+		weighted := Experiment{
+			Id: "weighted",
+			Variants: []Variant{
+				{Id: "a", Weight: 80},
+				{Id: "b", Weight: 20},
+			},
+		}
+
+		const total = 10000
+		counts := map[string]int{}
+		for i := 0; i < total; i++ {
+			v, _ := weighted.Assign(fmt.Sprintf("visitor-%d", i))
+			counts[v.Id]++
+		}
+
+		assert.InDelta(t, 0.8, float64(counts["a"])/total, 0.03)
+		assert.InDelta(t, 0.2, float64(counts["b"])/total, 0.03)
+	})
+
+	t.Run("adding a variant only moves visitors to the new variant", func(t *testing.T) {
+		extended := experiment
+		extended.Variants = append(append([]Variant{}, experiment.Variants...), Variant{Id: "third", Weight: 50})
+
+		for i := 0; i < 1000; i++ {
+			visitorId := fmt.Sprintf("visitor-%d", i)
+			before, _ := experiment.Assign(visitorId)
+			after, _ := extended.Assign(visitorId)
+
+			if after.Id != before.Id {
+				assert.Equal(t, "third", after.Id)
+			}
+		}
+	})
+
+	t.Run("no eligible variant", func(t *testing.T) {
+		_, found := Experiment{Id: "empty", Variants: []Variant{{Id: "a", Weight: 0}}}.Assign("visitor")
+		assert.False(t, found)
+	})
+}
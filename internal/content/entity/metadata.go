@@ -0,0 +1,155 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/IsaacDSC/search_content/pkg/webvtt"
+	"net/url"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 5000
+	maxPosterAltLength   = 300
+	maxAttributes        = 50
+	maxAttributeLength   = 500
+)
+
+var (
+	aspectRatioPattern = regexp.MustCompile(`^[1-9][0-9]*:[1-9][0-9]*$`)
+	languagePattern    = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+)
+
+// VideoMetadata describes a video for the frontend player.
+// Every field is optional; Attributes holds any extra key/value
+// the frontend needs without changing the model.
+type VideoMetadata struct {
+	Title           string            `json:",omitempty"`
+	Description     string            `json:",omitempty"`
+	DurationSeconds float64           `json:",omitempty"`
+	AspectRatio     string            `json:",omitempty"` // e.g. "16:9"
+	PosterAlt       string            `json:",omitempty"` // accessible text of the thumbnail
+	UploadDate      *time.Time        `json:",omitempty"`
+	Captions        []Caption         `json:",omitempty"`
+	Preview         *PreviewTrack     `json:",omitempty"`
+	Attributes      map[string]string `json:",omitempty"`
+}
+
+type CaptionKind string
+
+const (
+	CaptionKindSubtitles    CaptionKind = "subtitles"
+	CaptionKindCaptions     CaptionKind = "captions"
+	CaptionKindDescriptions CaptionKind = "descriptions"
+	CaptionKindChapters     CaptionKind = "chapters"
+	CaptionKindMetadata     CaptionKind = "metadata"
+)
+
+func (ck CaptionKind) IsValid() bool {
+	switch ck {
+	case CaptionKindSubtitles, CaptionKindCaptions, CaptionKindDescriptions, CaptionKindChapters, CaptionKindMetadata:
+		return true
+	}
+
+	return false
+}
+
+// Caption is a text track of the video, as in the HTML <track> element.
+// A caption either points to an external Url or carries an inline WebVTT
+// Body, which is stored as a CaptionTrack and then referenced by TrackId.
+type Caption struct {
+	Language string      // BCP 47 tag, e.g. "pt-BR"
+	Kind     CaptionKind // defaults to subtitles
+	Label    string      `json:",omitempty"`
+	Url      string
+	TrackId  string `json:",omitempty"`
+	Body     string `json:"-"`
+}
+
+func (c Caption) IsInline() bool {
+	return c.Body != ""
+}
+
+func (c Caption) Validate() error {
+	if !languagePattern.MatchString(c.Language) {
+		return fmt.Errorf("invalid caption language %q", c.Language)
+	}
+
+	if !c.Kind.IsValid() {
+		return fmt.Errorf("invalid caption kind %q", c.Kind)
+	}
+
+	if c.IsInline() {
+		if err := webvtt.Validate([]byte(c.Body)); err != nil {
+			return fmt.Errorf("invalid caption body: %w", err)
+		}
+		return nil
+	}
+
+	if c.Url == "" {
+		return errors.New("caption url is empty")
+	}
+
+	if _, err := url.Parse(c.Url); err != nil {
+		return errors.New("invalid caption url")
+	}
+
+	return nil
+}
+
+func (m VideoMetadata) Validate() error {
+	if utf8.RuneCountInString(m.Title) > maxTitleLength {
+		return fmt.Errorf("title is longer than %d characters", maxTitleLength)
+	}
+
+	if utf8.RuneCountInString(m.Description) > maxDescriptionLength {
+		return fmt.Errorf("description is longer than %d characters", maxDescriptionLength)
+	}
+
+	if utf8.RuneCountInString(m.PosterAlt) > maxPosterAltLength {
+		return fmt.Errorf("poster alt is longer than %d characters", maxPosterAltLength)
+	}
+
+	if m.DurationSeconds < 0 {
+		return errors.New("duration must not be negative")
+	}
+
+	if m.AspectRatio != "" && !aspectRatioPattern.MatchString(m.AspectRatio) {
+		return fmt.Errorf("invalid aspect ratio %q", m.AspectRatio)
+	}
+
+	if m.UploadDate != nil && m.UploadDate.After(time.Now()) {
+		return errors.New("upload date is in the future")
+	}
+
+	for _, c := range m.Captions {
+		if err := c.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if m.Preview != nil {
+		if err := m.Preview.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if len(m.Attributes) > maxAttributes {
+		return fmt.Errorf("more than %d attributes", maxAttributes)
+	}
+
+	for k, v := range m.Attributes {
+		if k == "" {
+			return errors.New("attribute name is empty")
+		}
+
+		if utf8.RuneCountInString(v) > maxAttributeLength {
+			return fmt.Errorf("attribute %q is longer than %d characters", k, maxAttributeLength)
+		}
+	}
+
+	return nil
+}
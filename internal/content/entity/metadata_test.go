@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVideoMetadata_Validate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		metadata    VideoMetadata
+		errContains string
+	}{
+		{
+			name: "valid metadata",
+			metadata: VideoMetadata{
+				Title:           "Summer collection",
+				DurationSeconds: 30,
+				AspectRatio:     "16:9",
+				UploadDate:      &past,
+				Captions:        []Caption{{Language: "pt-BR", Kind: CaptionKindCaptions, Url: "https://cdn.com/pt.vtt"}},
+				Attributes:      map[string]string{"campaign": "summer"},
+			},
+		},
+		{name: "empty metadata", metadata: VideoMetadata{}},
+		{name: "title too long", metadata: VideoMetadata{Title: strings.Repeat("a", 201)}, errContains: "title"},
+		{name: "negative duration", metadata: VideoMetadata{DurationSeconds: -1}, errContains: "duration"},
+		{name: "invalid aspect ratio", metadata: VideoMetadata{AspectRatio: "wide"}, errContains: "aspect ratio"},
+		{name: "zero aspect ratio", metadata: VideoMetadata{AspectRatio: "0:9"}, errContains: "aspect ratio"},
+		{name: "upload date in the future", metadata: VideoMetadata{UploadDate: &future}, errContains: "future"},
+		{name: "pending preview", metadata: VideoMetadata{Preview: &PreviewTrack{Frames: []string{"https://cdn.com/1.jpg"}, IntervalSeconds: 2}}},
+		{name: "generated preview", metadata: VideoMetadata{Preview: &PreviewTrack{Url: "https://content.com/assets/abc"}}},
+		{name: "preview without frames", metadata: VideoMetadata{Preview: &PreviewTrack{}}, errContains: "no frames"},
+		{
+			name:        "preview without interval",
+			metadata:    VideoMetadata{Preview: &PreviewTrack{Frames: []string{"https://cdn.com/1.jpg"}}},
+			errContains: "interval",
+		},
+		{
+			name:        "invalid caption language",
+			metadata:    VideoMetadata{Captions: []Caption{{Language: "portuguese!", Kind: CaptionKindSubtitles, Url: "https://cdn.com/pt.vtt"}}},
+			errContains: "language",
+		},
+		{
+			name:        "invalid caption kind",
+			metadata:    VideoMetadata{Captions: []Caption{{Language: "pt", Kind: "karaoke", Url: "https://cdn.com/pt.vtt"}}},
+			errContains: "kind",
+		},
+		{
+			name:        "empty attribute name",
+			metadata:    VideoMetadata{Attributes: map[string]string{"": "value"}},
+			errContains: "attribute",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.metadata.Validate()
+
+			if tt.errContains == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorContains(t, err, tt.errContains)
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package entity
+
+// ImagePlaceholder is painted by the frontend while the thumbnail loads.
+// It is computed by the service from the thumbnail, never sent by clients.
+type ImagePlaceholder struct {
+	BlurHash      string
+	DominantColor string // CSS "#rrggbb"
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+const maxPreviewFrames = 1000
+
+// PreviewTrack is shown by the player while seeking: a WebVTT track whose
+// cues point to regions of a sprite sheet ("sprite.jpg#xywh=0,0,160,90").
+// A track is registered with the Frames to compose, one every IntervalSeconds,
+// which are replaced by the Url of the generated track and of its sprite.
+type PreviewTrack struct {
+	Url             string
+	SpriteUrl       string   `json:",omitempty"`
+	IntervalSeconds float64  `json:",omitempty"`
+	TileWidth       int      `json:",omitempty"`
+	Frames          []string `json:"-"`
+}
+
+func (p PreviewTrack) IsPending() bool {
+	return len(p.Frames) > 0
+}
+
+func (p PreviewTrack) Validate() error {
+	if !p.IsPending() {
+		if p.Url == "" {
+			return errors.New("preview has no frames")
+		}
+
+		if _, err := url.Parse(p.Url); err != nil {
+			return errors.New("invalid preview url")
+		}
+
+		return nil
+	}
+
+	if len(p.Frames) > maxPreviewFrames {
+		return fmt.Errorf("preview has more than %d frames", maxPreviewFrames)
+	}
+
+	if p.IntervalSeconds <= 0 {
+		return errors.New("preview interval must be positive")
+	}
+
+	for i, frame := range p.Frames {
+		if frame == "" {
+			return fmt.Errorf("preview frame %d is empty", i)
+		}
+
+		if _, err := url.Parse(frame); err != nil {
+			return fmt.Errorf("invalid preview frame %d", i)
+		}
+	}
+
+	return nil
+}
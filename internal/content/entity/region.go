@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RegionCode is an ISO 3166 country ("BR") or country-subdivision ("BR-SP") code.
+type RegionCode string
+
+var regionCodePattern = regexp.MustCompile(`^[A-Z]{2}(-[A-Z0-9]{1,3})?$`)
+
+func NewRegionCode(code string) RegionCode {
+	return RegionCode(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func (rc RegionCode) IsValid() bool {
+	return regionCodePattern.MatchString(string(rc))
+}
+
+// Country returns the country part of the code, e.g. "BR" for "BR-SP".
+func (rc RegionCode) Country() RegionCode {
+	country, _, _ := strings.Cut(string(rc), "-")
+	return RegionCode(country)
+}
+
+// RegionVideo returns the video registered for the region, falling back from
+// the subdivision to its country. The returned code is the one that matched.
+func (e Enterprise) RegionVideo(region RegionCode) (Video, RegionCode, bool) {
+	if region == "" || len(e.Regions) == 0 {
+		return Video{}, "", false
+	}
+
+	if video, ok := e.Regions[region]; ok {
+		return video, region, true
+	}
+
+	country := region.Country()
+	if video, ok := e.Regions[country]; ok {
+		return video, country, true
+	}
+
+	return Video{}, "", false
+}
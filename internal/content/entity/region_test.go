@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionCode(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantCode    RegionCode
+		wantValid   bool
+		wantCountry RegionCode
+	}{
+		{input: "BR", wantCode: "BR", wantValid: true, wantCountry: "BR"},
+		{input: " br-sp ", wantCode: "BR-SP", wantValid: true, wantCountry: "BR"},
+		{input: "GB-ENG", wantCode: "GB-ENG", wantValid: true, wantCountry: "GB"},
+		{input: "Brazil", wantCode: "BRAZIL", wantValid: false, wantCountry: "BRAZIL"},
+		{input: "", wantCode: "", wantValid: false, wantCountry: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			code := NewRegionCode(tt.input)
+
+			assert.Equal(t, tt.wantCode, code)
+			assert.Equal(t, tt.wantValid, code.IsValid())
+			assert.Equal(t, tt.wantCountry, code.Country())
+		})
+	}
+}
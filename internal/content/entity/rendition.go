@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/IsaacDSC/search_content/pkg/manifest"
+)
+
+// Rendition is one encoding of the video, listed in the adaptive streaming
+// manifests. PlaylistUrl is the HLS media playlist and Url the media file
+// used by DASH; a rendition is only listed in the formats it has a url for.
+type Rendition struct {
+	Url         string  `json:",omitempty"`
+	PlaylistUrl string  `json:",omitempty"`
+	Bandwidth   int     // peak bits per second
+	Width       int     `json:",omitempty"`
+	Height      int     `json:",omitempty"`
+	Codecs      string  `json:",omitempty"` // RFC 6381, e.g. "avc1.64001f,mp4a.40.2"
+	FrameRate   float64 `json:",omitempty"`
+	MimeType    string  `json:",omitempty"` // defaults to video/mp4
+}
+
+func (r Rendition) Validate() error {
+	if r.Url == "" && r.PlaylistUrl == "" {
+		return errors.New("rendition url and playlist url are empty")
+	}
+
+	for _, u := range []string{r.Url, r.PlaylistUrl} {
+		if u == "" {
+			continue
+		}
+		if _, err := url.Parse(u); err != nil {
+			return errors.New("invalid rendition url")
+		}
+	}
+
+	if r.Bandwidth <= 0 {
+		return errors.New("rendition bandwidth must be positive")
+	}
+
+	if r.Width < 0 || r.Height < 0 || (r.Width == 0) != (r.Height == 0) {
+		return errors.New("rendition width and height must be set together")
+	}
+
+	if r.Codecs != "" && !manifest.ValidCodecs(r.Codecs) {
+		return errors.New("rendition codecs must be RFC 6381 codecs separated by commas")
+	}
+
+	if r.FrameRate < 0 {
+		return errors.New("rendition frame rate must not be negative")
+	}
+
+	return nil
+}
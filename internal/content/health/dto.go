@@ -0,0 +1,60 @@
+package health
+
+import (
+	"github.com/IsaacDSC/search_content/pkg/linkcheck"
+	"time"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusOk       = "ok"
+	StatusDegraded = "degraded"
+)
+
+// ReportOutputDto is the result of the last dead link check.
+type ReportOutputDto struct {
+	Status      string          `json:"status"`
+	CheckedAt   *time.Time      `json:"checked_at,omitempty"`
+	UrlsChecked int             `json:"urls_checked"`
+	BrokenRules []BrokenRuleDto `json:"broken_rules"`
+	// SkippedUrls were not requested, their address isn't public (e.g. a
+	// private CDN), so they are neither broken nor replaced by a fallback.
+	SkippedUrls []string `json:"skipped_urls,omitempty"`
+}
+
+// BrokenRuleDto is a rule with at least one broken url.
+type BrokenRuleDto struct {
+	Enterprise string `json:"enterprise"`
+	Path       string `json:"path"`
+	// HasFallback means the reader serves the backup video of the rule in place of the broken ones.
+	HasFallback bool      `json:"has_fallback"`
+	Links       []LinkDto `json:"links"`
+}
+
+type LinkDto struct {
+	Url         string    `json:"url"`
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+func NewLinkDto(result linkcheck.Result) LinkDto {
+	link := LinkDto{
+		Url:         result.Url,
+		StatusCode:  result.StatusCode,
+		ContentType: result.ContentType,
+		CheckedAt:   result.CheckedAt,
+	}
+
+	if result.Size > 0 {
+		link.Size = result.Size
+	}
+
+	if result.Err != nil {
+		link.Error = result.Err.Error()
+	}
+
+	return link
+}
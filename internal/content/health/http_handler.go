@@ -0,0 +1,52 @@
+package health
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+)
+
+type Handler interface {
+	GetContentHealth(w http.ResponseWriter, r *http.Request) error
+}
+
+type HttpHandler struct {
+	service Service
+	token   string
+}
+
+var _ Handler = (*HttpHandler)(nil)
+
+// NewHandler creates the handler of the report, which requires the token as
+// a bearer token: it tells the status of every url checked. The report is
+// not served when token is empty.
+func NewHandler(service Service, token string) *HttpHandler {
+	return &HttpHandler{service: service, token: token}
+}
+
+// GetContentHealth returns the rules with broken links found by the last check.
+func (h HttpHandler) GetContentHealth(w http.ResponseWriter, r *http.Request) error {
+	// the report changes with every check, it must never come from a cache
+	w.Header().Set("Cache-Control", "no-store")
+
+	if h.token == "" {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="health"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	return json.NewEncoder(w).Encode(h.service.Report())
+}
+
+func (h HttpHandler) authorized(r *http.Request) bool {
+	expected := "Bearer " + h.token
+	return subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) == 1
+}
@@ -0,0 +1,75 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staticService struct {
+	Service
+	report ReportOutputDto
+}
+
+func (s staticService) Report() ReportOutputDto { return s.report }
+
+func TestHttpHandler_GetContentHealth(t *testing.T) {
+	report := ReportOutputDto{
+		Status:      StatusDegraded,
+		UrlsChecked: 2,
+		BrokenRules: []BrokenRuleDto{{
+			Enterprise: "shop.com",
+			Path:       "/sale",
+			Links:      []LinkDto{{Url: "https://cdn.com/a.mp4", StatusCode: http.StatusNotFound}},
+		}},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/health/content", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+
+	w := httptest.NewRecorder()
+	err := NewHandler(staticService{report: report}, "secret").GetContentHealth(w, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+	var got ReportOutputDto
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, report, got)
+}
+
+func TestHttpHandler_GetContentHealthUnauthorized(t *testing.T) {
+	service := staticService{report: ReportOutputDto{Status: StatusOk}}
+
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantCode      int
+	}{
+		{name: "no token configured", token: "", authorization: "Bearer ", wantCode: http.StatusNotFound},
+		{name: "missing token", token: "secret", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", token: "secret", authorization: "Bearer guess", wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/health/content", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			err := NewHandler(service, tt.token).GetContentHealth(w, req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+			assert.NotContains(t, w.Body.String(), StatusOk)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package health
+
+import (
+	"context"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/linkcheck"
+)
+
+type Repository interface {
+	ListEnterprises(ctx context.Context) ([]entity.EnterpriseKey, error)
+	Get(ctx context.Context, enterpriseKey entity.EnterpriseKey) (reader.EnterpriseData, error)
+}
+
+// CatalogRepository is the catalog of the videos that rules reference by id.
+type CatalogRepository interface {
+	GetVideo(ctx context.Context, id string) (entity.CatalogVideo, error)
+}
+
+// ContentCache holds the rendered content, tagged with its rule
+// (entity.RuleCacheTag).
+type ContentCache interface {
+	Invalidate(tags ...string) error
+}
+
+// LinkChecker requests the urls and returns their results in the same order.
+type LinkChecker interface {
+	Check(ctx context.Context, urls []string) []linkcheck.Result
+}
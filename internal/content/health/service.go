@@ -0,0 +1,295 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/linkcheck"
+)
+
+type Service interface {
+	// CheckAll checks the video and thumbnail urls of every rule.
+	CheckAll(ctx context.Context) error
+	Report() ReportOutputDto
+	IsBroken(rawUrl string) bool
+}
+
+// Monitor keeps the result of the last dead link check in memory.
+type Monitor struct {
+	repository Repository
+	catalog    CatalogRepository
+	checker    LinkChecker
+	cache      ContentCache
+
+	running sync.Mutex // a single check at a time
+
+	mu      sync.RWMutex
+	report  ReportOutputDto
+	results map[string]linkcheck.Result
+}
+
+var _ Service = (*Monitor)(nil)
+
+// NewMonitor creates the monitor. cache may be nil when the content isn't
+// cached, otherwise the content of the rules that fall back is invalidated
+// after each check.
+func NewMonitor(repository Repository, catalog CatalogRepository, checker LinkChecker, cache ContentCache) *Monitor {
+	return &Monitor{
+		repository: repository,
+		catalog:    catalog,
+		checker:    checker,
+		cache:      cache,
+		report:     ReportOutputDto{Status: StatusPending, BrokenRules: []BrokenRuleDto{}},
+	}
+}
+
+// Start runs a check now and then every interval, in the background.
+// Stopping cancels the check in progress.
+func (m *Monitor) Start(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := m.CheckAll(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("failed to check content links: %v", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cancel
+}
+
+// rule is a rule with the urls to check.
+type rule struct {
+	enterprise  string
+	path        string
+	cacheTag    string
+	hasFallback bool
+	urls        []string
+}
+
+func (m *Monitor) CheckAll(ctx context.Context) error {
+	m.running.Lock()
+	defer m.running.Unlock()
+
+	keys, err := m.repository.ListEnterprises(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list enterprises: %w", err)
+	}
+
+	var (
+		rules   []rule
+		urls    []string
+		seen    = map[string]bool{}
+		catalog = &cachedCatalog{catalog: m.catalog, videos: map[string]entity.CatalogVideo{}}
+	)
+	for _, key := range keys {
+		data, err := m.repository.Get(ctx, key)
+		if err != nil {
+			// a single unreadable file must not stop the check of the others
+			log.Printf("failed to read enterprise %s: %v", key, err)
+			continue
+		}
+
+		for path, enterprise := range data {
+			if enterprise, err = reader.JoinCatalog(ctx, catalog, enterprise); err != nil {
+				log.Printf("failed to read the videos of %s%s: %v", key, path, err)
+				continue
+			}
+
+			r := rule{
+				enterprise:  key.String(),
+				path:        string(path),
+				cacheTag:    entity.RuleCacheTag(enterprise.Url),
+				hasFallback: enterprise.Fallback != nil,
+			}
+			for _, u := range ruleUrls(enterprise) {
+				r.urls = append(r.urls, u)
+				if !seen[u] {
+					seen[u] = true
+					urls = append(urls, u)
+				}
+			}
+			rules = append(rules, r)
+		}
+	}
+
+	checked := m.checker.Check(ctx, urls)
+	if err := ctx.Err(); err != nil {
+		// a partial check would report the unchecked urls as broken
+		return err
+	}
+
+	results := make(map[string]linkcheck.Result, len(checked))
+	for _, result := range checked {
+		results[result.Url] = result
+	}
+
+	report := newReport(rules, results)
+
+	m.mu.Lock()
+	m.report = report
+	m.results = results
+	m.mu.Unlock()
+
+	m.invalidateFallbacks(rules, results)
+
+	return nil
+}
+
+// invalidateFallbacks drops the cached content of the rules with a broken
+// link and a fallback, cached before the link broke: it now falls back,
+// and is not cached while it does.
+func (m *Monitor) invalidateFallbacks(rules []rule, results map[string]linkcheck.Result) {
+	if m.cache == nil {
+		return
+	}
+
+	var tags []string
+	for _, r := range rules {
+		if r.hasFallback && slices.ContainsFunc(r.urls, func(u string) bool { return results[u].Broken() }) {
+			tags = append(tags, r.cacheTag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return
+	}
+
+	if err := m.cache.Invalidate(tags...); err != nil {
+		log.Printf("failed to invalidate the content of the rules falling back: %v", err)
+	}
+}
+
+func newReport(rules []rule, results map[string]linkcheck.Result) ReportOutputDto {
+	now := time.Now()
+	report := ReportOutputDto{
+		Status:      StatusOk,
+		CheckedAt:   &now,
+		BrokenRules: []BrokenRuleDto{},
+	}
+
+	for u, result := range results {
+		if result.Skipped() {
+			report.SkippedUrls = append(report.SkippedUrls, u)
+		}
+	}
+	sort.Strings(report.SkippedUrls)
+	report.UrlsChecked = len(results) - len(report.SkippedUrls)
+
+	for _, r := range rules {
+		var links []LinkDto
+		for _, u := range r.urls {
+			if result := results[u]; result.Broken() {
+				links = append(links, NewLinkDto(result))
+			}
+		}
+
+		if len(links) > 0 {
+			report.BrokenRules = append(report.BrokenRules, BrokenRuleDto{
+				Enterprise:  r.enterprise,
+				Path:        r.path,
+				HasFallback: r.hasFallback,
+				Links:       links,
+			})
+		}
+	}
+
+	if len(report.BrokenRules) > 0 {
+		report.Status = StatusDegraded
+	}
+
+	sort.Slice(report.BrokenRules, func(i, j int) bool {
+		a, b := report.BrokenRules[i], report.BrokenRules[j]
+		if a.Enterprise != b.Enterprise {
+			return a.Enterprise < b.Enterprise
+		}
+		return a.Path < b.Path
+	})
+
+	return report
+}
+
+// ruleUrls returns the http urls of every video of the rule, without repetition.
+func ruleUrls(enterprise entity.Enterprise) []string {
+	var urls []string
+	seen := map[string]bool{}
+	add := func(rawUrl string) {
+		if seen[rawUrl] || !isHttp(rawUrl) {
+			return
+		}
+		seen[rawUrl] = true
+		urls = append(urls, rawUrl)
+	}
+
+	for _, video := range enterprise.Videos() {
+		add(video.VideoUrl)
+		add(video.TambnailUrl)
+		for _, rendition := range video.Renditions {
+			add(rendition.Url)
+			add(rendition.PlaylistUrl)
+		}
+	}
+
+	return urls
+}
+
+// cachedCatalog reads each catalog video once per check, they are
+// usually referenced by many rules.
+type cachedCatalog struct {
+	catalog CatalogRepository
+	videos  map[string]entity.CatalogVideo
+}
+
+func (c *cachedCatalog) GetVideo(ctx context.Context, id string) (entity.CatalogVideo, error) {
+	if video, ok := c.videos[id]; ok {
+		return video, nil
+	}
+
+	video, err := c.catalog.GetVideo(ctx, id)
+	if err != nil {
+		return entity.CatalogVideo{}, err
+	}
+
+	c.videos[id] = video
+	return video, nil
+}
+
+func isHttp(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (m *Monitor) Report() ReportOutputDto {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.report
+}
+
+// IsBroken reports whether the url was broken in the last check.
+// Urls that were not checked yet are not broken.
+func (m *Monitor) IsBroken(rawUrl string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result, ok := m.results[rawUrl]
+	return ok && result.Broken()
+}
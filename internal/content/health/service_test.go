@@ -0,0 +1,194 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/builder"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/linkcheck"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newCdn(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok.mp4", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Length", "2048")
+	})
+	mux.HandleFunc("/ok.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestMonitor_CheckAll(t *testing.T) {
+	cdn := newCdn(t)
+
+	healthyUrl, _ := url.Parse("https://shop.com/home")
+	brokenUrl, _ := url.Parse("https://shop.com/sale")
+	otherUrl, _ := url.Parse("https://other.com/home")
+
+	healthy := entity.Video{VideoUrl: cdn.URL + "/ok.mp4", TambnailUrl: cdn.URL + "/ok.jpg"}
+	deleted := entity.Video{VideoUrl: cdn.URL + "/deleted.mp4", TambnailUrl: cdn.URL + "/ok.jpg"}
+
+	shop := reader.NewEnterprisesData(entity.NewPathKey(healthyUrl),
+		builder.NewEnterpriseBuilder().WithUrl(healthyUrl).WithVideo(healthy).Build())
+	shop.Append(entity.NewPathKey(brokenUrl),
+		builder.NewEnterpriseBuilder().WithUrl(brokenUrl).WithVideo(deleted).WithFallback(&healthy).Build())
+
+	other := reader.NewEnterprisesData(entity.NewPathKey(otherUrl),
+		builder.NewEnterpriseBuilder().WithUrl(otherUrl).WithVideo(entity.Video{
+			VideoUrl:    cdn.URL + "/ok.mp4",
+			TambnailUrl: cdn.URL + "/missing.jpg",
+			Renditions:  []entity.Rendition{{Url: "/relative.mp4"}},
+		}).Build())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().
+		ListEnterprises(gomock.Any()).
+		Return([]entity.EnterpriseKey{"shop.com", "other.com", "corrupted.com"}, nil)
+	mockRepo.EXPECT().Get(gomock.Any(), entity.EnterpriseKey("shop.com")).Return(shop, nil)
+	mockRepo.EXPECT().Get(gomock.Any(), entity.EnterpriseKey("other.com")).Return(other, nil)
+	mockRepo.EXPECT().Get(gomock.Any(), entity.EnterpriseKey("corrupted.com")).Return(nil, errors.New("invalid json"))
+
+	// the content of the rule cached before its link broke now falls back
+	mockCache := NewMockContentCache(ctrl)
+	mockCache.EXPECT().Invalidate("rule/shop.com/sale").Return(nil)
+
+	monitor := NewMonitor(mockRepo, nil, linkcheck.NewChecker(linkcheck.Config{RatePerSecond: 1000, Backoff: time.Millisecond, Client: cdn.Client()}), mockCache)
+	assert.Equal(t, StatusPending, monitor.Report().Status)
+
+	err := monitor.CheckAll(context.Background())
+	assert.NoError(t, err)
+
+	report := monitor.Report()
+	assert.Equal(t, StatusDegraded, report.Status)
+	assert.NotNil(t, report.CheckedAt)
+	// the relative rendition url is skipped and repeated urls are checked once
+	assert.Equal(t, 4, report.UrlsChecked)
+
+	if assert.Len(t, report.BrokenRules, 2) {
+		otherRule := report.BrokenRules[0]
+		assert.Equal(t, "other.com", otherRule.Enterprise)
+		assert.Equal(t, "/home", otherRule.Path)
+		assert.False(t, otherRule.HasFallback)
+		assert.Equal(t, cdn.URL+"/missing.jpg", otherRule.Links[0].Url)
+		assert.Equal(t, http.StatusNotFound, otherRule.Links[0].StatusCode)
+
+		saleRule := report.BrokenRules[1]
+		assert.Equal(t, "shop.com", saleRule.Enterprise)
+		assert.Equal(t, "/sale", saleRule.Path)
+		assert.True(t, saleRule.HasFallback)
+		assert.Len(t, saleRule.Links, 1)
+	}
+
+	assert.True(t, monitor.IsBroken(cdn.URL+"/deleted.mp4"))
+	assert.False(t, monitor.IsBroken(cdn.URL+"/ok.mp4"))
+	assert.False(t, monitor.IsBroken("https://never-checked.com/a.mp4"))
+}
+
+func TestMonitor_CheckAllCanceled(t *testing.T) {
+	cdn := newCdn(t)
+	endpoint, _ := url.Parse("https://shop.com/home")
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().ListEnterprises(gomock.Any()).Return([]entity.EnterpriseKey{"shop.com"}, nil)
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(reader.NewEnterprisesData(entity.NewPathKey(endpoint),
+		builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(entity.Video{VideoUrl: cdn.URL + "/ok.mp4"}).Build()), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	monitor := NewMonitor(mockRepo, nil, linkcheck.NewChecker(linkcheck.Config{Client: cdn.Client()}), nil)
+	err := monitor.CheckAll(ctx)
+
+	// the previous report is kept
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StatusPending, monitor.Report().Status)
+	assert.False(t, monitor.IsBroken(cdn.URL+"/ok.mp4"))
+}
+
+func TestMonitor_CheckAllCatalog(t *testing.T) {
+	cdn := newCdn(t)
+
+	homeUrl, _ := url.Parse("https://shop.com/home")
+	saleUrl, _ := url.Parse("https://shop.com/sale")
+
+	shop := reader.NewEnterprisesData(entity.NewPathKey(homeUrl),
+		builder.NewEnterpriseBuilder().WithUrl(homeUrl).WithVideo(entity.Video{CatalogId: "intro"}).Build())
+	shop.Append(entity.NewPathKey(saleUrl),
+		builder.NewEnterpriseBuilder().WithUrl(saleUrl).WithVideo(entity.Video{CatalogId: "intro"}).Build())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().ListEnterprises(gomock.Any()).Return([]entity.EnterpriseKey{"shop.com"}, nil)
+	mockRepo.EXPECT().Get(gomock.Any(), entity.EnterpriseKey("shop.com")).Return(shop, nil)
+
+	// the catalog video is read once for both rules
+	mockCatalog := NewMockCatalogRepository(ctrl)
+	mockCatalog.EXPECT().GetVideo(gomock.Any(), "intro").Return(entity.CatalogVideo{
+		Id:    "intro",
+		Video: entity.Video{VideoUrl: cdn.URL + "/deleted.mp4", TambnailUrl: cdn.URL + "/ok.jpg"},
+	}, nil).Times(1)
+
+	monitor := NewMonitor(mockRepo, mockCatalog, linkcheck.NewChecker(linkcheck.Config{RatePerSecond: 1000, Backoff: time.Millisecond, Client: cdn.Client()}), nil)
+
+	err := monitor.CheckAll(context.Background())
+	assert.NoError(t, err)
+
+	report := monitor.Report()
+	assert.Equal(t, 2, report.UrlsChecked)
+	assert.Len(t, report.BrokenRules, 2)
+	assert.True(t, monitor.IsBroken(cdn.URL+"/deleted.mp4"))
+}
+
+func TestMonitor_CheckAllSkipped(t *testing.T) {
+	// a private CDN, refused by the public client of the checker
+	cdn := newCdn(t)
+	endpoint, _ := url.Parse("https://shop.com/home")
+	video := entity.Video{VideoUrl: cdn.URL + "/ok.mp4", TambnailUrl: cdn.URL + "/ok.jpg"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().ListEnterprises(gomock.Any()).Return([]entity.EnterpriseKey{"shop.com"}, nil)
+	mockRepo.EXPECT().Get(gomock.Any(), gomock.Any()).Return(reader.NewEnterprisesData(entity.NewPathKey(endpoint),
+		builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).
+			WithFallback(&entity.Video{VideoUrl: cdn.URL + "/backup.mp4", TambnailUrl: cdn.URL + "/ok.jpg"}).Build()), nil)
+
+	// no rule falls back, there is nothing to invalidate
+	mockCache := NewMockContentCache(ctrl)
+
+	monitor := NewMonitor(mockRepo, nil, linkcheck.NewChecker(linkcheck.Config{RatePerSecond: 1000}), mockCache)
+	assert.NoError(t, monitor.CheckAll(context.Background()))
+
+	report := monitor.Report()
+	assert.Equal(t, StatusOk, report.Status)
+	assert.Empty(t, report.BrokenRules)
+	assert.Equal(t, 0, report.UrlsChecked)
+	assert.Equal(t, []string{cdn.URL + "/backup.mp4", cdn.URL + "/ok.jpg", cdn.URL + "/ok.mp4"}, report.SkippedUrls)
+	assert.False(t, monitor.IsBroken(video.VideoUrl))
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"github.com/IsaacDSC/search_content/internal/content/health"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
 	"github.com/IsaacDSC/search_content/internal/content/writer"
 	"net/http"
@@ -9,20 +10,40 @@ import (
 type Handler struct {
 	wh writer.Handler
 	rh reader.Handler
+	hh health.Handler
 }
 
-func NewHandler(wh writer.Handler, rh reader.Handler) *Handler {
+func NewHandler(wh writer.Handler, rh reader.Handler, hh health.Handler) *Handler {
 	return &Handler{
 		wh: wh,
 		rh: rh,
+		hh: hh,
 	}
 }
 
 func (h Handler) GetRoutes() map[string]func(w http.ResponseWriter, r *http.Request) error {
 	return map[string]func(w http.ResponseWriter, r *http.Request) error{
-		"GET /health":             h.health,
-		"POST /content":           h.wh.SaveContent,
-		"GET /content/{endpoint}": h.rh.GetContent,
+		"GET /health":                 h.health,
+		"GET /health/content":         h.hh.GetContentHealth,
+		"POST /content":               h.wh.SaveContent,
+		"GET /content/{endpoint}":     h.rh.GetContent,
+		"GET /captions/{file}":        h.rh.GetCaption,
+		"GET /manifest/{file}":        h.rh.GetManifest,
+		"GET /v/{endpoint}":           h.rh.RedirectVideo,
+		"GET /t/{endpoint}":           h.rh.RedirectThumbnail,
+		"GET /thumb/{endpoint}":       h.rh.GetThumbnail,
+		"POST /assets":                h.wh.UploadAsset,
+		"GET /assets/{id}":            h.rh.GetAsset,
+		"POST /videos":                h.wh.CreateVideo,
+		"GET /videos/{id}":            h.rh.GetVideo,
+		"PUT /videos/{id}":            h.wh.UpdateVideo,
+		"DELETE /videos/{id}":         h.wh.DeleteVideo,
+		"GET /videos/{id}/references": h.wh.GetVideoReferences,
+		"OPTIONS /uploads":            h.wh.UploadOptions,
+		"POST /uploads":               h.wh.CreateUpload,
+		"HEAD /uploads/{id}":          h.wh.GetUploadOffset,
+		"PATCH /uploads/{id}":         h.wh.PatchUpload,
+		"DELETE /uploads/{id}":        h.wh.DeleteUpload,
 	}
 }
 
@@ -0,0 +1,161 @@
+package container
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+type Config struct {
+	// PublicBaseUrl is the address clients reach this service at, e.g. "https://content.example.com".
+	// It prefixes the url of the files served by the service, such as caption tracks.
+	PublicBaseUrl string
+	// GeoIPDatabasePath is the local .mmdb file used to resolve the client region.
+	// Region targeting is disabled when empty.
+	GeoIPDatabasePath string
+	// TrustedProxies are the IPs/CIDRs allowed to set X-Forwarded-For.
+	TrustedProxies []string
+	// UrlSigningConfigPath is the JSON file with the url signing keys of each enterprise.
+	// Urls are returned unsigned when empty.
+	UrlSigningConfigPath string
+	// PlaceholderVideoUrl and PlaceholderThumbnailUrl are the redirect targets of
+	// /v/{endpoint} and /t/{endpoint} when the endpoint has no content.
+	PlaceholderVideoUrl     string
+	PlaceholderThumbnailUrl string
+	// ThumbnailRoots maps url prefixes to local directories holding the same files,
+	// e.g. "https://cdn.example.com/=/srv/cdn". Thumbnails found there, and uploaded
+	// assets, get a BlurHash and a dominant color when the rule is saved.
+	ThumbnailRoots map[string]string
+	// LinkCheckInterval is how often the video and thumbnail urls of every rule are
+	// checked, e.g. "6h". Dead links are not checked when empty.
+	LinkCheckInterval time.Duration
+	// LinkCheckRate is the maximum number of requests per second of the check.
+	LinkCheckRate float64
+	// HealthToken is the bearer token of GET /health/content, which lists the
+	// links found broken. The report isn't served when empty.
+	HealthToken string
+	// DataDir is the directory of the rules, captions and catalog files.
+	// Defaults to "assets/tmp" under the working directory when empty.
+	DataDir string
+	// DataLayout is how the files are laid out in DataDir, "flat" or "sharded".
+	// Existing files are moved to another layout with cmd/relayout.
+	DataLayout filesystem.Layout
+	// DataFormat is the format of the files written, e.g. "json" or "msgpack+zstd".
+	// Files of every format are read, existing ones are converted with cmd/convertstore.
+	DataFormat filesystem.Format
+	// DataBackend stores the rules, FileSystemBackend by default. The captions
+	// and catalog stay in DataDir whatever the backend, and the assets in
+	// "assets/blobs" under the working directory.
+	DataBackend string
+}
+
+const (
+	// FileSystemBackend stores the rules of each host in a file of DataDir.
+	FileSystemBackend = "filesystem"
+	// BoltBackend stores the rules in a bbolt database in DataDir, a key per rule.
+	BoltBackend = "bolt"
+	// SQLiteBackend stores the rules in tables of a SQLite database in DataDir.
+	SQLiteBackend = "sqlite"
+)
+
+func NewConfigFromEnv() Config {
+	return Config{
+		PublicBaseUrl:           os.Getenv("PUBLIC_BASE_URL"),
+		GeoIPDatabasePath:       os.Getenv("GEOIP_DATABASE_PATH"),
+		TrustedProxies:          splitList(os.Getenv("TRUSTED_PROXIES")),
+		UrlSigningConfigPath:    os.Getenv("URL_SIGNING_CONFIG_PATH"),
+		PlaceholderVideoUrl:     os.Getenv("PLACEHOLDER_VIDEO_URL"),
+		PlaceholderThumbnailUrl: os.Getenv("PLACEHOLDER_THUMBNAIL_URL"),
+		ThumbnailRoots:          splitMap(os.Getenv("THUMBNAIL_LOCAL_ROOTS")),
+		LinkCheckInterval:       parseDuration(os.Getenv("LINK_CHECK_INTERVAL")),
+		LinkCheckRate:           parseFloat(os.Getenv("LINK_CHECK_RATE")),
+		HealthToken:             os.Getenv("HEALTH_TOKEN"),
+		DataDir:                 os.Getenv("DATA_DIR"),
+		DataLayout:              parseLayout(os.Getenv("DATA_LAYOUT")),
+		DataFormat:              parseFormat(os.Getenv("DATA_FORMAT")),
+		DataBackend:             parseBackend(os.Getenv("DATA_BACKEND")),
+	}
+}
+
+// parseDuration panics on invalid values, like the containers do
+// when the service can't start as configured.
+func parseDuration(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		panic("Invalid duration " + value + ": " + err.Error())
+	}
+
+	return d
+}
+
+func parseFloat(value string) float64 {
+	if value == "" {
+		return 0
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		panic("Invalid number " + value + ": " + err.Error())
+	}
+
+	return f
+}
+
+func parseLayout(value string) filesystem.Layout {
+	layout, err := filesystem.ParseLayout(value)
+	if err != nil {
+		panic("Invalid data layout: " + err.Error())
+	}
+
+	return layout
+}
+
+func parseFormat(value string) filesystem.Format {
+	format, err := filesystem.ParseFormat(value)
+	if err != nil {
+		panic("Invalid data format: " + err.Error())
+	}
+
+	return format
+}
+
+func parseBackend(value string) string {
+	switch value {
+	case "":
+		return FileSystemBackend
+	case FileSystemBackend, BoltBackend, SQLiteBackend:
+		return value
+	}
+
+	panic("Invalid data backend: " + value)
+}
+
+func splitList(value string) []string {
+	var output []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			output = append(output, v)
+		}
+	}
+
+	return output
+}
+
+// splitMap parses a "key=value,key=value" list.
+func splitMap(value string) map[string]string {
+	output := map[string]string{}
+	for _, v := range splitList(value) {
+		if k, v, ok := strings.Cut(v, "="); ok {
+			output[k] = v
+		}
+	}
+
+	return output
+}
@@ -1,21 +1,86 @@
 package container
 
 import (
+	"context"
+	"github.com/IsaacDSC/search_content/internal/content/health"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
 	"github.com/IsaacDSC/search_content/internal/content/writer"
+	"github.com/IsaacDSC/search_content/pkg/cache"
+	"github.com/IsaacDSC/search_content/pkg/geoip"
+	"github.com/IsaacDSC/search_content/pkg/tus"
+	"io"
+	"time"
+)
+
+const (
+	// uploadDir keeps the resumable uploads until they are complete.
+	uploadDir = "assets/uploads"
+	// uploadExpiration is how long an abandoned upload is kept after its last chunk.
+	uploadExpiration      = 24 * time.Hour
+	uploadCleanupInterval = time.Hour
+
+	// thumbnailCacheDir keeps the resized thumbnails, up to thumbnailCacheSize bytes.
+	thumbnailCacheDir  = "assets/thumbnails"
+	thumbnailCacheSize = 512 << 20 // 512MB
 )
 
 type Handlers struct {
 	WriterHandler writer.Handler
 	ReaderHandler reader.Handler
+	HealthHandler health.Handler
 }
 
-func GetHandlers(services ServicesContainer) Handlers {
-	wh := writer.NewHandler(services.WriterService)
-	rh := reader.NewHandler(services.ReaderService)
+func GetHandlers(services ServicesContainer, cfg Config) Handlers {
+	wh := writer.NewHandler(services.WriterService, services.UploadHandler)
+	rh := reader.NewHandler(services.ReaderService, newRegionResolver(cfg), reader.Placeholders{
+		Video:     cfg.PlaceholderVideoUrl,
+		Thumbnail: cfg.PlaceholderThumbnailUrl,
+	}, newThumbnailCache(), cfg.PublicBaseUrl)
 
 	return Handlers{
 		WriterHandler: wh,
 		ReaderHandler: rh,
+		HealthHandler: health.NewHandler(services.HealthService, cfg.HealthToken),
 	}
 }
+
+func newRegionResolver(cfg Config) reader.RegionResolver {
+	if cfg.GeoIPDatabasePath == "" {
+		return nil
+	}
+
+	resolver, err := geoip.NewResolver(cfg.GeoIPDatabasePath, cfg.TrustedProxies)
+	if err != nil {
+		panic("Failed to initialize geoip: " + err.Error())
+	}
+
+	return resolver
+}
+
+func newThumbnailCache() reader.ThumbnailCache {
+	thumbnails, err := cache.NewDiskCache(thumbnailCacheDir, thumbnailCacheSize)
+	if err != nil {
+		panic("Failed to initialize thumbnail cache: " + err.Error())
+	}
+
+	return thumbnails
+}
+
+// newUploadHandler starts removing the abandoned uploads until stop is called.
+func newUploadHandler(writerService writer.Service, cfg Config) (uploads *tus.Handler, stop func()) {
+	store, err := tus.NewFileStore(uploadDir)
+	if err != nil {
+		panic("Failed to initialize uploads: " + err.Error())
+	}
+
+	uploads = tus.NewHandler(store, tus.Config{
+		BasePath:   cfg.PublicBaseUrl + "/uploads/",
+		MaxSize:    writer.MaxAssetSize,
+		Expiration: uploadExpiration,
+		Complete: func(ctx context.Context, _ tus.Upload, data io.Reader) (string, error) {
+			asset, err := writerService.UploadAsset(ctx, data)
+			return asset.Url, err
+		},
+	})
+	return uploads, uploads.StartCleanup(uploadCleanupInterval)
+}
@@ -1,18 +1,70 @@
 package container
 
 import (
+	"path/filepath"
+
 	"github.com/IsaacDSC/search_content/internal/content/infra/repository"
 	"github.com/IsaacDSC/search_content/pkg/filesystem"
 )
 
+const (
+	// defaultDataDir is the DataDir when empty, as the filesystem driver defaults to.
+	defaultDataDir = "assets/tmp"
+	// boltFile is the database of BoltBackend in DataDir.
+	boltFile = "rules.db"
+	// sqliteFile is the database of SQLiteBackend in DataDir.
+	sqliteFile = "rules.sqlite"
+)
+
 type RepositoryContainer struct {
-	Repository repository.Repository
+	Repository        repository.Repository
+	CaptionRepository repository.CaptionRepository
+	AssetRepository   repository.AssetRepository
+	CatalogRepository repository.CatalogRepository
 }
 
-func NewRepositoryContainer() RepositoryContainer {
-	fsDriver := filesystem.NewFileSystem()
-	repo := repository.NewFileSystemRepo(fsDriver)
+func NewRepositoryContainer(cfg Config) RepositoryContainer {
+	opts := []filesystem.Option{filesystem.WithLayout(cfg.DataLayout), filesystem.WithFormat(cfg.DataFormat)}
+	if cfg.DataDir != "" {
+		opts = append(opts, filesystem.WithDir(cfg.DataDir))
+	}
+
+	fsDriver := filesystem.NewFileSystem(opts...)
+	repo := newRepository(cfg, fsDriver)
+	captionRepo := repository.NewCaptionFileSystemRepo(fsDriver)
+	catalogRepo := repository.NewCatalogFileSystemRepo(fsDriver)
+	assetRepo := repository.NewAssetFileSystemRepo(filesystem.NewBlobFileSystem())
 	return RepositoryContainer{
-		Repository: repo,
+		Repository:        repo,
+		CaptionRepository: captionRepo,
+		AssetRepository:   assetRepo,
+		CatalogRepository: catalogRepo,
 	}
 }
+
+// newRepository returns the repository of the rules of cfg.DataBackend.
+func newRepository(cfg Config, fsDriver filesystem.Driver) repository.Repository {
+	dataDir := cfg.DataDir
+	if dataDir == "" {
+		dataDir = defaultDataDir
+	}
+
+	switch cfg.DataBackend {
+	case BoltBackend:
+		repo, err := repository.NewBoltRepo(filepath.Join(dataDir, boltFile))
+		if err != nil {
+			panic("Failed to open rules database: " + err.Error())
+		}
+
+		return repo
+	case SQLiteBackend:
+		repo, err := repository.NewSQLiteRepo(filepath.Join(dataDir, sqliteFile))
+		if err != nil {
+			panic("Failed to open rules database: " + err.Error())
+		}
+
+		return repo
+	}
+
+	return repository.NewFileSystemRepo(fsDriver)
+}
@@ -1,21 +1,79 @@
 package container
 
 import (
+	"github.com/IsaacDSC/search_content/internal/content/health"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository"
+	"github.com/IsaacDSC/search_content/internal/content/infra/signing"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
 	"github.com/IsaacDSC/search_content/internal/content/writer"
+	"github.com/IsaacDSC/search_content/pkg/linkcheck"
+	"github.com/IsaacDSC/search_content/pkg/tus"
 )
 
+// linkCheckConcurrency is the number of dead link requests in flight.
+const linkCheckConcurrency = 4
+
 type ServicesContainer struct {
 	WriterService writer.Service
 	ReaderService reader.Service
+	HealthService health.Service
+	UploadHandler *tus.Handler
+
+	stopHealth  func()
+	stopUploads func()
 }
 
-func NewServicesContainer(repositories RepositoryContainer) ServicesContainer {
-	writerService := writer.NewContentUseCase(repositories.Repository)
-	readerService := reader.NewContentUseCase(repositories.Repository)
+func NewServicesContainer(repositories RepositoryContainer, cacheStrategies CacheStrategies, cfg Config) ServicesContainer {
+	healthService, stopHealth := newHealthService(repositories, cacheStrategies, cfg)
+	writerService := writer.NewContentUseCase(repositories.Repository, repositories.CaptionRepository, repositories.AssetRepository, repositories.CatalogRepository, repository.NewLocalThumbnailSource(repositories.AssetRepository, cfg.PublicBaseUrl, cfg.ThumbnailRoots), cacheStrategies.LRUCache, cfg.PublicBaseUrl)
+	uploadHandler, stopUploads := newUploadHandler(writerService, cfg)
+	readerService := reader.NewContentUseCase(repositories.Repository, repositories.CaptionRepository, repositories.AssetRepository, repositories.CatalogRepository, newUrlSigner(cfg), healthService)
 
 	return ServicesContainer{
 		WriterService: writerService,
 		ReaderService: readerService,
+		HealthService: healthService,
+		UploadHandler: uploadHandler,
+		stopHealth:    stopHealth,
+		stopUploads:   stopUploads,
 	}
 }
+
+// Close stops the background work of the services.
+func (c ServicesContainer) Close() {
+	c.stopHealth()
+	c.stopUploads()
+}
+
+// newHealthService starts the dead link checker when LinkCheckInterval is set.
+// Otherwise the report stays pending and no video falls back.
+func newHealthService(repositories RepositoryContainer, cacheStrategies CacheStrategies, cfg Config) (*health.Monitor, func()) {
+	monitor := health.NewMonitor(repositories.Repository, repositories.CatalogRepository, linkcheck.NewChecker(linkcheck.Config{
+		Concurrency:   linkCheckConcurrency,
+		RatePerSecond: cfg.LinkCheckRate,
+	}), cacheStrategies.LRUCache)
+
+	if cfg.LinkCheckInterval > 0 {
+		return monitor, monitor.Start(cfg.LinkCheckInterval)
+	}
+
+	return monitor, func() {}
+}
+
+func newUrlSigner(cfg Config) reader.UrlSigner {
+	if cfg.UrlSigningConfigPath == "" {
+		return nil
+	}
+
+	configs, err := signing.LoadConfig(cfg.UrlSigningConfigPath)
+	if err != nil {
+		panic("Failed to load url signing config: " + err.Error())
+	}
+
+	signer, err := signing.NewEnterpriseSigner(configs)
+	if err != nil {
+		panic("Failed to initialize url signing: " + err.Error())
+	}
+
+	return signer
+}
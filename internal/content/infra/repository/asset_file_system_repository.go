@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+	"io"
+)
+
+type AssetFileSystemRepo struct {
+	blobs filesystem.BlobStore
+}
+
+var _ AssetRepository = (*AssetFileSystemRepo)(nil)
+
+func NewAssetFileSystemRepo(blobs filesystem.BlobStore) *AssetFileSystemRepo {
+	return &AssetFileSystemRepo{blobs: blobs}
+}
+
+func (r AssetFileSystemRepo) SaveAsset(ctx context.Context, body io.Reader) (entity.Asset, error) {
+	info, err := r.blobs.Put(ctx, body)
+	if err != nil {
+		return entity.Asset{}, err
+	}
+
+	return entity.Asset{Id: info.Id, Size: info.Size, ModTime: info.ModTime}, nil
+}
+
+func (r AssetFileSystemRepo) GetAsset(ctx context.Context, id string) (entity.Asset, io.ReadSeekCloser, error) {
+	file, info, err := r.blobs.Open(ctx, id)
+	if errors.Is(err, filesystem.ErrFileNotFound) || errors.Is(err, filesystem.ErrInvalidBlobId) {
+		return entity.Asset{}, nil, reader.ErrAssetNotFound
+	}
+
+	if err != nil {
+		return entity.Asset{}, nil, err
+	}
+
+	return entity.Asset{Id: info.Id, Size: info.Size, ModTime: info.ModTime}, file, nil
+}
+
+func (r AssetFileSystemRepo) AssetExists(ctx context.Context, id string) (bool, error) {
+	file, _, err := r.blobs.Open(ctx, id)
+	if errors.Is(err, filesystem.ErrFileNotFound) || errors.Is(err, filesystem.ErrInvalidBlobId) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, file.Close()
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/internal/content/writer"
+	bolt "go.etcd.io/bbolt"
+)
+
+// rulesBucket holds a key per rule: the host and the path of its url,
+// separated by a NUL byte, which sorts the rules of a host together and
+// before the ones of any longer host. A url can't hold a NUL byte.
+var rulesBucket = []byte("rules")
+
+// boltOpenTimeout is how long Open waits for another process to release
+// the database, instead of blocking forever.
+const boltOpenTimeout = time.Second
+
+// BoltRepo stores the rules in a bbolt database, so saving a rule writes
+// only its own key instead of the whole file of the host. Each value is
+// the schema.EnterpriseDocument of a single rule, versioned and migrated
+// on read like the files of FileSystemRepo.
+type BoltRepo struct {
+	db *bolt.DB
+}
+
+var _ Repository = (*BoltRepo)(nil)
+
+// NewBoltRepo opens, or creates, the database at path.
+func NewBoltRepo(path string) (*BoltRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(rulesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create bucket: %w", err)
+	}
+
+	return &BoltRepo{db: db}, nil
+}
+
+func (r *BoltRepo) Close() error {
+	return r.db.Close()
+}
+
+func hostPrefix(enterpriseKey entity.EnterpriseKey) []byte {
+	return append([]byte(enterpriseKey), 0)
+}
+
+func ruleKey(enterpriseKey entity.EnterpriseKey, pathKey entity.PathKey) []byte {
+	return append(hostPrefix(enterpriseKey), pathKey...)
+}
+
+// Save puts the rule under its key, replacing the rule of the same path.
+func (r *BoltRepo) Save(ctx context.Context, enterprise entity.Enterprise) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	enterpriseKey := entity.NewEnterpriseKey(enterprise.Url)
+	pathKey := entity.NewPathKey(enterprise.Url)
+
+	key := ruleKey(enterpriseKey, pathKey)
+	if enterpriseKey == "" || len(key) > bolt.MaxKeySize {
+		return fmt.Errorf("%w: can't store host %q", writer.ErrInvalidEndpoint, enterpriseKey)
+	}
+
+	value, err := json.Marshal(schema.NewEnterpriseDocument(reader.NewEnterprisesData(pathKey, enterprise)))
+	if err != nil {
+		return fmt.Errorf("failed to encode rule: %w", err)
+	}
+
+	err = r.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(rulesBucket).Put(key, value)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save rule: %w", err)
+	}
+
+	return nil
+}
+
+// Get scans the keys of the host.
+func (r *BoltRepo) Get(ctx context.Context, enterpriseKey entity.EnterpriseKey) (reader.EnterpriseData, error) {
+	if err := ctx.Err(); err != nil {
+		return reader.EnterpriseData{}, err
+	}
+
+	prefix := hostPrefix(enterpriseKey)
+	data := reader.EnterpriseData{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(rulesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if err := decodeRules(v, data); err != nil {
+				return fmt.Errorf("rule %s: %w", k[len(prefix):], err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reader.EnterpriseData{}, err
+	}
+
+	if len(data) == 0 {
+		return reader.EnterpriseData{}, fmt.Errorf("%w: %s", reader.ErrContentNotFound, enterpriseKey)
+	}
+
+	return data, nil
+}
+
+// decodeRules adds the rules of the value to data. The value is only
+// valid during its transaction, and is copied by the decoding.
+func decodeRules(value []byte, data reader.EnterpriseData) error {
+	var doc schema.EnterpriseDocument
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return err
+	}
+
+	rules, err := doc.ToDomain()
+	if err != nil {
+		return err
+	}
+
+	for pathKey, enterprise := range rules {
+		data[pathKey] = enterprise
+	}
+
+	return nil
+}
+
+// ListEnterprises seeks past the keys of each host, reading a single key
+// per host.
+func (r *BoltRepo) ListEnterprises(ctx context.Context) ([]entity.EnterpriseKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var keys []entity.EnterpriseKey
+	err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(rulesBucket).Cursor()
+		for k, _ := c.First(); k != nil; {
+			host, _, _ := bytes.Cut(k, []byte{0})
+			keys = append(keys, entity.EnterpriseKey(host))
+
+			// the first key after the ones of the host
+			k, _ = c.Seek(append(bytes.Clone(host), 1))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enterprises: %w", err)
+	}
+
+	return keys, nil
+}
+
+// FindVideoReferences reads every rule, as FileSystemRepo does, but in a
+// single transaction.
+func (r *BoltRepo) FindVideoReferences(ctx context.Context, id string) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var endpoints []string
+	err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(rulesBucket).ForEach(func(k, v []byte) error {
+			data := reader.EnterpriseData{}
+			if err := decodeRules(v, data); err != nil {
+				return fmt.Errorf("rule %q: %w", k, err)
+			}
+
+			for _, enterprise := range data {
+				if slices.Contains(enterprise.CatalogIds(), id) {
+					endpoints = append(endpoints, enterprise.Url.String())
+				}
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(endpoints)
+
+	return endpoints, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+type CaptionFileSystemRepo struct {
+	fsDrive filesystem.Driver
+}
+
+var _ CaptionRepository = (*CaptionFileSystemRepo)(nil)
+
+func NewCaptionFileSystemRepo(fsDrive filesystem.Driver) *CaptionFileSystemRepo {
+	return &CaptionFileSystemRepo{fsDrive: fsDrive}
+}
+
+func newCaptionFileName(id string) filesystem.FileName {
+	return filesystem.NewFileName("captions/" + id)
+}
+
+func (r CaptionFileSystemRepo) SaveCaption(ctx context.Context, track entity.CaptionTrack) error {
+	fileName := newCaptionFileName(track.Id)
+
+	// tracks are content addressed, an existing file already has the same body
+	exists, err := r.fsDrive.FileExists(ctx, fileName)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return nil
+	}
+
+	if err := r.fsDrive.Save(ctx, fileName, track); err != nil {
+		return fmt.Errorf("failed to save caption file: %w", err)
+	}
+
+	return nil
+}
+
+func (r CaptionFileSystemRepo) GetCaption(ctx context.Context, id string) (entity.CaptionTrack, error) {
+	var track entity.CaptionTrack
+	err := r.fsDrive.Get(ctx, newCaptionFileName(id), &track)
+	if errors.Is(err, filesystem.ErrFileNotFound) {
+		return entity.CaptionTrack{}, reader.ErrCaptionNotFound
+	}
+
+	if err != nil {
+		return entity.CaptionTrack{}, err
+	}
+
+	return track, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCaptionFileSystemRepo_SaveCaption(t *testing.T) {
+	track := entity.NewCaptionTrack("WEBVTT\n")
+	fileName := filesystem.NewFileName("captions/" + track.Id)
+
+	tests := []struct {
+		name      string
+		setupMock func(mockDriver *filesystem.MockDriver)
+	}{
+		{
+			name: "save new track",
+			setupMock: func(mockDriver *filesystem.MockDriver) {
+				mockDriver.EXPECT().FileExists(gomock.Any(), fileName).Return(false, nil)
+				mockDriver.EXPECT().Save(gomock.Any(), fileName, track).Return(nil)
+			},
+		},
+		{
+			name: "skip existing track",
+			setupMock: func(mockDriver *filesystem.MockDriver) {
+				mockDriver.EXPECT().FileExists(gomock.Any(), fileName).Return(true, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockDriver := filesystem.NewMockDriver(ctrl)
+			tt.setupMock(mockDriver)
+
+			err := NewCaptionFileSystemRepo(mockDriver).SaveCaption(context.Background(), track)
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCaptionFileSystemRepo_GetCaption(t *testing.T) {
+	track := entity.NewCaptionTrack("WEBVTT\n")
+	fileName := filesystem.NewFileName("captions/" + track.Id)
+
+	t.Run("existing track", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDriver := filesystem.NewMockDriver(ctrl)
+		mockDriver.EXPECT().
+			Get(gomock.Any(), fileName, gomock.Any()).
+			DoAndReturn(storedAs(map[string]any{"Id": track.Id, "Body": track.Body}))
+
+		got, err := NewCaptionFileSystemRepo(mockDriver).GetCaption(context.Background(), track.Id)
+
+		assert.NoError(t, err)
+		assert.Equal(t, track, got)
+	})
+
+	t.Run("missing track", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDriver := filesystem.NewMockDriver(ctrl)
+		mockDriver.EXPECT().
+			Get(gomock.Any(), fileName, gomock.Any()).
+			Return(filesystem.ErrFileNotFound)
+
+		_, err := NewCaptionFileSystemRepo(mockDriver).GetCaption(context.Background(), track.Id)
+
+		assert.ErrorIs(t, err, reader.ErrCaptionNotFound)
+	})
+}
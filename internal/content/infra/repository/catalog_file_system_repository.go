@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/internal/content/writer"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+type CatalogFileSystemRepo struct {
+	fsDrive filesystem.Driver
+}
+
+var _ CatalogRepository = (*CatalogFileSystemRepo)(nil)
+
+func NewCatalogFileSystemRepo(fsDrive filesystem.Driver) *CatalogFileSystemRepo {
+	return &CatalogFileSystemRepo{fsDrive: fsDrive}
+}
+
+func newCatalogFileName(id string) filesystem.FileName {
+	return filesystem.NewFileName("videos/" + id)
+}
+
+func (r CatalogFileSystemRepo) SaveVideo(ctx context.Context, video entity.CatalogVideo) error {
+	if err := r.fsDrive.Save(ctx, newCatalogFileName(video.Id), video); err != nil {
+		return fmt.Errorf("failed to save video file: %w", err)
+	}
+
+	return nil
+}
+
+func (r CatalogFileSystemRepo) VideoExists(ctx context.Context, id string) (bool, error) {
+	if !entity.IsValidCatalogId(id) {
+		return false, nil
+	}
+
+	return r.fsDrive.FileExists(ctx, newCatalogFileName(id))
+}
+
+func (r CatalogFileSystemRepo) GetVideo(ctx context.Context, id string) (entity.CatalogVideo, error) {
+	// the id comes from the request path, it must not escape the catalog directory
+	if !entity.IsValidCatalogId(id) {
+		return entity.CatalogVideo{}, reader.ErrVideoNotFound
+	}
+
+	var video entity.CatalogVideo
+	err := r.fsDrive.Get(ctx, newCatalogFileName(id), &video)
+	if errors.Is(err, filesystem.ErrFileNotFound) {
+		return entity.CatalogVideo{}, reader.ErrVideoNotFound
+	}
+
+	if err != nil {
+		return entity.CatalogVideo{}, err
+	}
+
+	return video, nil
+}
+
+func (r CatalogFileSystemRepo) DeleteVideo(ctx context.Context, id string) error {
+	if !entity.IsValidCatalogId(id) {
+		return writer.ErrVideoNotFound
+	}
+
+	err := r.fsDrive.Delete(ctx, newCatalogFileName(id))
+	if errors.Is(err, filesystem.ErrFileNotFound) {
+		return writer.ErrVideoNotFound
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to delete video file: %w", err)
+	}
+
+	return nil
+}
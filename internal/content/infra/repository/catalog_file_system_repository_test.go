@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/internal/content/writer"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCatalogFileSystemRepo_GetVideo(t *testing.T) {
+	video := entity.CatalogVideo{
+		Id:        "intro",
+		Video:     entity.Video{VideoUrl: "https://cdn.com/intro.mp4", TambnailUrl: "https://cdn.com/intro.jpg"},
+		UpdatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("existing video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDriver := filesystem.NewMockDriver(ctrl)
+		mockDriver.EXPECT().Get(gomock.Any(), filesystem.NewFileName("videos/intro"), gomock.Any()).DoAndReturn(storedAs(video))
+
+		got, err := NewCatalogFileSystemRepo(mockDriver).GetVideo(context.Background(), "intro")
+
+		assert.NoError(t, err)
+		assert.Equal(t, video, got)
+	})
+
+	t.Run("missing video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockDriver := filesystem.NewMockDriver(ctrl)
+		mockDriver.EXPECT().Get(gomock.Any(), filesystem.NewFileName("videos/intro"), gomock.Any()).Return(filesystem.ErrFileNotFound)
+
+		_, err := NewCatalogFileSystemRepo(mockDriver).GetVideo(context.Background(), "intro")
+
+		assert.ErrorIs(t, err, reader.ErrVideoNotFound)
+	})
+
+	t.Run("id outside the catalog", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		_, err := NewCatalogFileSystemRepo(filesystem.NewMockDriver(ctrl)).GetVideo(context.Background(), "../example.com")
+
+		assert.ErrorIs(t, err, reader.ErrVideoNotFound)
+	})
+}
+
+func TestCatalogFileSystemRepo_DeleteVideo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockDriver := filesystem.NewMockDriver(ctrl)
+	mockDriver.EXPECT().Delete(gomock.Any(), filesystem.NewFileName("videos/intro")).Return(filesystem.ErrFileNotFound)
+
+	err := NewCatalogFileSystemRepo(mockDriver).DeleteVideo(context.Background(), "intro")
+
+	assert.ErrorIs(t, err, writer.ErrVideoNotFound)
+}
+
+func TestFileSystemRepo_FindVideoReferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	home, _ := url.Parse("https://example.com/home")
+	about, _ := url.Parse("https://example.com/about")
+	other, _ := url.Parse("https://other.com/home")
+
+	stored := func(rules ...entity.Enterprise) func(context.Context, filesystem.FileName, any) error {
+		data := reader.EnterpriseData{}
+		for _, rule := range rules {
+			data.Append(entity.NewPathKey(rule.Url), rule)
+		}
+
+		return storedAs(data)
+	}
+
+	mockDriver := filesystem.NewMockDriver(ctrl)
+	mockDriver.EXPECT().List(gomock.Any()).Return([]filesystem.FileName{
+		filesystem.NewFileName("example.com"),
+		filesystem.NewFileName("other.com"),
+	}, nil)
+	mockDriver.EXPECT().Get(gomock.Any(), filesystem.NewFileName("example.com"), gomock.Any()).DoAndReturn(stored(
+		entity.Enterprise{Url: home, Video: entity.Video{CatalogId: "intro"}},
+		entity.Enterprise{Url: about, Video: entity.Video{VideoUrl: "https://cdn.com/a.mp4"}, Fallback: &entity.Video{CatalogId: "intro"}},
+	))
+	mockDriver.EXPECT().Get(gomock.Any(), filesystem.NewFileName("other.com"), gomock.Any()).DoAndReturn(stored(
+		entity.Enterprise{Url: other, Video: entity.Video{CatalogId: "outro"}},
+	))
+
+	endpoints, err := NewFileSystemRepo(mockDriver).FindVideoReferences(context.Background(), "intro")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/about", "https://example.com/home"}, endpoints)
+}
@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
 	"github.com/IsaacDSC/search_content/internal/content/writer"
 	"github.com/IsaacDSC/search_content/pkg/filesystem"
+	"slices"
+	"sort"
+	"strings"
 )
 
 type FileSystemRepo struct {
@@ -21,24 +24,36 @@ func NewFileSystemRepo(fsDrive filesystem.Driver) *FileSystemRepo {
 	return &FileSystemRepo{fsDrive: fsDrive}
 }
 
+// Save adds the rule to the file of its enterprise. The file is read and
+// written under the same lock, so concurrent saves for a host are all kept.
+// The file is written at the current schema version, whatever the one read.
 func (r FileSystemRepo) Save(ctx context.Context, enterprise entity.Enterprise) error {
 	enterpriseKey := entity.NewEnterpriseKey(enterprise.Url)
 	pathKey := entity.NewPathKey(enterprise.Url)
+	fileName := filesystem.NewFileName(enterpriseKey.String())
 
-	result, err := r.Get(ctx, enterpriseKey)
-	if !errors.Is(filesystem.ErrFileNotFound, err) && err != nil {
-		return err
-	}
+	err := r.fsDrive.Update(ctx, fileName, func(current filesystem.DecodeFunc) (any, error) {
+		if current == nil {
+			return schema.NewEnterpriseDocument(reader.NewEnterprisesData(pathKey, enterprise)), nil
+		}
+
+		var doc schema.EnterpriseDocument
+		if err := current(&doc); err != nil {
+			return nil, err
+		}
 
-	if errors.Is(filesystem.ErrFileNotFound, err) {
-		data := reader.NewEnterprisesData(pathKey, enterprise)
-		fileName := filesystem.NewFileName(enterpriseKey.String())
-		return r.fsDrive.Save(ctx, fileName, data)
+		if doc.Rules == nil {
+			doc.Rules = make(map[string]schema.Rule)
+		}
+		doc.Rules[string(pathKey)] = schema.NewRule(enterprise)
+
+		return doc, nil
+	})
+	if errors.Is(err, filesystem.ErrInvalidFileName) {
+		return fmt.Errorf("%w: %w", writer.ErrInvalidEndpoint, err)
 	}
 
-	fileName := filesystem.NewFileName(enterpriseKey.String())
-	data := result.Append(pathKey, enterprise)
-	if err := r.fsDrive.Save(ctx, fileName, data); err != nil {
+	if err != nil {
 		return fmt.Errorf("failed to save enterprise file: %w", err)
 	}
 
@@ -47,28 +62,122 @@ func (r FileSystemRepo) Save(ctx context.Context, enterprise entity.Enterprise)
 
 func (r FileSystemRepo) Get(ctx context.Context, enterpriseKey entity.EnterpriseKey) (reader.EnterpriseData, error) {
 	fileName := filesystem.NewFileName(enterpriseKey.String())
-	data, err := r.fsDrive.Get(ctx, fileName)
+	var doc schema.EnterpriseDocument
+	err := r.fsDrive.Get(ctx, fileName, &doc)
+
+	// no rule can be saved for a host without a valid file name
+	if errors.Is(err, filesystem.ErrFileNotFound) || errors.Is(err, filesystem.ErrInvalidFileName) {
+		return reader.EnterpriseData{}, fmt.Errorf("%w: %w", reader.ErrContentNotFound, err)
+	}
 
 	if err != nil {
 		return reader.EnterpriseData{}, err
 	}
 
-	output, ok := data.(map[string]any)
-	if !ok {
-		return reader.EnterpriseData{}, writer.ErrInvalidDataType
+	return doc.ToDomain()
+}
+
+// MigrateEnterprises rewrites at the current schema version the files of
+// the enterprises written at an older one, and returns the number of files
+// rewritten. Files read at an older version are migrated anyway, this only
+// spares the migration on each read.
+func (r FileSystemRepo) MigrateEnterprises(ctx context.Context) (int, error) {
+	keys, err := r.ListEnterprises(ctx)
+	if err != nil {
+		return 0, err
 	}
 
-	// Convert map to JSON bytes
-	jsonBytes, err := json.Marshal(output)
+	migrated := 0
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return migrated, err
+		}
+
+		err := r.fsDrive.Update(ctx, filesystem.NewFileName(key.String()), func(current filesystem.DecodeFunc) (any, error) {
+			if current == nil {
+				return nil, errUpToDate
+			}
+
+			var doc schema.EnterpriseDocument
+			if err := current(&doc); err != nil {
+				return nil, err
+			}
+
+			if !doc.Upgraded() {
+				return nil, errUpToDate
+			}
+
+			return doc, nil
+		})
+		if errors.Is(err, errUpToDate) {
+			continue
+		}
+
+		if err != nil {
+			return migrated, fmt.Errorf("failed to migrate enterprise %s: %w", key, err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
+
+// errUpToDate aborts the Update of a file already at the current version.
+var errUpToDate = errors.New("up to date")
+
+func (r FileSystemRepo) ListEnterprises(ctx context.Context) ([]entity.EnterpriseKey, error) {
+	files, err := r.fsDrive.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]entity.EnterpriseKey, 0, len(files))
+	for _, file := range files {
+		keys = append(keys, entity.EnterpriseKey(file.Key()))
+	}
+
+	return keys, nil
+}
+
+// FindVideoReferences reads every rule, the catalog is small enough
+// for the writes that change it to afford a scan.
+func (r FileSystemRepo) FindVideoReferences(ctx context.Context, id string) ([]string, error) {
+	keys, err := r.ListEnterprises(ctx)
 	if err != nil {
-		return reader.EnterpriseData{}, fmt.Errorf("failed to marshal data: %w", err)
+		return nil, err
 	}
 
-	// Unmarshal JSON into EnterpriseData
-	var enterpriseData reader.EnterpriseData
-	if err := json.Unmarshal(jsonBytes, &enterpriseData); err != nil {
-		return reader.EnterpriseData{}, fmt.Errorf("failed to unmarshal data: %w", err)
+	var endpoints []string
+	for _, key := range keys {
+		data, err := r.Get(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read enterprise %s: %w", key, err)
+		}
+
+		for _, enterprise := range data {
+			if slices.Contains(enterprise.CatalogIds(), id) {
+				endpoints = append(endpoints, enterprise.Url.String())
+			}
+		}
 	}
 
-	return enterpriseData, nil
+	sort.Strings(endpoints)
+
+	return endpoints, nil
+}
+
+// NewFileValue returns a pointer to the type the file system repositories
+// store in the file of the given name, to decode it whatever its format:
+// the tracks of the caption repository, the videos of the catalog, and
+// the enterprises at the top level.
+func NewFileValue(name filesystem.FileName) any {
+	dir, _, nested := strings.Cut(name.Key(), "/")
+	switch {
+	case nested && dir == "captions":
+		return new(entity.CaptionTrack)
+	case nested && dir == "videos":
+		return new(entity.CatalogVideo)
+	default:
+		return new(schema.EnterpriseDocument)
+	}
 }
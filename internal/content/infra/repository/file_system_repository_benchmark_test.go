@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+// benchmarkRules is the number of rules in the file of the benchmarked host.
+const benchmarkRules = 100
+
+func newBenchmarkData(b *testing.B) reader.EnterpriseData {
+	data := reader.EnterpriseData{}
+	for i := range benchmarkRules {
+		endpoint, err := url.Parse(fmt.Sprintf("https://bench.com/page/%d", i))
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		data.Append(entity.NewPathKey(endpoint), entity.Enterprise{
+			Url:   endpoint,
+			Path:  endpoint.Path,
+			Video: entity.Video{VideoUrl: "https://cdn.com/video.mp4", TambnailUrl: "https://cdn.com/thumb.jpg"},
+		})
+	}
+
+	return data
+}
+
+// BenchmarkFileSystemRepo_Get reads the file of a host from the disk.
+func BenchmarkFileSystemRepo_Get(b *testing.B) {
+	ctx := context.Background()
+	fs := filesystem.NewFileSystem(filesystem.WithDir(b.TempDir()))
+	if err := fs.Save(ctx, filesystem.NewFileName("bench.com"), newBenchmarkData(b)); err != nil {
+		b.Fatal(err)
+	}
+
+	repo := NewFileSystemRepo(fs)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		if _, err := repo.Get(ctx, entity.EnterpriseKey("bench.com")); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDecodeEnterpriseData compares decoding the file straight into
+// its type with the previous map[string]any round trip, which paid three
+// JSON passes per read.
+func BenchmarkDecodeEnterpriseData(b *testing.B) {
+	content, err := json.Marshal(newBenchmarkData(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("typed", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			var data reader.EnterpriseData
+			if err := json.Unmarshal(content, &data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("map round trip", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			output := make(map[string]any)
+			if err := json.Unmarshal(content, &output); err != nil {
+				b.Fatal(err)
+			}
+
+			jsonBytes, err := json.Marshal(output)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			var data reader.EnterpriseData
+			if err := json.Unmarshal(jsonBytes, &data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -2,11 +2,18 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
+	"os"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
 	"github.com/IsaacDSC/search_content/internal/content/writer"
 	"github.com/IsaacDSC/search_content/pkg/filesystem"
@@ -14,6 +21,25 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// decoderOf decodes data as the driver does after it went through the disk.
+func decoderOf(data any) filesystem.DecodeFunc {
+	return func(v any) error {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(b, v)
+	}
+}
+
+// storedAs mocks a driver Get of a file holding data.
+func storedAs(data any) func(context.Context, filesystem.FileName, any) error {
+	return func(_ context.Context, _ filesystem.FileName, v any) error {
+		return decoderOf(data)(v)
+	}
+}
+
 func TestFileSystemRepo_Save(t *testing.T) {
 	// Helper function to parse URLs in test cases
 	parseURL := func(rawURL string) *url.URL {
@@ -41,14 +67,15 @@ func TestFileSystemRepo_Save(t *testing.T) {
 				pathKey := entity.NewPathKey(enterprise.Url)
 				fileName := filesystem.NewFileName(enterpriseKey.String())
 
+				expectedData := schema.NewEnterpriseDocument(reader.NewEnterprisesData(pathKey, enterprise))
 				mockDriver.EXPECT().
-					Get(gomock.Any(), fileName).
-					Return(nil, filesystem.ErrFileNotFound)
-
-				expectedData := reader.NewEnterprisesData(pathKey, enterprise)
-				mockDriver.EXPECT().
-					Save(gomock.Any(), fileName, expectedData).
-					Return(nil)
+					Update(gomock.Any(), fileName, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ filesystem.FileName, fn func(filesystem.DecodeFunc) (any, error)) error {
+						data, err := fn(nil)
+						assert.NoError(t, err)
+						assert.Equal(t, expectedData, data)
+						return nil
+					})
 
 				return mockDriver, enterprise
 			},
@@ -65,22 +92,23 @@ func TestFileSystemRepo_Save(t *testing.T) {
 				pathKey := entity.NewPathKey(enterprise.Url)
 				fileName := filesystem.NewFileName(enterpriseKey.String())
 
+				// a file written before the schema was versioned
 				existingData := map[string]any{string(pathKey): enterprise}
 
 				mockDriver.EXPECT().
-					Get(gomock.Any(), fileName).
-					Return(existingData, nil)
+					Update(gomock.Any(), fileName, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ filesystem.FileName, fn func(filesystem.DecodeFunc) (any, error)) error {
+						data, err := fn(decoderOf(existingData))
+						assert.NoError(t, err)
 
-				mockDriver.EXPECT().
-					Save(gomock.Any(), fileName, gomock.Any()).
-					DoAndReturn(func(_ context.Context, _ filesystem.FileName, data any) error {
 						// Verify the data being saved has the enterprise
-						savedData, ok := data.(reader.EnterpriseData)
-						assert.True(t, ok, "data should be EnterpriseData")
+						savedData, ok := data.(schema.EnterpriseDocument)
+						assert.True(t, ok, "data should be an EnterpriseDocument")
+						assert.Equal(t, schema.CurrentVersion, savedData.Version)
 
-						savedEnterprise, exists := savedData[pathKey]
+						savedRule, exists := savedData.Rules[string(pathKey)]
 						assert.True(t, exists, "enterprise should exist in data")
-						assert.Equal(t, enterprise.Url.String(), savedEnterprise.Url.String())
+						assert.Equal(t, enterprise.Url.String(), savedRule.Url)
 
 						return nil
 					})
@@ -101,12 +129,12 @@ func TestFileSystemRepo_Save(t *testing.T) {
 				expectedErr := errors.New("driver error")
 
 				mockDriver.EXPECT().
-					Get(gomock.Any(), fileName).
-					Return(nil, expectedErr)
+					Update(gomock.Any(), fileName, gomock.Any()).
+					Return(expectedErr)
 
 				return mockDriver, enterprise
 			},
-			expectedError: errors.New("driver error"),
+			expectedError: errors.New("failed to save enterprise file: driver error"),
 		},
 	}
 
@@ -135,6 +163,66 @@ func TestFileSystemRepo_Save(t *testing.T) {
 	}
 }
 
+func TestFileSystemRepo_SaveConcurrent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("rewrites the file of the host once per registration")
+	}
+
+	repo := NewFileSystemRepo(filesystem.NewFileSystem(filesystem.WithDir(t.TempDir())))
+	ctx := context.Background()
+
+	const registrations = 1000
+
+	var wg sync.WaitGroup
+	errs := make(chan error, registrations)
+	for i := range registrations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			endpoint, _ := url.Parse(fmt.Sprintf("https://concurrent.com/page/%d", i))
+			errs <- repo.Save(ctx, entity.Enterprise{Url: endpoint, Path: endpoint.Path})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	data, err := repo.Get(ctx, entity.EnterpriseKey("concurrent.com"))
+	assert.NoError(t, err)
+	// no registration was overwritten by a concurrent one
+	assert.Len(t, data, registrations)
+}
+
+func TestFileSystemRepo_UnusualHosts(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewFileSystemRepo(filesystem.NewFileSystem(filesystem.WithDir(dir)))
+	ctx := context.Background()
+
+	// a dot-only host is stored inside the directory and read back
+	endpoint, _ := url.Parse("https://../home")
+	assert.NoError(t, repo.Save(ctx, entity.Enterprise{Url: endpoint, Path: endpoint.Path}))
+
+	data, err := repo.Get(ctx, entity.EnterpriseKey(".."))
+	assert.NoError(t, err)
+	assert.Len(t, data, 1)
+
+	keys, err := repo.ListEnterprises(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.EnterpriseKey{".."}, keys)
+
+	// a host too long for a file name is rejected
+	endpoint, _ = url.Parse("https://" + strings.Repeat("a", 250) + ".com/home")
+	err = repo.Save(ctx, entity.Enterprise{Url: endpoint, Path: endpoint.Path})
+	assert.ErrorIs(t, err, writer.ErrInvalidEndpoint)
+
+	_, err = repo.Get(ctx, entity.NewEnterpriseKey(endpoint))
+	assert.ErrorIs(t, err, reader.ErrContentNotFound)
+}
+
 func TestFileSystemRepo_Get(t *testing.T) {
 	// Helper function to parse URLs in test cases
 	parseURL := func(rawURL string) *url.URL {
@@ -168,12 +256,18 @@ func TestFileSystemRepo_Get(t *testing.T) {
 				expectedData := map[string]any{string(pathKey): enterprise}
 
 				mockDriver.EXPECT().
-					Get(gomock.Any(), fileName).
-					Return(expectedData, nil)
+					Get(gomock.Any(), fileName, gomock.Any()).
+					DoAndReturn(storedAs(expectedData))
 
 				return mockDriver, enterpriseKey
 			},
-			expectedData:  reader.NewEnterprisesData(entity.NewPathKey(parseURL("https://example.com/video")), entity.Enterprise{Url: parseURL("https://example.com/video")}),
+			// the fields derived from the url are filled in when read
+			expectedData: reader.NewEnterprisesData(entity.NewPathKey(parseURL("https://example.com/video")), entity.Enterprise{
+				Url:    parseURL("https://example.com/video"),
+				Origin: "https://example.com",
+				Paths:  []string{"video"},
+				Path:   "/video",
+			}),
 			expectedError: nil,
 		},
 		{
@@ -185,8 +279,8 @@ func TestFileSystemRepo_Get(t *testing.T) {
 				fileName := filesystem.NewFileName(enterpriseKey.String())
 
 				mockDriver.EXPECT().
-					Get(gomock.Any(), fileName).
-					Return(nil, filesystem.ErrFileNotFound)
+					Get(gomock.Any(), fileName, gomock.Any()).
+					Return(filesystem.ErrFileNotFound)
 
 				return mockDriver, enterpriseKey
 			},
@@ -194,6 +288,7 @@ func TestFileSystemRepo_Get(t *testing.T) {
 			expectedError: filesystem.ErrFileNotFound,
 			errorValidator: func(t *testing.T, err error) {
 				assert.ErrorIs(t, err, filesystem.ErrFileNotFound)
+				assert.ErrorIs(t, err, reader.ErrContentNotFound)
 			},
 		},
 		{
@@ -204,19 +299,20 @@ func TestFileSystemRepo_Get(t *testing.T) {
 				enterpriseKey := entity.NewEnterpriseKey(url)
 				fileName := filesystem.NewFileName(enterpriseKey.String())
 
-				// Return string instead of EnterpriseData to trigger type assertion failure
+				// a string instead of EnterpriseData fails to decode
 				invalidData := "not an enterprise data"
 
 				mockDriver.EXPECT().
-					Get(gomock.Any(), fileName).
-					Return(invalidData, nil)
+					Get(gomock.Any(), fileName, gomock.Any()).
+					DoAndReturn(storedAs(invalidData))
 
 				return mockDriver, enterpriseKey
 			},
 			expectedData:  reader.EnterpriseData{},
-			expectedError: writer.ErrInvalidDataType,
+			expectedError: errors.New("decode error"),
 			errorValidator: func(t *testing.T, err error) {
-				assert.ErrorIs(t, err, writer.ErrInvalidDataType)
+				var typeErr *json.UnmarshalTypeError
+				assert.ErrorAs(t, err, &typeErr)
 			},
 		},
 	}
@@ -247,3 +343,96 @@ func TestFileSystemRepo_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestFileSystemRepo_Get_LegacyFile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// files written before the video metadata existed must keep working
+	b, err := os.ReadFile("testdata/legacy_enterprise.json")
+	assert.NoError(t, err)
+
+	var stored map[string]any
+	assert.NoError(t, json.Unmarshal(b, &stored))
+
+	mockDriver := filesystem.NewMockDriver(ctrl)
+	mockDriver.EXPECT().
+		Get(gomock.Any(), filesystem.NewFileName("example.com"), gomock.Any()).
+		DoAndReturn(storedAs(stored))
+
+	repo := NewFileSystemRepo(mockDriver)
+	data, err := repo.Get(context.Background(), entity.EnterpriseKey("example.com"))
+
+	assert.NoError(t, err)
+	rule, found := data.GetRule(entity.PathKey("/home/camisa/masculino"))
+	assert.True(t, found)
+	assert.Equal(t, entity.Video{VideoUrl: "https://video2.com.br", TambnailUrl: "https://thumbnail2.com.br"}, rule.Video)
+	assert.Nil(t, rule.Video.Metadata)
+}
+
+func TestFileSystemRepo_MigrateEnterprises(t *testing.T) {
+	dir := t.TempDir()
+	b, err := os.ReadFile("testdata/legacy_enterprise.json")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(dir+"/example.com.json", b, 0644))
+
+	repo := NewFileSystemRepo(filesystem.NewFileSystem(filesystem.WithDir(dir)))
+	ctx := context.Background()
+
+	before, err := repo.Get(ctx, entity.EnterpriseKey("example.com"))
+	assert.NoError(t, err)
+
+	migrated, err := repo.MigrateEnterprises(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, migrated)
+
+	b, err = os.ReadFile(dir + "/example.com.json")
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), fmt.Sprintf(`"Version":%d`, schema.CurrentVersion))
+
+	after, err := repo.Get(ctx, entity.EnterpriseKey("example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	// files already at the current version are left alone
+	migrated, err = repo.MigrateEnterprises(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+}
+
+func TestFileSystemRepo_Get_WithMetadata(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	endpoint, _ := url.Parse("https://example.com/home")
+	uploadDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	enterprise := entity.Enterprise{
+		Url: endpoint,
+		Video: entity.Video{
+			VideoUrl:    "https://cdn.com/video.mp4",
+			TambnailUrl: "https://cdn.com/thumb.jpg",
+			Metadata: &entity.VideoMetadata{
+				Title:           "Summer collection",
+				DurationSeconds: 31.5,
+				AspectRatio:     "16:9",
+				PosterAlt:       "Model wearing a blue shirt",
+				UploadDate:      &uploadDate,
+				Captions: []entity.Caption{
+					{Language: "pt-BR", Kind: entity.CaptionKindSubtitles, Url: "https://cdn.com/pt.vtt"},
+				},
+				Attributes: map[string]string{"campaign": "summer"},
+			},
+		},
+	}
+
+	mockDriver := filesystem.NewMockDriver(ctrl)
+	mockDriver.EXPECT().
+		Get(gomock.Any(), filesystem.NewFileName("example.com"), gomock.Any()).
+		DoAndReturn(storedAs(reader.NewEnterprisesData(entity.NewPathKey(endpoint), enterprise)))
+
+	repo := NewFileSystemRepo(mockDriver)
+	data, err := repo.Get(context.Background(), entity.NewEnterpriseKey(endpoint))
+
+	assert.NoError(t, err)
+	assert.Equal(t, enterprise.Video, data[entity.NewPathKey(endpoint)].Video)
+}
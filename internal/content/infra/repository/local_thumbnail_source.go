@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/internal/content/writer"
+)
+
+// LocalThumbnailSource opens thumbnails uploaded as assets and thumbnails
+// whose url starts with one of the configured prefixes, read from the
+// directory mapped to it (e.g. a mount of the CDN origin).
+type LocalThumbnailSource struct {
+	assets        reader.AssetRepository
+	publicBaseUrl string
+	roots         []localRoot
+}
+
+type localRoot struct {
+	prefix string
+	dir    string
+}
+
+var _ writer.ThumbnailSource = (*LocalThumbnailSource)(nil)
+
+// NewLocalThumbnailSource maps url prefixes to directories. The longest
+// matching prefix wins. Assets are the urls under publicBaseUrl.
+func NewLocalThumbnailSource(assets reader.AssetRepository, publicBaseUrl string, roots map[string]string) *LocalThumbnailSource {
+	s := &LocalThumbnailSource{assets: assets, publicBaseUrl: publicBaseUrl}
+	for prefix, dir := range roots {
+		s.roots = append(s.roots, localRoot{prefix: prefix, dir: dir})
+	}
+
+	sort.Slice(s.roots, func(i, j int) bool {
+		return len(s.roots[i].prefix) > len(s.roots[j].prefix)
+	})
+
+	return s
+}
+
+func (s LocalThumbnailSource) OpenThumbnail(ctx context.Context, rawUrl string) (io.ReadCloser, bool, error) {
+	if id, ok := entity.AssetIdFromUrl(rawUrl, s.publicBaseUrl); ok {
+		_, file, err := s.assets.GetAsset(ctx, id)
+		if errors.Is(err, reader.ErrAssetNotFound) {
+			return nil, false, nil
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return file, true, nil
+	}
+
+	for _, root := range s.roots {
+		rest, ok := strings.CutPrefix(rawUrl, root.prefix)
+		if !ok {
+			continue
+		}
+
+		name, ok := localName(rest)
+		if !ok {
+			return nil, false, nil
+		}
+
+		file, err := os.Open(filepath.Join(root.dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		return file, true, nil
+	}
+
+	return nil, false, nil
+}
+
+// localName converts the rest of the url after the prefix to a path
+// relative to the root, refusing anything that would escape it.
+func localName(rest string) (string, bool) {
+	rest, _, _ = strings.Cut(rest, "?")
+	rest, _, _ = strings.Cut(rest, "#")
+
+	name, err := url.PathUnescape(rest)
+	if err != nil {
+		return "", false
+	}
+
+	name = filepath.FromSlash(strings.TrimPrefix(name, "/"))
+	if !filepath.IsLocal(name) {
+		return "", false
+	}
+
+	return name, true
+}
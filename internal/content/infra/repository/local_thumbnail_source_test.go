@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLocalThumbnailSource_OpenThumbnail(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "img"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "img", "a b.jpg"), []byte("cdn"), 0o644))
+
+	outside := filepath.Join(filepath.Dir(root), "secret.jpg")
+	require.NoError(t, os.WriteFile(outside, []byte("secret"), 0o644))
+	t.Cleanup(func() { os.Remove(outside) })
+
+	assetId := strings.Repeat("a", 64)
+
+	tests := []struct {
+		name      string
+		url       string
+		setupMock func(mockAssets *reader.MockAssetRepository)
+		want      string
+		wantOk    bool
+	}{
+		{
+			name: "uploaded asset",
+			url:  "https://content.com/assets/" + assetId,
+			setupMock: func(mockAssets *reader.MockAssetRepository) {
+				mockAssets.EXPECT().
+					GetAsset(gomock.Any(), assetId).
+					Return(entity.Asset{Id: assetId}, nopSeekCloser{strings.NewReader("asset")}, nil)
+			},
+			want:   "asset",
+			wantOk: true,
+		},
+		{
+			name: "missing asset",
+			url:  "https://content.com/assets/" + assetId,
+			setupMock: func(mockAssets *reader.MockAssetRepository) {
+				mockAssets.EXPECT().
+					GetAsset(gomock.Any(), assetId).
+					Return(entity.Asset{}, nil, reader.ErrAssetNotFound)
+			},
+		},
+		{
+			name: "asset path on a foreign host",
+			url:  "https://evil.com/assets/" + assetId,
+		},
+		{
+			name:   "file under a mapped prefix",
+			url:    "https://cdn.com/img/a%20b.jpg?v=2",
+			want:   "cdn",
+			wantOk: true,
+		},
+		{
+			name: "missing file under a mapped prefix",
+			url:  "https://cdn.com/img/missing.jpg",
+		},
+		{
+			name: "escaping the root",
+			url:  "https://cdn.com/../secret.jpg",
+		},
+		{
+			name: "escaping the root with an encoded path",
+			url:  "https://cdn.com/%2e%2e%2fsecret.jpg",
+		},
+		{
+			name: "unmapped url",
+			url:  "https://other.com/img/a%20b.jpg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAssets := reader.NewMockAssetRepository(ctrl)
+			if tt.setupMock != nil {
+				tt.setupMock(mockAssets)
+			}
+
+			source := NewLocalThumbnailSource(mockAssets, "https://content.com", map[string]string{"https://cdn.com/": root})
+
+			rc, ok, err := source.OpenThumbnail(context.Background(), tt.url)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOk, ok)
+			if !ok {
+				return
+			}
+			defer rc.Close()
+
+			body, _ := io.ReadAll(rc)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error { return nil }
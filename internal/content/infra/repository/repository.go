@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/IsaacDSC/search_content/internal/content/health"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
 	"github.com/IsaacDSC/search_content/internal/content/writer"
 )
@@ -8,4 +9,21 @@ import (
 type Repository interface {
 	reader.Repository
 	writer.Repository
+	health.Repository
+}
+
+type CaptionRepository interface {
+	reader.CaptionRepository
+	writer.CaptionRepository
+}
+
+type AssetRepository interface {
+	reader.AssetRepository
+	writer.AssetRepository
+}
+
+type CatalogRepository interface {
+	reader.CatalogRepository
+	writer.CatalogRepository
+	health.CatalogRepository
 }
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/internal/content/writer"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepository runs the behavior every Repository shares against a
+// new, empty, repository per test.
+func testRepository(t *testing.T, newRepo func(t *testing.T) Repository) {
+	ctx := context.Background()
+
+	rule := func(rawURL string, video entity.Video) entity.Enterprise {
+		endpoint, _ := url.Parse(rawURL)
+		return entity.Enterprise{Url: endpoint, Video: video}
+	}
+
+	// the fields derived from the url are filled in when read
+	read := func(enterprise entity.Enterprise) entity.Enterprise {
+		enterprise.Origin = enterprise.Url.Scheme + "://" + enterprise.Url.Host
+		enterprise.Path = enterprise.Url.Path
+		enterprise.Paths = entity.NewPathKey(enterprise.Url).ToListPaths()
+		return enterprise
+	}
+
+	t.Run("save and get", func(t *testing.T) {
+		repo := newRepo(t)
+		home := rule("https://shop.com/home", entity.Video{VideoUrl: "https://cdn.com/home.mp4", TambnailUrl: "https://cdn.com/home.jpg"})
+		promo := rule("https://shop.com/promo/*", entity.Video{VideoUrl: "https://cdn.com/promo.mp4", TambnailUrl: "https://cdn.com/promo.jpg"})
+		other := rule("https://other.com/home", entity.Video{VideoUrl: "https://cdn.com/other.mp4"})
+
+		fallback := entity.Video{VideoUrl: "https://cdn.com/fallback.mp4"}
+		promo.Experiment = &entity.Experiment{Id: "promo", Variants: []entity.Variant{
+			{Id: "a", Weight: 70, Video: entity.Video{VideoUrl: "https://cdn.com/a.mp4"}},
+			{Id: "b", Weight: 30, Video: entity.Video{VideoUrl: "https://cdn.com/b.mp4"}},
+		}}
+		promo.Regions = map[entity.RegionCode]entity.Video{"BR": {VideoUrl: "https://cdn.com/br.mp4"}}
+		promo.Playlist = []entity.Video{{VideoUrl: "https://cdn.com/1.mp4"}, {VideoUrl: "https://cdn.com/2.mp4"}}
+		promo.Fallback = &fallback
+
+		require.NoError(t, repo.Save(ctx, home))
+		require.NoError(t, repo.Save(ctx, promo))
+		require.NoError(t, repo.Save(ctx, other))
+
+		data, err := repo.Get(ctx, "shop.com")
+		require.NoError(t, err)
+		assert.Equal(t, reader.EnterpriseData{
+			"/home":    read(home),
+			"/promo/*": read(promo),
+		}, data)
+	})
+
+	t.Run("save replaces the rule of the path", func(t *testing.T) {
+		repo := newRepo(t)
+		previous := rule("https://shop.com/home", entity.Video{VideoUrl: "https://cdn.com/old.mp4"})
+		previous.Experiment = &entity.Experiment{Id: "old", Variants: []entity.Variant{{Id: "a", Weight: 1}, {Id: "b", Weight: 1}}}
+		require.NoError(t, repo.Save(ctx, previous))
+
+		latest := rule("https://shop.com/home", entity.Video{VideoUrl: "https://cdn.com/new.mp4"})
+		latest.Experiment = &entity.Experiment{Id: "new", Variants: []entity.Variant{{Id: "c", Weight: 1}}}
+		require.NoError(t, repo.Save(ctx, latest))
+
+		data, err := repo.Get(ctx, "shop.com")
+		require.NoError(t, err)
+		assert.Equal(t, reader.EnterpriseData{"/home": read(latest)}, data)
+	})
+
+	t.Run("get unknown host", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Save(ctx, rule("https://shop.com/home", entity.Video{})))
+
+		// a host that is a prefix of a stored one
+		_, err := repo.Get(ctx, "shop.co")
+		assert.ErrorIs(t, err, reader.ErrContentNotFound)
+	})
+
+	t.Run("save without host", func(t *testing.T) {
+		repo := newRepo(t)
+
+		err := repo.Save(ctx, rule("/home", entity.Video{}))
+		assert.ErrorIs(t, err, writer.ErrInvalidEndpoint)
+	})
+
+	t.Run("list enterprises", func(t *testing.T) {
+		repo := newRepo(t)
+
+		keys, err := repo.ListEnterprises(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+
+		for _, rawURL := range []string{"https://shop.com/b", "https://a.com/home", "https://shop.com/a", "https://shop.com.br/home", "https://../home"} {
+			require.NoError(t, repo.Save(ctx, rule(rawURL, entity.Video{})))
+		}
+
+		keys, err = repo.ListEnterprises(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []entity.EnterpriseKey{"..", "a.com", "shop.com", "shop.com.br"}, keys)
+	})
+
+	t.Run("find video references", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Save(ctx, rule("https://shop.com/b", entity.Video{CatalogId: "summer"})))
+		require.NoError(t, repo.Save(ctx, rule("https://shop.com/a", entity.Video{CatalogId: "summer"})))
+		require.NoError(t, repo.Save(ctx, rule("https://other.com/home", entity.Video{CatalogId: "winter"})))
+
+		endpoints, err := repo.FindVideoReferences(ctx, "summer")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"https://shop.com/a", "https://shop.com/b"}, endpoints)
+
+		endpoints, err = repo.FindVideoReferences(ctx, "spring")
+		require.NoError(t, err)
+		assert.Empty(t, endpoints)
+	})
+
+	t.Run("concurrent saves", func(t *testing.T) {
+		repo := newRepo(t)
+
+		const registrations = 100
+
+		var wg sync.WaitGroup
+		for i := range registrations {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, repo.Save(ctx, rule(fmt.Sprintf("https://concurrent.com/page/%d", i), entity.Video{})))
+			}()
+		}
+		wg.Wait()
+
+		data, err := repo.Get(ctx, "concurrent.com")
+		require.NoError(t, err)
+		// no registration was overwritten by a concurrent one
+		assert.Len(t, data, registrations)
+	})
+}
+
+func TestFileSystemRepo_Suite(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewFileSystemRepo(filesystem.NewFileSystem(filesystem.WithDir(t.TempDir())))
+	})
+}
+
+func TestBoltRepo_Suite(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		repo, err := NewBoltRepo(filepath.Join(t.TempDir(), "rules.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
+
+func TestSQLiteRepo_Suite(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		repo, err := NewSQLiteRepo(filepath.Join(t.TempDir(), "rules.sqlite"))
+		require.NoError(t, err)
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported schema version")
+
+// Migration upgrades a document from the version it's registered at to
+// the next one. It works on the generic form of the document, as the
+// types of the older versions are not kept.
+type Migration func(doc map[string]any) (map[string]any, error)
+
+// migrations holds the Migration from each version to the next one, up to
+// CurrentVersion.
+var migrations = map[int]Migration{
+	1: migrateV1,
+}
+
+// Migrate upgrades a document of the given version to CurrentVersion.
+func Migrate(doc map[string]any, version int) (map[string]any, error) {
+	if version < 1 || version > CurrentVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	for ; version < CurrentVersion; version++ {
+		migrate, ok := migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no migration from %d", ErrUnsupportedVersion, version)
+		}
+
+		var err error
+		if doc, err = migrate(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate from %d: %w", version, err)
+		}
+	}
+
+	return doc, nil
+}
+
+// migrateV1 moves the rules, stored by path at the root of the document,
+// under Rules. The url, stored as a url.URL, becomes a string, and the
+// fields derived from it are dropped.
+func migrateV1(doc map[string]any) (map[string]any, error) {
+	rules := make(map[string]any, len(doc))
+	for path, value := range doc {
+		rule, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("rule %s is not an object", path)
+		}
+
+		endpoint, err := urlV1(rule["Url"])
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", path, err)
+		}
+
+		rule["Url"] = endpoint
+		delete(rule, "Origin")
+		delete(rule, "Paths")
+		delete(rule, "Path")
+
+		rules[path] = rule
+	}
+
+	return map[string]any{"Version": 2, "Rules": rules}, nil
+}
+
+func urlV1(value any) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	var endpoint url.URL
+	if err := json.Unmarshal(b, &endpoint); err != nil {
+		return "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	return endpoint.String(), nil
+}
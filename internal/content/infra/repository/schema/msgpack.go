@@ -0,0 +1,66 @@
+package schema
+
+import (
+	"encoding/json"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// DecodeMsgpack decodes a document of any version, migrating it to
+// CurrentVersion as UnmarshalJSON does. Documents at CurrentVersion are
+// decoded directly into their types.
+func (d *EnterpriseDocument) DecodeMsgpack(dec *msgpack.Decoder) error {
+	// the alias has no DecodeMsgpack, which would recurse
+	type document EnterpriseDocument
+
+	raw, err := dec.DecodeRaw()
+	if err != nil {
+		return err
+	}
+
+	var probe struct{ Version int }
+	if err := msgpack.Unmarshal(raw, &probe); err != nil {
+		return err
+	}
+
+	if version := max(probe.Version, 1); version == CurrentVersion {
+		if err := msgpack.Unmarshal(raw, (*document)(d)); err != nil {
+			return err
+		}
+		d.fromVersion = version
+
+		return nil
+	}
+
+	// older documents were written from their JSON form, the one the
+	// migrations work on
+	var generic map[string]any
+	if err := msgpack.Unmarshal(raw, &generic); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(generic)
+	if err != nil {
+		return err
+	}
+
+	return d.UnmarshalJSON(b)
+}
+
+// DecodeMsgpack reads the upload date in UTC, as protobuf does: a
+// MessagePack timestamp has no time zone, and is otherwise read in the
+// local one.
+func (m *Metadata) DecodeMsgpack(dec *msgpack.Decoder) error {
+	type metadata Metadata
+
+	if err := dec.Decode((*metadata)(m)); err != nil {
+		return err
+	}
+
+	if m.UploadDate != nil {
+		uploadDate := m.UploadDate.UTC()
+		m.UploadDate = &uploadDate
+	}
+
+	return nil
+}
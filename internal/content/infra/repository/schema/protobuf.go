@@ -0,0 +1,243 @@
+package schema
+
+import (
+	"fmt"
+
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema/schemapb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MarshalProto encodes the document as a schemapb.EnterpriseDocument.
+func (d EnterpriseDocument) MarshalProto() ([]byte, error) {
+	pb := &schemapb.EnterpriseDocument{
+		Version: int32(d.Version),
+		Rules:   make(map[string]*schemapb.Rule, len(d.Rules)),
+	}
+	for path, rule := range d.Rules {
+		pb.Rules[path] = rule.toProto()
+	}
+
+	return proto.Marshal(pb)
+}
+
+// UnmarshalProto decodes a schemapb.EnterpriseDocument. Documents are
+// written in protobuf since version 2, so there is no older version to
+// migrate yet: the next version adds its migration here.
+func (d *EnterpriseDocument) UnmarshalProto(b []byte) error {
+	var pb schemapb.EnterpriseDocument
+	if err := proto.Unmarshal(b, &pb); err != nil {
+		return err
+	}
+
+	if version := int(pb.Version); version != CurrentVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+
+	*d = EnterpriseDocument{Version: CurrentVersion, fromVersion: CurrentVersion}
+	if pb.Rules != nil {
+		d.Rules = make(map[string]Rule, len(pb.Rules))
+		for path, rule := range pb.Rules {
+			d.Rules[path] = ruleFromProto(rule)
+		}
+	}
+
+	return nil
+}
+
+func (r Rule) toProto() *schemapb.Rule {
+	rule := &schemapb.Rule{
+		Url:   r.Url,
+		Video: r.Video.toProto(),
+	}
+
+	if r.Experiment != nil {
+		rule.Experiment = &schemapb.Experiment{Id: r.Experiment.Id}
+		for _, variant := range r.Experiment.Variants {
+			rule.Experiment.Variants = append(rule.Experiment.Variants, &schemapb.Variant{
+				Id:     variant.Id,
+				Weight: int64(variant.Weight),
+				Video:  variant.Video.toProto(),
+			})
+		}
+	}
+
+	if r.Regions != nil {
+		rule.Regions = make(map[string]*schemapb.Video, len(r.Regions))
+		for region, video := range r.Regions {
+			rule.Regions[region] = video.toProto()
+		}
+	}
+
+	for _, video := range r.Playlist {
+		rule.Playlist = append(rule.Playlist, video.toProto())
+	}
+
+	if r.Fallback != nil {
+		rule.Fallback = r.Fallback.toProto()
+	}
+
+	return rule
+}
+
+func ruleFromProto(pb *schemapb.Rule) Rule {
+	rule := Rule{
+		Url:   pb.GetUrl(),
+		Video: videoFromProto(pb.GetVideo()),
+	}
+
+	if e := pb.GetExperiment(); e != nil {
+		rule.Experiment = &Experiment{Id: e.Id}
+		for _, variant := range e.Variants {
+			rule.Experiment.Variants = append(rule.Experiment.Variants, Variant{
+				Id:     variant.GetId(),
+				Weight: int(variant.GetWeight()),
+				Video:  videoFromProto(variant.GetVideo()),
+			})
+		}
+	}
+
+	if len(pb.Regions) > 0 {
+		rule.Regions = make(map[string]Video, len(pb.Regions))
+		for region, video := range pb.Regions {
+			rule.Regions[region] = videoFromProto(video)
+		}
+	}
+
+	for _, video := range pb.Playlist {
+		rule.Playlist = append(rule.Playlist, videoFromProto(video))
+	}
+
+	if pb.Fallback != nil {
+		fallback := videoFromProto(pb.Fallback)
+		rule.Fallback = &fallback
+	}
+
+	return rule
+}
+
+func (v Video) toProto() *schemapb.Video {
+	video := &schemapb.Video{
+		VideoUrl:     v.VideoUrl,
+		ThumbnailUrl: v.ThumbnailUrl,
+		CatalogId:    v.CatalogId,
+	}
+
+	if m := v.Metadata; m != nil {
+		video.Metadata = &schemapb.Metadata{
+			Title:           m.Title,
+			Description:     m.Description,
+			DurationSeconds: m.DurationSeconds,
+			AspectRatio:     m.AspectRatio,
+			PosterAlt:       m.PosterAlt,
+			Attributes:      m.Attributes,
+		}
+
+		if m.UploadDate != nil {
+			video.Metadata.UploadDate = timestamppb.New(*m.UploadDate)
+		}
+
+		for _, c := range m.Captions {
+			video.Metadata.Captions = append(video.Metadata.Captions, &schemapb.Caption{
+				Language: c.Language,
+				Kind:     c.Kind,
+				Label:    c.Label,
+				Url:      c.Url,
+				TrackId:  c.TrackId,
+			})
+		}
+
+		if p := m.Preview; p != nil {
+			video.Metadata.Preview = &schemapb.Preview{
+				Url:             p.Url,
+				SpriteUrl:       p.SpriteUrl,
+				IntervalSeconds: p.IntervalSeconds,
+				TileWidth:       int64(p.TileWidth),
+			}
+		}
+	}
+
+	for _, r := range v.Renditions {
+		video.Renditions = append(video.Renditions, &schemapb.Rendition{
+			Url:         r.Url,
+			PlaylistUrl: r.PlaylistUrl,
+			Bandwidth:   int64(r.Bandwidth),
+			Width:       int64(r.Width),
+			Height:      int64(r.Height),
+			Codecs:      r.Codecs,
+			FrameRate:   r.FrameRate,
+			MimeType:    r.MimeType,
+		})
+	}
+
+	if p := v.Placeholder; p != nil {
+		video.Placeholder = &schemapb.Placeholder{BlurHash: p.BlurHash, DominantColor: p.DominantColor}
+	}
+
+	return video
+}
+
+func videoFromProto(pb *schemapb.Video) Video {
+	video := Video{
+		VideoUrl:     pb.GetVideoUrl(),
+		ThumbnailUrl: pb.GetThumbnailUrl(),
+		CatalogId:    pb.GetCatalogId(),
+	}
+
+	if m := pb.GetMetadata(); m != nil {
+		video.Metadata = &Metadata{
+			Title:           m.Title,
+			Description:     m.Description,
+			DurationSeconds: m.DurationSeconds,
+			AspectRatio:     m.AspectRatio,
+			PosterAlt:       m.PosterAlt,
+		}
+
+		if len(m.Attributes) > 0 {
+			video.Metadata.Attributes = m.Attributes
+		}
+
+		if m.UploadDate != nil {
+			uploadDate := m.UploadDate.AsTime()
+			video.Metadata.UploadDate = &uploadDate
+		}
+
+		for _, c := range m.Captions {
+			video.Metadata.Captions = append(video.Metadata.Captions, Caption{
+				Language: c.GetLanguage(),
+				Kind:     c.GetKind(),
+				Label:    c.GetLabel(),
+				Url:      c.GetUrl(),
+				TrackId:  c.GetTrackId(),
+			})
+		}
+
+		if p := m.Preview; p != nil {
+			video.Metadata.Preview = &Preview{
+				Url:             p.Url,
+				SpriteUrl:       p.SpriteUrl,
+				IntervalSeconds: p.IntervalSeconds,
+				TileWidth:       int(p.TileWidth),
+			}
+		}
+	}
+
+	for _, r := range pb.GetRenditions() {
+		video.Renditions = append(video.Renditions, Rendition{
+			Url:         r.GetUrl(),
+			PlaylistUrl: r.GetPlaylistUrl(),
+			Bandwidth:   int(r.GetBandwidth()),
+			Width:       int(r.GetWidth()),
+			Height:      int(r.GetHeight()),
+			Codecs:      r.GetCodecs(),
+			FrameRate:   r.GetFrameRate(),
+			MimeType:    r.GetMimeType(),
+		})
+	}
+
+	if p := pb.GetPlaceholder(); p != nil {
+		video.Placeholder = &Placeholder{BlurHash: p.BlurHash, DominantColor: p.DominantColor}
+	}
+
+	return video
+}
@@ -0,0 +1,381 @@
+// Package schema defines how the rules of an enterprise are persisted,
+// separately from the domain entities, so that a change to an entity
+// doesn't change the files already written. Each document records its
+// version, and older documents are migrated when read.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+)
+
+// CurrentVersion is the version of the documents written.
+//
+//	1: reader.EnterpriseData as is, without a version
+//	2: Version and Rules by path, the url of each rule as a string
+const CurrentVersion = 2
+
+// EnterpriseDocument holds the rules of an enterprise. The json and
+// msgpack names of the fields are part of the schema and never change
+// within a version: they keep the names of version 1, typos included. The
+// protobuf encoding is the message of the same name in package schemapb.
+type EnterpriseDocument struct {
+	Version int
+	Rules   map[string]Rule
+
+	fromVersion int // version of the document read, before its migrations
+}
+
+type Rule struct {
+	Url        string
+	Video      Video
+	Experiment *Experiment      `json:",omitempty" msgpack:",omitempty"`
+	Regions    map[string]Video `json:",omitempty" msgpack:",omitempty"`
+	Playlist   []Video          `json:",omitempty" msgpack:",omitempty"`
+	Fallback   *Video           `json:",omitempty" msgpack:",omitempty"`
+}
+
+type Video struct {
+	VideoUrl     string
+	ThumbnailUrl string       `json:"TambnailUrl" msgpack:"TambnailUrl"`
+	Metadata     *Metadata    `json:",omitempty" msgpack:",omitempty"`
+	Renditions   []Rendition  `json:",omitempty" msgpack:",omitempty"`
+	Placeholder  *Placeholder `json:",omitempty" msgpack:",omitempty"`
+	CatalogId    string       `json:",omitempty" msgpack:",omitempty"`
+}
+
+type Metadata struct {
+	Title           string            `json:",omitempty" msgpack:",omitempty"`
+	Description     string            `json:",omitempty" msgpack:",omitempty"`
+	DurationSeconds float64           `json:",omitempty" msgpack:",omitempty"`
+	AspectRatio     string            `json:",omitempty" msgpack:",omitempty"`
+	PosterAlt       string            `json:",omitempty" msgpack:",omitempty"`
+	UploadDate      *time.Time        `json:",omitempty" msgpack:",omitempty"`
+	Captions        []Caption         `json:",omitempty" msgpack:",omitempty"`
+	Preview         *Preview          `json:",omitempty" msgpack:",omitempty"`
+	Attributes      map[string]string `json:",omitempty" msgpack:",omitempty"`
+}
+
+type Caption struct {
+	Language string
+	Kind     string
+	Label    string `json:",omitempty" msgpack:",omitempty"`
+	Url      string
+	TrackId  string `json:",omitempty" msgpack:",omitempty"`
+}
+
+type Preview struct {
+	Url             string
+	SpriteUrl       string  `json:",omitempty" msgpack:",omitempty"`
+	IntervalSeconds float64 `json:",omitempty" msgpack:",omitempty"`
+	TileWidth       int     `json:",omitempty" msgpack:",omitempty"`
+}
+
+type Rendition struct {
+	Url         string `json:",omitempty" msgpack:",omitempty"`
+	PlaylistUrl string `json:",omitempty" msgpack:",omitempty"`
+	Bandwidth   int
+	Width       int     `json:",omitempty" msgpack:",omitempty"`
+	Height      int     `json:",omitempty" msgpack:",omitempty"`
+	Codecs      string  `json:",omitempty" msgpack:",omitempty"`
+	FrameRate   float64 `json:",omitempty" msgpack:",omitempty"`
+	MimeType    string  `json:",omitempty" msgpack:",omitempty"`
+}
+
+type Placeholder struct {
+	BlurHash      string
+	DominantColor string
+}
+
+type Experiment struct {
+	Id       string
+	Variants []Variant
+}
+
+type Variant struct {
+	Id     string
+	Weight int
+	Video  Video
+}
+
+// NewEnterpriseDocument returns the document of the rules, at CurrentVersion.
+func NewEnterpriseDocument(data reader.EnterpriseData) EnterpriseDocument {
+	doc := EnterpriseDocument{Version: CurrentVersion, Rules: make(map[string]Rule, len(data))}
+	for pathKey, enterprise := range data {
+		doc.Rules[string(pathKey)] = NewRule(enterprise)
+	}
+
+	return doc
+}
+
+// Upgraded reports whether the document was read at an older version,
+// and is written at CurrentVersion only when saved again.
+func (d EnterpriseDocument) Upgraded() bool {
+	return d.fromVersion != 0 && d.fromVersion != CurrentVersion
+}
+
+// UnmarshalJSON decodes a document of any version, migrating it to
+// CurrentVersion. Documents at CurrentVersion are decoded directly.
+func (d *EnterpriseDocument) UnmarshalJSON(b []byte) error {
+	// the alias has no UnmarshalJSON, which would recurse
+	type document EnterpriseDocument
+
+	var probe struct{ Version int }
+	if err := json.Unmarshal(b, &probe); err != nil {
+		return err
+	}
+
+	version := max(probe.Version, 1)
+	if version == CurrentVersion {
+		if err := json.Unmarshal(b, (*document)(d)); err != nil {
+			return err
+		}
+		d.fromVersion = version
+
+		return nil
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	migrated, err := Migrate(raw, version)
+	if err != nil {
+		return err
+	}
+
+	if b, err = json.Marshal(migrated); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(b, (*document)(d)); err != nil {
+		return err
+	}
+	d.fromVersion = version
+
+	return nil
+}
+
+// ToDomain returns the rules of the document.
+func (d EnterpriseDocument) ToDomain() (reader.EnterpriseData, error) {
+	output := make(reader.EnterpriseData, len(d.Rules))
+	for path, rule := range d.Rules {
+		enterprise, err := rule.ToDomain()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", path, err)
+		}
+
+		output[entity.PathKey(path)] = enterprise
+	}
+
+	return output, nil
+}
+
+func NewRule(e entity.Enterprise) Rule {
+	rule := Rule{
+		Video:    newVideo(e.Video),
+		Playlist: newVideos(e.Playlist),
+	}
+
+	if e.Url != nil {
+		rule.Url = e.Url.String()
+	}
+
+	if e.Experiment != nil {
+		rule.Experiment = &Experiment{Id: e.Experiment.Id}
+		for _, variant := range e.Experiment.Variants {
+			rule.Experiment.Variants = append(rule.Experiment.Variants, Variant{
+				Id:     variant.Id,
+				Weight: variant.Weight,
+				Video:  newVideo(variant.Video),
+			})
+		}
+	}
+
+	if e.Regions != nil {
+		rule.Regions = make(map[string]Video, len(e.Regions))
+		for region, video := range e.Regions {
+			rule.Regions[string(region)] = newVideo(video)
+		}
+	}
+
+	if e.Fallback != nil {
+		fallback := newVideo(*e.Fallback)
+		rule.Fallback = &fallback
+	}
+
+	return rule
+}
+
+// ToDomain returns the rule, with the fields derived from its url.
+func (r Rule) ToDomain() (entity.Enterprise, error) {
+	endpoint, err := url.Parse(r.Url)
+	if err != nil {
+		return entity.Enterprise{}, err
+	}
+
+	enterprise := entity.Enterprise{
+		Url:      endpoint,
+		Origin:   endpoint.Scheme + "://" + endpoint.Host,
+		Path:     endpoint.Path,
+		Paths:    strings.Split(endpoint.Path, "/")[1:],
+		Video:    r.Video.toDomain(),
+		Playlist: videosToDomain(r.Playlist),
+	}
+
+	if r.Experiment != nil {
+		enterprise.Experiment = &entity.Experiment{Id: r.Experiment.Id}
+		for _, variant := range r.Experiment.Variants {
+			enterprise.Experiment.Variants = append(enterprise.Experiment.Variants, entity.Variant{
+				Id:     variant.Id,
+				Weight: variant.Weight,
+				Video:  variant.Video.toDomain(),
+			})
+		}
+	}
+
+	if r.Regions != nil {
+		enterprise.Regions = make(map[entity.RegionCode]entity.Video, len(r.Regions))
+		for region, video := range r.Regions {
+			enterprise.Regions[entity.RegionCode(region)] = video.toDomain()
+		}
+	}
+
+	if r.Fallback != nil {
+		fallback := r.Fallback.toDomain()
+		enterprise.Fallback = &fallback
+	}
+
+	return enterprise, nil
+}
+
+func newVideos(videos []entity.Video) []Video {
+	if videos == nil {
+		return nil
+	}
+
+	output := make([]Video, len(videos))
+	for i, video := range videos {
+		output[i] = newVideo(video)
+	}
+
+	return output
+}
+
+func videosToDomain(videos []Video) []entity.Video {
+	if videos == nil {
+		return nil
+	}
+
+	output := make([]entity.Video, len(videos))
+	for i, video := range videos {
+		output[i] = video.toDomain()
+	}
+
+	return output
+}
+
+func newVideo(v entity.Video) Video {
+	video := Video{
+		VideoUrl:     v.VideoUrl,
+		ThumbnailUrl: v.TambnailUrl,
+		CatalogId:    v.CatalogId,
+	}
+
+	if m := v.Metadata; m != nil {
+		video.Metadata = &Metadata{
+			Title:           m.Title,
+			Description:     m.Description,
+			DurationSeconds: m.DurationSeconds,
+			AspectRatio:     m.AspectRatio,
+			PosterAlt:       m.PosterAlt,
+			UploadDate:      m.UploadDate,
+			Attributes:      m.Attributes,
+		}
+
+		for _, c := range m.Captions {
+			video.Metadata.Captions = append(video.Metadata.Captions, Caption{
+				Language: c.Language,
+				Kind:     string(c.Kind),
+				Label:    c.Label,
+				Url:      c.Url,
+				TrackId:  c.TrackId,
+			})
+		}
+
+		if p := m.Preview; p != nil {
+			video.Metadata.Preview = &Preview{
+				Url:             p.Url,
+				SpriteUrl:       p.SpriteUrl,
+				IntervalSeconds: p.IntervalSeconds,
+				TileWidth:       p.TileWidth,
+			}
+		}
+	}
+
+	for _, r := range v.Renditions {
+		video.Renditions = append(video.Renditions, Rendition(r))
+	}
+
+	if p := v.Placeholder; p != nil {
+		video.Placeholder = &Placeholder{BlurHash: p.BlurHash, DominantColor: p.DominantColor}
+	}
+
+	return video
+}
+
+func (v Video) toDomain() entity.Video {
+	video := entity.Video{
+		VideoUrl:    v.VideoUrl,
+		TambnailUrl: v.ThumbnailUrl,
+		CatalogId:   v.CatalogId,
+	}
+
+	if m := v.Metadata; m != nil {
+		video.Metadata = &entity.VideoMetadata{
+			Title:           m.Title,
+			Description:     m.Description,
+			DurationSeconds: m.DurationSeconds,
+			AspectRatio:     m.AspectRatio,
+			PosterAlt:       m.PosterAlt,
+			UploadDate:      m.UploadDate,
+			Attributes:      m.Attributes,
+		}
+
+		for _, c := range m.Captions {
+			video.Metadata.Captions = append(video.Metadata.Captions, entity.Caption{
+				Language: c.Language,
+				Kind:     entity.CaptionKind(c.Kind),
+				Label:    c.Label,
+				Url:      c.Url,
+				TrackId:  c.TrackId,
+			})
+		}
+
+		if p := m.Preview; p != nil {
+			video.Metadata.Preview = &entity.PreviewTrack{
+				Url:             p.Url,
+				SpriteUrl:       p.SpriteUrl,
+				IntervalSeconds: p.IntervalSeconds,
+				TileWidth:       p.TileWidth,
+			}
+		}
+	}
+
+	for _, r := range v.Renditions {
+		video.Renditions = append(video.Renditions, entity.Rendition(r))
+	}
+
+	if p := v.Placeholder; p != nil {
+		video.Placeholder = &entity.ImagePlaceholder{BlurHash: p.BlurHash, DominantColor: p.DominantColor}
+	}
+
+	return video
+}
@@ -0,0 +1,60 @@
+package schema
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+)
+
+// benchmarkDocument returns a document of many rules, as an enterprise
+// with a rule per product page.
+func benchmarkDocument(rulesCount int) EnterpriseDocument {
+	data := make(reader.EnterpriseData, rulesCount)
+	for i := range rulesCount {
+		for path, enterprise := range rules() {
+			data[entity.PathKey(fmt.Sprintf("%s/%d", path, i))] = enterprise
+		}
+	}
+
+	return NewEnterpriseDocument(data)
+}
+
+// BenchmarkCodecs compares the codecs on a document: the size of the
+// encoded document is reported as bytes/doc, next to the allocations of
+// encoding and decoding it.
+func BenchmarkCodecs(b *testing.B) {
+	doc := benchmarkDocument(100)
+
+	for _, codec := range []filesystem.Codec{filesystem.JSONCodec, filesystem.MessagePackCodec, filesystem.ProtobufCodec} {
+		data, err := codec.Marshal(doc)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.Run(codec.Name()+"/marshal", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ReportMetric(float64(len(data)), "bytes/doc")
+
+			for i := 0; i < b.N; i++ {
+				if _, err := codec.Marshal(doc); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(codec.Name()+"/unmarshal", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ReportMetric(float64(len(data)), "bytes/doc")
+
+			for i := 0; i < b.N; i++ {
+				var got EnterpriseDocument
+				if err := codec.Unmarshal(data, &got); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
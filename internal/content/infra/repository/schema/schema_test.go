@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema/schemapb"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/pkg/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// rules is stored in testdata at every version, enterprise.v<N>.json.golden.
+// Only the current version is written by -update: the older ones are frozen,
+// as no code writes them anymore.
+func rules() reader.EnterpriseData {
+	uploadDate := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fallback := entity.Video{VideoUrl: "https://cdn.com/fallback.mp4", TambnailUrl: "https://cdn.com/fallback.jpg"}
+
+	return reader.EnterpriseData{
+		"/home/camisa/*": {
+			Url:    mustParse("https://example.com/home/camisa/*"),
+			Origin: "https://example.com",
+			Paths:  []string{"home", "camisa", "*"},
+			Path:   "/home/camisa/*",
+			Video: entity.Video{
+				VideoUrl:    "https://cdn.com/camisa.mp4",
+				TambnailUrl: "https://cdn.com/camisa.jpg",
+				CatalogId:   "camisa",
+				Metadata: &entity.VideoMetadata{
+					Title:           "Camisa",
+					DurationSeconds: 12.5,
+					UploadDate:      &uploadDate,
+					Captions: []entity.Caption{
+						{Language: "pt-BR", Kind: entity.CaptionKindSubtitles, Url: "https://cdn.com/camisa.vtt"},
+					},
+					Preview:    &entity.PreviewTrack{Url: "https://cdn.com/camisa-preview.vtt", IntervalSeconds: 2},
+					Attributes: map[string]string{"sku": "123"},
+				},
+				Renditions: []entity.Rendition{
+					{PlaylistUrl: "https://cdn.com/camisa/720p.m3u8", Bandwidth: 2500000, Width: 1280, Height: 720},
+				},
+				Placeholder: &entity.ImagePlaceholder{BlurHash: "LEHV6nWB2yk8", DominantColor: "#336699"},
+			},
+			Regions: map[entity.RegionCode]entity.Video{
+				"BR-SP": {VideoUrl: "https://cdn.com/camisa-sp.mp4", TambnailUrl: "https://cdn.com/camisa-sp.jpg"},
+			},
+			Fallback: &fallback,
+		},
+		"/promo": {
+			Url:    mustParse("https://example.com/promo"),
+			Origin: "https://example.com",
+			Paths:  []string{"promo"},
+			Path:   "/promo",
+			Experiment: &entity.Experiment{
+				Id: "promo-2024",
+				Variants: []entity.Variant{
+					{Id: "a", Weight: 70, Video: entity.Video{VideoUrl: "https://cdn.com/a.mp4", TambnailUrl: "https://cdn.com/a.jpg"}},
+					{Id: "b", Weight: 30, Video: entity.Video{VideoUrl: "https://cdn.com/b.mp4", TambnailUrl: "https://cdn.com/b.jpg"}},
+				},
+			},
+			Playlist: []entity.Video{
+				{VideoUrl: "https://cdn.com/1.mp4", TambnailUrl: "https://cdn.com/1.jpg"},
+				{VideoUrl: "https://cdn.com/2.mp4", TambnailUrl: "https://cdn.com/2.jpg"},
+			},
+		},
+	}
+}
+
+func mustParse(rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+func goldenName(version int) string {
+	return fmt.Sprintf("enterprise.v%d.json.golden", version)
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestEnterpriseDocument_Encode(t *testing.T) {
+	got, err := json.MarshalIndent(NewEnterpriseDocument(rules()), "", "  ")
+	require.NoError(t, err)
+
+	assertGolden(t, goldenName(CurrentVersion), append(got, '\n'))
+}
+
+func TestEnterpriseDocument_Decode(t *testing.T) {
+	for version := 1; version <= CurrentVersion; version++ {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			b, err := os.ReadFile(filepath.Join("testdata", goldenName(version)))
+			require.NoError(t, err)
+
+			var doc EnterpriseDocument
+			require.NoError(t, json.Unmarshal(b, &doc))
+
+			assert.Equal(t, CurrentVersion, doc.Version)
+			assert.Equal(t, version != CurrentVersion, doc.Upgraded())
+
+			data, err := doc.ToDomain()
+			require.NoError(t, err)
+			assert.Equal(t, rules(), data)
+		})
+	}
+}
+
+func TestEnterpriseDocument_DecodeMigrated(t *testing.T) {
+	// a migrated document is written as the current version
+	b, err := os.ReadFile(filepath.Join("testdata", goldenName(1)))
+	require.NoError(t, err)
+
+	var doc EnterpriseDocument
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	got, err := json.MarshalIndent(doc, "", "  ")
+	require.NoError(t, err)
+
+	want, err := os.ReadFile(filepath.Join("testdata", goldenName(CurrentVersion)))
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got)+"\n")
+}
+
+func TestEnterpriseDocument_DecodeUnsupportedVersion(t *testing.T) {
+	var doc EnterpriseDocument
+	err := json.Unmarshal([]byte(fmt.Sprintf(`{"Version": %d, "Rules": {}}`, CurrentVersion+1)), &doc)
+
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestEnterpriseDocument_BinaryCodecs(t *testing.T) {
+	for _, codec := range []filesystem.Codec{filesystem.MessagePackCodec, filesystem.ProtobufCodec} {
+		t.Run(codec.Name(), func(t *testing.T) {
+			b, err := codec.Marshal(NewEnterpriseDocument(rules()))
+			require.NoError(t, err)
+
+			var doc EnterpriseDocument
+			require.NoError(t, codec.Unmarshal(b, &doc))
+
+			assert.Equal(t, CurrentVersion, doc.Version)
+			assert.False(t, doc.Upgraded())
+
+			data, err := doc.ToDomain()
+			require.NoError(t, err)
+			assert.Equal(t, rules(), data)
+		})
+	}
+}
+
+func TestEnterpriseDocument_DecodeMsgpackMigrated(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("testdata", goldenName(1)))
+	require.NoError(t, err)
+
+	// a version 1 document, as MessagePack writes it
+	var v1 map[string]any
+	require.NoError(t, json.Unmarshal(b, &v1))
+	b, err = filesystem.MessagePackCodec.Marshal(v1)
+	require.NoError(t, err)
+
+	var doc EnterpriseDocument
+	require.NoError(t, filesystem.MessagePackCodec.Unmarshal(b, &doc))
+
+	assert.True(t, doc.Upgraded())
+
+	data, err := doc.ToDomain()
+	require.NoError(t, err)
+	assert.Equal(t, rules(), data)
+}
+
+func TestEnterpriseDocument_UnmarshalProtoUnsupportedVersion(t *testing.T) {
+	b, err := filesystem.ProtobufCodec.Marshal(&schemapb.EnterpriseDocument{Version: CurrentVersion + 1})
+	require.NoError(t, err)
+
+	var doc EnterpriseDocument
+	err = filesystem.ProtobufCodec.Unmarshal(b, &doc)
+
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+}
+
+func TestMigrate_EveryVersionHasAMigration(t *testing.T) {
+	for version := 1; version < CurrentVersion; version++ {
+		assert.Contains(t, migrations, version)
+	}
+}
@@ -0,0 +1,5 @@
+// Package schemapb holds the protobuf messages of the persisted schema,
+// generated from schema.proto.
+package schemapb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative schema.proto
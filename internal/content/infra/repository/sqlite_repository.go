@@ -0,0 +1,511 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/internal/content/infra/repository/schema"
+	"github.com/IsaacDSC/search_content/internal/content/reader"
+	"github.com/IsaacDSC/search_content/internal/content/writer"
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// sqliteMigrations creates and changes the tables, one version each. The
+// version of a database is its user_version, a migration is applied with
+// the version it sets in a single transaction. Only append to the list.
+//
+// The videos of a rule are stored as the json of schema.Video, the tables
+// hold what's queried: the hosts, the paths, the variants and the catalog
+// videos referenced.
+var sqliteMigrations = []string{
+	`CREATE TABLE enterprises (
+		id   INTEGER PRIMARY KEY,
+		host TEXT NOT NULL UNIQUE
+	);
+
+	CREATE TABLE rules (
+		id            INTEGER PRIMARY KEY,
+		enterprise_id INTEGER NOT NULL REFERENCES enterprises (id) ON DELETE CASCADE,
+		path          TEXT NOT NULL,
+		url           TEXT NOT NULL,
+		video         TEXT NOT NULL,
+		experiment_id TEXT,
+		regions       TEXT,
+		playlist      TEXT,
+		fallback      TEXT
+	);
+
+	-- the rules of a host, and of a path prefix in a host, e.g.
+	-- WHERE enterprise_id = ? AND path >= '/promo/' AND path < '/promo0'
+	CREATE UNIQUE INDEX rules_enterprise_path ON rules (enterprise_id, path);
+	-- a path prefix across the hosts
+	CREATE INDEX rules_path ON rules (path);
+
+	CREATE TABLE variants (
+		rule_id    INTEGER NOT NULL REFERENCES rules (id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		variant_id TEXT NOT NULL,
+		weight     INTEGER NOT NULL,
+		video      TEXT NOT NULL,
+		PRIMARY KEY (rule_id, position)
+	);`,
+
+	// any video of a rule can reference the catalog, so the ids get a table
+	// of their own, filled in from the rules already stored
+	`CREATE TABLE catalog_references (
+		catalog_id TEXT NOT NULL,
+		rule_id    INTEGER NOT NULL REFERENCES rules (id) ON DELETE CASCADE,
+		PRIMARY KEY (catalog_id, rule_id)
+	) WITHOUT ROWID;
+
+	CREATE INDEX catalog_references_rule ON catalog_references (rule_id);
+
+	INSERT INTO catalog_references (catalog_id, rule_id)
+	SELECT DISTINCT catalog_id, rule_id FROM (
+		SELECT json_extract(video, '$.CatalogId') AS catalog_id, id AS rule_id FROM rules
+		UNION ALL SELECT json_extract(fallback, '$.CatalogId'), id FROM rules
+		UNION ALL SELECT json_extract(p.value, '$.CatalogId'), r.id FROM rules r, json_each(r.playlist) p
+		UNION ALL SELECT json_extract(g.value, '$.CatalogId'), r.id FROM rules r, json_each(r.regions) g
+		UNION ALL SELECT json_extract(video, '$.CatalogId'), rule_id FROM variants
+	) WHERE catalog_id IS NOT NULL AND catalog_id != '';`,
+}
+
+// sqliteBusyTimeout is how long, in milliseconds, a write waits for the
+// one in progress, instead of failing with SQLITE_BUSY.
+const sqliteBusyTimeout = 5000
+
+// SQLiteRepo stores the rules in a SQLite database, in WAL mode so the
+// reads don't wait for the writes. Each Save is a transaction writing the
+// rows of its rule only.
+type SQLiteRepo struct {
+	db *sql.DB
+}
+
+var _ Repository = (*SQLiteRepo)(nil)
+
+// NewSQLiteRepo opens, or creates, the database at path and applies the
+// migrations it lacks.
+func NewSQLiteRepo(path string) (*SQLiteRepo, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// transactions take the write lock when they begin, so two saves
+	// never both read and then fail to upgrade to writing
+	dsn := (&url.URL{Scheme: "file", Path: path, RawQuery: url.Values{
+		"_pragma": {
+			"journal_mode(WAL)",
+			"foreign_keys(1)",
+			fmt.Sprintf("busy_timeout(%d)", sqliteBusyTimeout),
+		},
+		"_txlock": {"immediate"},
+	}.Encode()}).String()
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	if err := migrateSQLite(context.Background(), db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	return &SQLiteRepo{db: db}, nil
+}
+
+func (r *SQLiteRepo) Close() error {
+	return r.db.Close()
+}
+
+func migrateSQLite(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	if version > len(sqliteMigrations) {
+		return fmt.Errorf("database at version %d, newer than %d", version, len(sqliteMigrations))
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		err := inTx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, sqliteMigrations[version]); err != nil {
+				return err
+			}
+
+			// PRAGMA takes no parameters
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d: %w", version+1, err)
+		}
+	}
+
+	return nil
+}
+
+// inTx runs fn in a transaction, committed when fn succeeds.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Save upserts the enterprise and the rule of the path, and replaces the
+// variants and the catalog references of the rule, in one transaction.
+func (r *SQLiteRepo) Save(ctx context.Context, enterprise entity.Enterprise) error {
+	enterpriseKey := entity.NewEnterpriseKey(enterprise.Url)
+	pathKey := entity.NewPathKey(enterprise.Url)
+	if enterpriseKey == "" {
+		return fmt.Errorf("%w: no host", writer.ErrInvalidEndpoint)
+	}
+
+	rule := schema.NewRule(enterprise)
+	columns, err := newRuleColumns(rule)
+	if err != nil {
+		return fmt.Errorf("failed to encode rule: %w", err)
+	}
+
+	err = inTx(ctx, r.db, func(tx *sql.Tx) error {
+		// the no-op update makes RETURNING give the id of an existing host
+		var enterpriseId int64
+		err := tx.QueryRowContext(ctx,
+			`INSERT INTO enterprises (host) VALUES (?)
+			ON CONFLICT (host) DO UPDATE SET host = excluded.host
+			RETURNING id`,
+			enterpriseKey,
+		).Scan(&enterpriseId)
+		if err != nil {
+			return fmt.Errorf("failed to save enterprise: %w", err)
+		}
+
+		var ruleId int64
+		err = tx.QueryRowContext(ctx,
+			`INSERT INTO rules (enterprise_id, path, url, video, experiment_id, regions, playlist, fallback)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (enterprise_id, path) DO UPDATE SET
+				url = excluded.url,
+				video = excluded.video,
+				experiment_id = excluded.experiment_id,
+				regions = excluded.regions,
+				playlist = excluded.playlist,
+				fallback = excluded.fallback
+			RETURNING id`,
+			enterpriseId, pathKey, rule.Url, columns.video, columns.experimentId, columns.regions, columns.playlist, columns.fallback,
+		).Scan(&ruleId)
+		if err != nil {
+			return fmt.Errorf("failed to save rule: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM variants WHERE rule_id = ?`, ruleId); err != nil {
+			return fmt.Errorf("failed to delete variants: %w", err)
+		}
+
+		for i, variant := range columns.variants {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO variants (rule_id, position, variant_id, weight, video) VALUES (?, ?, ?, ?, ?)`,
+				ruleId, i, variant.id, variant.weight, variant.video,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to save variant %s: %w", variant.id, err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM catalog_references WHERE rule_id = ?`, ruleId); err != nil {
+			return fmt.Errorf("failed to delete catalog references: %w", err)
+		}
+
+		for _, catalogId := range enterprise.CatalogIds() {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO catalog_references (catalog_id, rule_id) VALUES (?, ?)`,
+				catalogId, ruleId,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to save catalog reference %s: %w", catalogId, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save enterprise rule: %w", err)
+	}
+
+	return nil
+}
+
+// ruleColumns is a rule as stored in the rules and variants tables, the
+// optional fields NULL when empty.
+type ruleColumns struct {
+	video        string
+	experimentId sql.NullString
+	regions      sql.NullString
+	playlist     sql.NullString
+	fallback     sql.NullString
+	variants     []variantColumns
+}
+
+type variantColumns struct {
+	id     string
+	weight int
+	video  string
+}
+
+func newRuleColumns(rule schema.Rule) (ruleColumns, error) {
+	var columns ruleColumns
+	var err error
+
+	if columns.video, err = encodeColumn(rule.Video); err != nil {
+		return ruleColumns{}, err
+	}
+
+	if rule.Experiment != nil {
+		columns.experimentId = sql.NullString{String: rule.Experiment.Id, Valid: true}
+		for _, variant := range rule.Experiment.Variants {
+			video, err := encodeColumn(variant.Video)
+			if err != nil {
+				return ruleColumns{}, err
+			}
+
+			columns.variants = append(columns.variants, variantColumns{id: variant.Id, weight: variant.Weight, video: video})
+		}
+	}
+
+	if rule.Regions != nil {
+		if columns.regions, err = encodeNullColumn(rule.Regions); err != nil {
+			return ruleColumns{}, err
+		}
+	}
+
+	if rule.Playlist != nil {
+		if columns.playlist, err = encodeNullColumn(rule.Playlist); err != nil {
+			return ruleColumns{}, err
+		}
+	}
+
+	if rule.Fallback != nil {
+		if columns.fallback, err = encodeNullColumn(rule.Fallback); err != nil {
+			return ruleColumns{}, err
+		}
+	}
+
+	return columns, nil
+}
+
+func encodeColumn(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func encodeNullColumn(v any) (sql.NullString, error) {
+	s, err := encodeColumn(v)
+	return sql.NullString{String: s, Valid: err == nil}, err
+}
+
+// decodeNullColumn leaves v as is when the column is NULL.
+func decodeNullColumn(column sql.NullString, v any) error {
+	if !column.Valid {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(column.String), v)
+}
+
+func (r *SQLiteRepo) Get(ctx context.Context, enterpriseKey entity.EnterpriseKey) (reader.EnterpriseData, error) {
+	rules, err := r.queryRules(ctx, "WHERE e.host = ?", enterpriseKey)
+	if err != nil {
+		return reader.EnterpriseData{}, err
+	}
+
+	if len(rules) == 0 {
+		return reader.EnterpriseData{}, fmt.Errorf("%w: %s", reader.ErrContentNotFound, enterpriseKey)
+	}
+
+	data := make(reader.EnterpriseData, len(rules))
+	for _, rule := range rules {
+		data[rule.path] = rule.enterprise
+	}
+
+	return data, nil
+}
+
+type storedRule struct {
+	path       entity.PathKey
+	enterprise entity.Enterprise
+}
+
+// queryRules returns the rules matching the filter on the rules r and the
+// enterprises e, with their variants.
+func (r *SQLiteRepo) queryRules(ctx context.Context, filter string, args ...any) ([]storedRule, error) {
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	// read only, the rules and their variants are read from the same snapshot
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT r.id, r.path, r.url, r.video, r.experiment_id, r.regions, r.playlist, r.fallback
+		FROM rules r JOIN enterprises e ON e.id = r.enterprise_id `+filter+`
+		ORDER BY r.id`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+
+	var ids []int64
+	var paths []entity.PathKey
+	var rules []schema.Rule
+	for rows.Next() {
+		var id int64
+		var path, video string
+		var rule schema.Rule
+		var experimentId, regions, playlist, fallback sql.NullString
+		if err := rows.Scan(&id, &path, &rule.Url, &video, &experimentId, &regions, &playlist, &fallback); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read rule: %w", err)
+		}
+
+		err := errors.Join(
+			json.Unmarshal([]byte(video), &rule.Video),
+			decodeNullColumn(regions, &rule.Regions),
+			decodeNullColumn(playlist, &rule.Playlist),
+			decodeNullColumn(fallback, &rule.Fallback),
+		)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to decode rule %s: %w", path, err)
+		}
+
+		if experimentId.Valid {
+			rule.Experiment = &schema.Experiment{Id: experimentId.String}
+		}
+
+		ids = append(ids, id)
+		paths = append(paths, entity.PathKey(path))
+		rules = append(rules, rule)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query rules: %w", err)
+	}
+
+	byId := make(map[int64]*schema.Rule, len(rules))
+	for i := range rules {
+		byId[ids[i]] = &rules[i]
+	}
+
+	if err := queryVariants(ctx, tx, byId, filter, args...); err != nil {
+		return nil, err
+	}
+
+	output := make([]storedRule, len(rules))
+	for i, rule := range rules {
+		enterprise, err := rule.ToDomain()
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", paths[i], err)
+		}
+
+		output[i] = storedRule{path: paths[i], enterprise: enterprise}
+	}
+
+	return output, nil
+}
+
+// queryVariants adds the variants to the experiments of the rules.
+func queryVariants(ctx context.Context, tx *sql.Tx, rules map[int64]*schema.Rule, filter string, args ...any) error {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT v.rule_id, v.variant_id, v.weight, v.video
+		FROM variants v JOIN rules r ON r.id = v.rule_id JOIN enterprises e ON e.id = r.enterprise_id `+filter+`
+		ORDER BY v.rule_id, v.position`,
+		args...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to query variants: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ruleId int64
+		var variant schema.Variant
+		var video string
+		if err := rows.Scan(&ruleId, &variant.Id, &variant.Weight, &video); err != nil {
+			return fmt.Errorf("failed to read variant: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(video), &variant.Video); err != nil {
+			return fmt.Errorf("failed to decode variant %s: %w", variant.Id, err)
+		}
+
+		// variants are kept only with the experiment of their rule
+		if rule, ok := rules[ruleId]; ok && rule.Experiment != nil {
+			rule.Experiment.Variants = append(rule.Experiment.Variants, variant)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *SQLiteRepo) ListEnterprises(ctx context.Context) ([]entity.EnterpriseKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT host FROM enterprises ORDER BY host`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list enterprises: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []entity.EnterpriseKey
+	for rows.Next() {
+		var key entity.EnterpriseKey
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("failed to list enterprises: %w", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// FindVideoReferences looks the catalog id up in catalog_references.
+func (r *SQLiteRepo) FindVideoReferences(ctx context.Context, id string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT r.url FROM catalog_references c JOIN rules r ON r.id = c.rule_id
+		WHERE c.catalog_id = ?
+		ORDER BY r.url`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find video references: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []string
+	for rows.Next() {
+		var endpoint string
+		if err := rows.Scan(&endpoint); err != nil {
+			return nil, fmt.Errorf("failed to find video references: %w", err)
+		}
+
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, rows.Err()
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteRepo_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.sqlite")
+	ctx := context.Background()
+
+	repo, err := NewSQLiteRepo(path)
+	require.NoError(t, err)
+
+	endpoint, _ := url.Parse("https://shop.com/promo/summer")
+	require.NoError(t, repo.Save(ctx, entity.Enterprise{
+		Url: endpoint,
+		Experiment: &entity.Experiment{Id: "summer", Variants: []entity.Variant{
+			{Id: "a", Weight: 50},
+			{Id: "b", Weight: 50},
+		}},
+	}))
+	require.NoError(t, repo.Close())
+
+	// the migrations already applied are skipped
+	repo, err = NewSQLiteRepo(path)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var version int
+	require.NoError(t, repo.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version))
+	assert.Equal(t, len(sqliteMigrations), version)
+
+	var journalMode string
+	require.NoError(t, repo.db.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode))
+	assert.Equal(t, "wal", journalMode)
+
+	// the rules can be queried with SQL
+	var variants int
+	err = repo.db.QueryRowContext(ctx,
+		`SELECT count(*) FROM variants v
+		JOIN rules r ON r.id = v.rule_id
+		JOIN enterprises e ON e.id = r.enterprise_id
+		WHERE e.host = ? AND r.path >= '/promo/' AND r.path < '/promo0'`,
+		"shop.com",
+	).Scan(&variants)
+	require.NoError(t, err)
+	assert.Equal(t, 2, variants)
+}
+
+func TestSQLiteRepo_MigrateCatalogReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.sqlite")
+	ctx := context.Background()
+
+	repo, err := NewSQLiteRepo(path)
+	require.NoError(t, err)
+
+	home, _ := url.Parse("https://shop.com/home")
+	promo, _ := url.Parse("https://shop.com/promo")
+	require.NoError(t, repo.Save(ctx, entity.Enterprise{
+		Url:      home,
+		Video:    entity.Video{CatalogId: "summer"},
+		Regions:  map[entity.RegionCode]entity.Video{"BR": {CatalogId: "winter"}},
+		Playlist: []entity.Video{{CatalogId: "spring"}},
+	}))
+	require.NoError(t, repo.Save(ctx, entity.Enterprise{
+		Url:      promo,
+		Fallback: &entity.Video{CatalogId: "summer"},
+		Experiment: &entity.Experiment{Id: "promo", Variants: []entity.Variant{
+			{Id: "a", Weight: 100, Video: entity.Video{CatalogId: "autumn"}},
+		}},
+	}))
+
+	// back to a database written before the references had a table
+	_, err = repo.db.ExecContext(ctx, `DROP TABLE catalog_references; PRAGMA user_version = 1`)
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	repo, err = NewSQLiteRepo(path)
+	require.NoError(t, err)
+	defer repo.Close()
+
+	for id, expected := range map[string][]string{
+		"summer": {"https://shop.com/home", "https://shop.com/promo"},
+		"winter": {"https://shop.com/home"},
+		"spring": {"https://shop.com/home"},
+		"autumn": {"https://shop.com/promo"},
+	} {
+		endpoints, err := repo.FindVideoReferences(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, expected, endpoints, id)
+	}
+}
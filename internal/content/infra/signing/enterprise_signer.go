@@ -0,0 +1,118 @@
+package signing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/pkg/urlsign"
+	"os"
+	"time"
+)
+
+// EnterpriseConfig is the signing configuration of one enterprise, as
+// read from the JSON file keyed by enterprise host:
+//
+//	{
+//	  "example.com": {
+//	    "keys": [{"id": "2024-10", "secret": "..."}, {"id": "2024-09", "secret": "..."}],
+//	    "ttl": "15m",
+//	    "expires_param": "Expires",
+//	    "signature_param": "Signature",
+//	    "key_id_param": "Key-Pair-Id"
+//	  }
+//	}
+//
+// The first key signs new urls; the others are still accepted by the edge
+// while a rotation is in progress. Empty parameter names use urlsign.DefaultParams.
+type EnterpriseConfig struct {
+	Keys           []KeyConfig `json:"keys"`
+	TTL            string      `json:"ttl"`
+	ExpiresParam   string      `json:"expires_param"`
+	SignatureParam string      `json:"signature_param"`
+	KeyIdParam     string      `json:"key_id_param"`
+}
+
+type KeyConfig struct {
+	Id     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+func (c EnterpriseConfig) Params() urlsign.Params {
+	return urlsign.Params{Expires: c.ExpiresParam, Signature: c.SignatureParam, KeyId: c.KeyIdParam}
+}
+
+func (c EnterpriseConfig) ActiveKeys() []urlsign.Key {
+	keys := make([]urlsign.Key, len(c.Keys))
+	for i, k := range c.Keys {
+		keys[i] = urlsign.Key{Id: k.Id, Secret: []byte(k.Secret)}
+	}
+
+	return keys
+}
+
+// Signer builds the signer of the enterprise, which uses the first key.
+func (c EnterpriseConfig) Signer() (*urlsign.Signer, error) {
+	if len(c.Keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+
+	ttl, err := time.ParseDuration(c.TTL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ttl: %w", err)
+	}
+
+	// a verifier rejects duplicated or incomplete keys, including the retired ones
+	if _, err := urlsign.NewVerifier(c.Params(), c.ActiveKeys()...); err != nil {
+		return nil, err
+	}
+
+	return urlsign.NewSigner(c.ActiveKeys()[0], ttl, c.Params())
+}
+
+// LoadConfig reads the signing configuration of every enterprise from path.
+func LoadConfig(path string) (map[entity.EnterpriseKey]EnterpriseConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs map[entity.EnterpriseKey]EnterpriseConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid signing config: %w", err)
+	}
+
+	return configs, nil
+}
+
+// EnterpriseSigner signs urls with the signer of each enterprise.
+type EnterpriseSigner struct {
+	signers map[entity.EnterpriseKey]*urlsign.Signer
+}
+
+func NewEnterpriseSigner(configs map[entity.EnterpriseKey]EnterpriseConfig) (*EnterpriseSigner, error) {
+	signers := make(map[entity.EnterpriseKey]*urlsign.Signer, len(configs))
+	for enterprise, cfg := range configs {
+		signer, err := cfg.Signer()
+		if err != nil {
+			return nil, fmt.Errorf("enterprise %s: %w", enterprise, err)
+		}
+		signers[enterprise] = signer
+	}
+
+	return &EnterpriseSigner{signers: signers}, nil
+}
+
+func (s *EnterpriseSigner) Sign(enterprise entity.EnterpriseKey, rawUrl string) (string, bool, error) {
+	signer, ok := s.signers[enterprise]
+	if !ok {
+		return rawUrl, false, nil
+	}
+
+	signed, err := signer.Sign(rawUrl)
+	if err != nil {
+		return "", false, err
+	}
+
+	return signed, true, nil
+}
@@ -0,0 +1,67 @@
+package signing
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/pkg/urlsign"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnterpriseSigner_Sign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signing.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"example.com": {
+			"keys": [{"id": "new", "secret": "s2"}, {"id": "old", "secret": "s1"}],
+			"ttl": "15m",
+			"signature_param": "token"
+		}
+	}`), 0o600))
+
+	configs, err := LoadConfig(path)
+	require.NoError(t, err)
+
+	signer, err := NewEnterpriseSigner(configs)
+	require.NoError(t, err)
+
+	t.Run("configured enterprise", func(t *testing.T) {
+		signed, ok, err := signer.Sign("example.com", "https://cdn.example.com/a.mp4")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		u, _ := url.Parse(signed)
+		assert.Equal(t, "new", u.Query().Get("kid"))
+
+		cfg := configs["example.com"]
+		verifier, err := urlsign.NewVerifier(cfg.Params(), cfg.ActiveKeys()...)
+		require.NoError(t, err)
+		assert.NoError(t, verifier.Verify(u))
+	})
+
+	t.Run("enterprise without config", func(t *testing.T) {
+		signed, ok, err := signer.Sign("other.com", "https://cdn.other.com/a.mp4")
+		require.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, "https://cdn.other.com/a.mp4", signed)
+	})
+}
+
+func TestNewEnterpriseSigner_InvalidConfig(t *testing.T) {
+	tests := map[string]EnterpriseConfig{
+		"without keys":  {TTL: "1m"},
+		"invalid ttl":   {Keys: []KeyConfig{{Id: "a", Secret: "s"}}, TTL: "soon"},
+		"duplicate key": {Keys: []KeyConfig{{Id: "a", Secret: "s"}, {Id: "a", Secret: "t"}}, TTL: "1m"},
+		"empty secret":  {Keys: []KeyConfig{{Id: "a"}}, TTL: "1m"},
+	}
+
+	for name, cfg := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewEnterpriseSigner(map[entity.EnterpriseKey]EnterpriseConfig{"example.com": cfg})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package reader
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+)
+
+// JoinCatalog returns a copy of the rule where the videos that reference
+// the catalog are replaced by the catalog videos. They keep the CatalogId,
+// so clients can tell the videos shared by many rules.
+func JoinCatalog(ctx context.Context, catalog CatalogRepository, rule entity.Enterprise) (entity.Enterprise, error) {
+	ids := rule.CatalogIds()
+	if len(ids) == 0 {
+		return rule, nil
+	}
+
+	videos := make(map[string]entity.Video, len(ids))
+	for _, id := range ids {
+		catalogVideo, err := catalog.GetVideo(ctx, id)
+		if err != nil {
+			return entity.Enterprise{}, fmt.Errorf("failed to get video %s: %w", id, err)
+		}
+
+		video := catalogVideo.Video
+		video.CatalogId = id
+		videos[id] = video
+	}
+
+	rule = rule.Clone()
+	rule.EachVideo(func(video *entity.Video) {
+		if video.CatalogId != "" {
+			*video = videos[video.CatalogId]
+		}
+	})
+
+	return rule, nil
+}
@@ -1,6 +1,7 @@
 package reader
 
 import (
+	"github.com/IsaacDSC/search_content/internal/content/entity"
 	"net/url"
 )
 
@@ -18,3 +19,41 @@ func (e EndpointDto) ToDomain() (*url.URL, error) {
 
 	return endpoint, nil
 }
+
+type ContentInputDto struct {
+	Endpoint  EndpointDto
+	VisitorId string
+	Region    entity.RegionCode
+	// Shuffle returns the playlist in random order.
+	Shuffle bool
+	// MaxItems limits the size of the playlist, zero means no limit.
+	MaxItems int
+}
+
+type ContentOutputDto struct {
+	entity.Video
+	Playlist     []entity.Video
+	ExperimentId string            `json:",omitempty"`
+	VariantId    string            `json:",omitempty"`
+	Region       entity.RegionCode `json:",omitempty"`
+	Shuffled     bool              `json:"-"`
+	// Varies means the rule serves other visitors another video, even when
+	// this one got the default.
+	Varies bool `json:"-"`
+	// Signed means the urls carry an expiry, so the response must not outlive it in a cache.
+	Signed bool `json:"-"`
+	// FellBack means a broken video was replaced by the fallback of the
+	// rule, which lasts only until the next check of the links.
+	FellBack bool `json:"-"`
+	// CacheTags invalidate the cached response: the tags of the rule and of
+	// the catalog videos joined into it.
+	CacheTags []string `json:"-"`
+}
+
+// IsPersonalized reports whether the content depends on who is asking
+// (or changes on every request), in which case it must not be shared.
+// It depends on the rule and not on the video picked: the default video
+// of a regional rule is not for the visitors of its regions.
+func (c ContentOutputDto) IsPersonalized() bool {
+	return c.Varies || c.ExperimentId != "" || c.Region != "" || c.Shuffled
+}
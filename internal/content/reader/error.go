@@ -0,0 +1,10 @@
+package reader
+
+import "errors"
+
+var (
+	ErrContentNotFound = errors.New("content not found")
+	ErrCaptionNotFound = errors.New("caption not found")
+	ErrAssetNotFound   = errors.New("asset not found")
+	ErrVideoNotFound   = errors.New("video not found")
+)
@@ -0,0 +1,67 @@
+package reader
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GetAsset streams an uploaded asset. http.ServeContent answers Range and
+// If-Range requests, so browsers can seek in videos, and conditional
+// requests against the ETag, which is the content address of the asset.
+func (h *HttpHandler) GetAsset(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	asset, file, err := h.service.GetAsset(r.Context(), r.PathValue("id"))
+	if errors.Is(err, ErrAssetNotFound) {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	if err != nil {
+		http.Error(w, "Failed to get asset", http.StatusInternalServerError)
+		return err
+	}
+	defer file.Close()
+
+	contentType, err := sniffContentType(file)
+	if err != nil {
+		http.Error(w, "Failed to read asset", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", `"`+asset.Id+`"`)
+
+	http.ServeContent(w, r, "", asset.ModTime, file)
+
+	return nil
+}
+
+// sniffContentType detects the media type from the first bytes of the file
+// and rewinds it. Anything other than images, videos and audio is served as
+// a download, so an uploaded html page never runs on this origin.
+func sniffContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	for _, prefix := range []string{"image/", "video/", "audio/"} {
+		if strings.HasPrefix(contentType, prefix) && contentType != "image/svg+xml" {
+			return contentType, nil
+		}
+	}
+
+	return "application/octet-stream", nil
+}
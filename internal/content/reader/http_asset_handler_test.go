@@ -0,0 +1,123 @@
+package reader
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+type nopSeekCloser struct{ io.ReadSeeker }
+
+func (nopSeekCloser) Close() error { return nil }
+
+func TestHttpHandler_GetAsset(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n" + strings.Repeat("0123456789", 10))
+	html := []byte("<html><script>alert(1)</script></html>")
+
+	id := strings.Repeat("a", 64)
+	etag := `"` + id + `"`
+
+	tests := []struct {
+		name            string
+		content         []byte
+		header          http.Header
+		notFound        bool
+		wantStatus      int
+		wantBody        string
+		wantContentType string
+	}{
+		{
+			name:            "full content",
+			content:         png,
+			wantStatus:      http.StatusOK,
+			wantBody:        string(png),
+			wantContentType: "image/png",
+		},
+		{
+			name:            "range",
+			content:         png,
+			header:          http.Header{"Range": {"bytes=8-11"}},
+			wantStatus:      http.StatusPartialContent,
+			wantBody:        "0123",
+			wantContentType: "image/png",
+		},
+		{
+			name:            "if-range with current etag",
+			content:         png,
+			header:          http.Header{"Range": {"bytes=8-11"}, "If-Range": {etag}},
+			wantStatus:      http.StatusPartialContent,
+			wantBody:        "0123",
+			wantContentType: "image/png",
+		},
+		{
+			name:            "if-range with stale etag",
+			content:         png,
+			header:          http.Header{"Range": {"bytes=8-11"}, "If-Range": {`"stale"`}},
+			wantStatus:      http.StatusOK,
+			wantBody:        string(png),
+			wantContentType: "image/png",
+		},
+		{
+			name:       "not modified",
+			content:    png,
+			header:     http.Header{"If-None-Match": {etag}},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:            "html is served as download",
+			content:         html,
+			wantStatus:      http.StatusOK,
+			wantBody:        string(html),
+			wantContentType: "application/octet-stream",
+		},
+		{
+			name:       "missing asset",
+			notFound:   true,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAssets := NewMockAssetRepository(ctrl)
+			if tt.notFound {
+				mockAssets.EXPECT().GetAsset(gomock.Any(), id).Return(entity.Asset{}, nil, ErrAssetNotFound)
+			} else {
+				asset := entity.Asset{Id: id, Size: int64(len(tt.content)), ModTime: time.Now()}
+				mockAssets.EXPECT().GetAsset(gomock.Any(), id).Return(asset, nopSeekCloser{bytes.NewReader(tt.content)}, nil)
+			}
+
+			service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), mockAssets, nil, nil, nil)
+			handler := NewHandler(service, nil, Placeholders{}, nil, "")
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /assets/{id}", func(w http.ResponseWriter, r *http.Request) { handler.GetAsset(w, r) })
+
+			req := httptest.NewRequest(http.MethodGet, "/assets/"+id, nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.wantBody != "" {
+				assert.Equal(t, tt.wantBody, rec.Body.String())
+				assert.Equal(t, tt.wantContentType, rec.Header().Get("Content-Type"))
+				assert.Equal(t, etag, rec.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package reader
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// GetVideo answers with a video of the catalog.
+func (h *HttpHandler) GetVideo(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	// PUT /videos/{id} replaces the video, the response must not be cached
+	w.Header().Set("Cache-Control", "no-store")
+
+	video, err := h.service.GetVideo(r.Context(), r.PathValue("id"))
+	if errors.Is(err, ErrVideoNotFound) {
+		http.Error(w, "Video not found", http.StatusNotFound)
+		return nil
+	}
+
+	if err != nil {
+		http.Error(w, "Failed to get video", http.StatusInternalServerError)
+		return err
+	}
+
+	return json.NewEncoder(w).Encode(video)
+}
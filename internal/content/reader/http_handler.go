@@ -3,19 +3,43 @@ package reader
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"io"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 type Handler interface {
 	GetContent(w http.ResponseWriter, r *http.Request) error
+	GetCaption(w http.ResponseWriter, r *http.Request) error
+	GetManifest(w http.ResponseWriter, r *http.Request) error
+	RedirectVideo(w http.ResponseWriter, r *http.Request) error
+	RedirectThumbnail(w http.ResponseWriter, r *http.Request) error
+	GetAsset(w http.ResponseWriter, r *http.Request) error
+	GetThumbnail(w http.ResponseWriter, r *http.Request) error
+	GetVideo(w http.ResponseWriter, r *http.Request) error
 }
 
+// RegionOverrideHeader lets QA force the region of a request, e.g. "BR-SP".
+const RegionOverrideHeader = "X-Geo-Region"
+
 type HttpHandler struct {
-	service Service
+	service       Service
+	regions       RegionResolver
+	placeholders  Placeholders
+	thumbnails    ThumbnailCache
+	publicBaseUrl string
 }
 
-func NewHandler(service Service) *HttpHandler {
-	return &HttpHandler{service: service}
+// NewHandler creates the reader handler. regions may be nil when
+// no GeoIP database is configured, and thumbnails when resized
+// thumbnails are not cached. publicBaseUrl is the address of the
+// assets this service resizes.
+func NewHandler(service Service, regions RegionResolver, placeholders Placeholders, thumbnails ThumbnailCache, publicBaseUrl string) *HttpHandler {
+	return &HttpHandler{service: service, regions: regions, placeholders: placeholders, thumbnails: thumbnails, publicBaseUrl: publicBaseUrl}
 }
 
 func (h *HttpHandler) GetContent(w http.ResponseWriter, r *http.Request) error {
@@ -27,24 +51,44 @@ func (h *HttpHandler) GetContent(w http.ResponseWriter, r *http.Request) error {
 
 	defer r.Body.Close()
 
-	endpoint := r.PathValue("endpoint")
-
-	decodedBytes, err := base64.StdEncoding.DecodeString(endpoint)
+	endpoint, err := decodeEndpoint(r.PathValue("endpoint"))
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte("Invalid base64 encoding"))
 		return err
 	}
 
-	decodedEndpoint := NewEndpointDto(string(decodedBytes))
+	shuffle, maxItems, err := playlistParams(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return nil
+	}
+
+	visitor, isNewVisitor := visitorId(r)
+	input := ContentInputDto{
+		Endpoint:  endpoint,
+		VisitorId: visitor,
+		Region:    h.region(r),
+		Shuffle:   shuffle,
+		MaxItems:  maxItems,
+	}
 
-	content, err := h.service.GetContent(r.Context(), decodedEndpoint)
+	content, err := h.service.GetContent(r.Context(), input)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte("Failed to get content"))
 		return err
 	}
 
+	if content.Signed {
+		// signed urls expire, a shared cache would keep serving them after that
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	setPersonalizationHeaders(w, content, visitor, isNewVisitor)
+	setFallbackHeaders(w, content)
+	setSurrogateKey(w, content)
+
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(content); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -54,3 +98,136 @@ func (h *HttpHandler) GetContent(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+// decodeEndpoint decodes the endpoint sent as a path segment, accepting the
+// standard and the URL-safe base64 alphabets, with or without padding.
+func decodeEndpoint(value string) (EndpointDto, error) {
+	var lastErr error
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawURLEncoding, base64.RawStdEncoding} {
+		decoded, err := enc.DecodeString(value)
+		if err == nil {
+			return NewEndpointDto(string(decoded)), nil
+		}
+		lastErr = err
+	}
+
+	return "", lastErr
+}
+
+// setFallbackHeaders keeps every cache from storing content that fell back:
+// the video is served again once its link is fixed. It comes last, as it
+// takes precedence over the other Cache-Control values.
+func setFallbackHeaders(w http.ResponseWriter, content ContentOutputDto) {
+	if content.FellBack {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+}
+
+// setPersonalizationHeaders keeps shared caches from storing content that depends
+// on the visitor (A/B variant, region, shuffled playlist) and persists a newly
+// generated visitor id.
+func setPersonalizationHeaders(w http.ResponseWriter, content ContentOutputDto, visitor string, isNewVisitor bool) {
+	if !content.IsPersonalized() {
+		return
+	}
+
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Vary", "Cookie, "+VisitorIdHeader+", "+RegionOverrideHeader+", X-Forwarded-For")
+	if isNewVisitor {
+		setVisitorCookie(w, visitor)
+	}
+}
+
+// SurrogateKeyHeader lists the cache tags of a response, separated by spaces,
+// so the caches storing it can invalidate it by tag.
+const SurrogateKeyHeader = "Surrogate-Key"
+
+// setSurrogateKey tags the content with its rule and the catalog videos it
+// joins, so it is invalidated when one of them changes.
+func setSurrogateKey(w http.ResponseWriter, content ContentOutputDto) {
+	if len(content.CacheTags) == 0 {
+		return
+	}
+
+	w.Header().Set(SurrogateKeyHeader, strings.Join(content.CacheTags, " "))
+}
+
+// playlistParams reads the optional "shuffle" and "max_items" query parameters.
+func playlistParams(r *http.Request) (shuffle bool, maxItems int, err error) {
+	query := r.URL.Query()
+
+	if v := query.Get("shuffle"); v != "" {
+		if shuffle, err = strconv.ParseBool(v); err != nil {
+			return false, 0, errors.New("invalid shuffle parameter")
+		}
+	}
+
+	if v := query.Get("max_items"); v != "" {
+		if maxItems, err = strconv.Atoi(v); err != nil || maxItems < 1 {
+			return false, 0, errors.New("invalid max_items parameter")
+		}
+	}
+
+	return shuffle, maxItems, nil
+}
+
+var captionFilePattern = regexp.MustCompile(`^([0-9a-f]{32})\.vtt$`)
+
+func (h *HttpHandler) GetCaption(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	match := captionFilePattern.FindStringSubmatch(r.PathValue("file"))
+	if match == nil {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	// tracks are content addressed, the id changes whenever the body changes
+	etag := `"` + match[1] + `"`
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	track, err := h.service.GetCaption(r.Context(), match[1])
+	if errors.Is(err, ErrCaptionNotFound) {
+		w.Header().Del("Cache-Control")
+		w.Header().Del("ETag")
+		http.NotFound(w, r)
+		return nil
+	}
+
+	if err != nil {
+		w.Header().Del("Cache-Control")
+		w.Header().Del("ETag")
+		http.Error(w, "Failed to get caption", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, err = io.WriteString(w, track.Body)
+
+	return err
+}
+
+func (h *HttpHandler) region(r *http.Request) entity.RegionCode {
+	if override := entity.NewRegionCode(r.Header.Get(RegionOverrideHeader)); override.IsValid() {
+		return override
+	}
+
+	if h.regions == nil {
+		return ""
+	}
+
+	code, ok := h.regions.Resolve(r)
+	if !ok {
+		return ""
+	}
+
+	return entity.NewRegionCode(code)
+}
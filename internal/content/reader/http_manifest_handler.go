@@ -0,0 +1,134 @@
+package reader
+
+import (
+	"errors"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/pkg/manifest"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	hlsExtension  = ".m3u8"
+	dashExtension = ".mpd"
+)
+
+// GetManifest serves the HLS master playlist ({endpoint}.m3u8) or the DASH
+// MPD ({endpoint}.mpd) built from the renditions of the resolved video.
+func (h *HttpHandler) GetManifest(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	file := r.PathValue("file")
+
+	var encoded string
+	var isHLS bool
+	switch {
+	case strings.HasSuffix(file, hlsExtension):
+		encoded, isHLS = strings.TrimSuffix(file, hlsExtension), true
+	case strings.HasSuffix(file, dashExtension):
+		encoded = strings.TrimSuffix(file, dashExtension)
+	default:
+		http.NotFound(w, r)
+		return nil
+	}
+
+	endpoint, err := decodeEndpoint(encoded)
+	if err != nil {
+		http.Error(w, "Invalid base64 encoding", http.StatusBadRequest)
+		return nil
+	}
+
+	visitor, isNewVisitor := visitorId(r)
+	input := ContentInputDto{
+		Endpoint:  endpoint,
+		VisitorId: visitor,
+		Region:    h.region(r),
+	}
+
+	content, err := h.service.GetContent(r.Context(), input)
+	if err != nil {
+		http.Error(w, "Failed to get content", http.StatusInternalServerError)
+		return err
+	}
+
+	var (
+		body        []byte
+		contentType string
+	)
+	if isHLS {
+		body, err = manifest.HLSMaster(hlsVariants(content.Renditions))
+		contentType = manifest.HLSContentType
+	} else {
+		body, err = manifest.DASH(dashVariants(content.Renditions), duration(content.Video))
+		contentType = manifest.DASHContentType
+	}
+
+	if errors.Is(err, manifest.ErrNoVariants) {
+		http.Error(w, "No renditions for this format", http.StatusNotFound)
+		return nil
+	}
+
+	if err != nil {
+		http.Error(w, "Failed to generate manifest", http.StatusUnprocessableEntity)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "no-cache")
+	if content.Signed {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	setPersonalizationHeaders(w, content, visitor, isNewVisitor)
+	setFallbackHeaders(w, content)
+
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+
+	return err
+}
+
+func hlsVariants(renditions []entity.Rendition) []manifest.Variant {
+	var variants []manifest.Variant
+	for _, r := range renditions {
+		if r.PlaylistUrl == "" {
+			continue
+		}
+		variants = append(variants, toVariant(r, r.PlaylistUrl))
+	}
+
+	return variants
+}
+
+func dashVariants(renditions []entity.Rendition) []manifest.Variant {
+	var variants []manifest.Variant
+	for _, r := range renditions {
+		if r.Url == "" {
+			continue
+		}
+		variants = append(variants, toVariant(r, r.Url))
+	}
+
+	return variants
+}
+
+func toVariant(r entity.Rendition, uri string) manifest.Variant {
+	return manifest.Variant{
+		Uri:       uri,
+		Bandwidth: r.Bandwidth,
+		Width:     r.Width,
+		Height:    r.Height,
+		Codecs:    r.Codecs,
+		FrameRate: r.FrameRate,
+		MimeType:  r.MimeType,
+	}
+}
+
+func duration(video entity.Video) time.Duration {
+	if video.Metadata == nil {
+		return 0
+	}
+
+	return time.Duration(video.Metadata.DurationSeconds * float64(time.Second))
+}
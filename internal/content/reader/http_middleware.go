@@ -2,19 +2,26 @@ package reader
 
 import (
 	"encoding/json"
-	"github.com/IsaacDSC/search_content/pkg/cache"
 	"log"
 	"net/http"
 	"strings"
 )
 
+// ResponseCache stores the JSON responses by the path and query of their
+// request, e.g. a cache.LRUCache. The tags of a response are those of its
+// SurrogateKeyHeader, which the cache invalidates it by.
+type ResponseCache interface {
+	Get(key string) (map[string]any, error)
+	Set(key string, content map[string]any, tags ...string) error
+}
+
 // CacheMiddleware provides caching capabilities for HTTP handlers
 type CacheMiddleware struct {
-	cache *cache.LRUCache
+	cache ResponseCache
 }
 
 // NewCacheMiddleware creates a new cache middleware
-func NewCacheMiddleware(cache *cache.LRUCache) *CacheMiddleware {
+func NewCacheMiddleware(cache ResponseCache) *CacheMiddleware {
 	return &CacheMiddleware{
 		cache: cache,
 	}
@@ -48,13 +55,19 @@ func (m *CacheMiddleware) WithCache(next http.HandlerFunc) http.HandlerFunc {
 		// Execute the original handler
 		next(crw, r)
 
-		// If successful response, store in cache
-		if crw.statusCode >= 200 && crw.statusCode < 300 && len(crw.body) > 0 {
-			body := make(map[string]any)
-			if err := json.Unmarshal(crw.body, &body); err != nil {
-				log.Println("[WARNING] Failed to unmarshal response body:", err)
-			}
-			m.cache.Set(cacheKey, body)
+		// Only successful JSON responses shared by every visitor are stored
+		if !crw.cacheable || crw.statusCode < 200 || crw.statusCode >= 300 || len(crw.body) == 0 {
+			return
+		}
+
+		body := make(map[string]any)
+		if err := json.Unmarshal(crw.body, &body); err != nil {
+			log.Println("[WARNING] Failed to unmarshal response body:", err)
+			return
+		}
+		tags := strings.Fields(crw.Header().Get(SurrogateKeyHeader))
+		if err := m.cache.Set(cacheKey, body, tags...); err != nil {
+			log.Println("[WARNING] Failed to cache response:", err)
 		}
 	}
 }
@@ -68,11 +81,15 @@ func generateCacheKey(r *http.Request) string {
 	return strings.TrimPrefix(key, "/")
 }
 
-// captureResponseWriter is a wrapper that captures response data
+// captureResponseWriter is a wrapper that captures response data.
+// The body is only buffered when the response can be cached, so
+// files streamed by other handlers are not kept in memory.
 type captureResponseWriter struct {
 	http.ResponseWriter
-	statusCode int
-	body       []byte
+	statusCode  int
+	body        []byte
+	cacheable   bool
+	wroteHeader bool
 }
 
 // newCaptureResponseWriter creates a new response writer wrapper
@@ -85,12 +102,31 @@ func newCaptureResponseWriter(w http.ResponseWriter) *captureResponseWriter {
 
 // Write captures the response body
 func (crw *captureResponseWriter) Write(b []byte) (int, error) {
-	crw.body = append(crw.body, b...)
+	if !crw.wroteHeader {
+		crw.WriteHeader(http.StatusOK)
+	}
+
+	if crw.cacheable {
+		crw.body = append(crw.body, b...)
+	}
 	return crw.ResponseWriter.Write(b)
 }
 
-// WriteHeader captures the status code
+// WriteHeader captures the status code and decides whether the response can be cached
 func (crw *captureResponseWriter) WriteHeader(statusCode int) {
-	crw.statusCode = statusCode
+	if !crw.wroteHeader {
+		crw.wroteHeader = true
+		crw.statusCode = statusCode
+		crw.cacheable = isCacheable(crw.Header())
+	}
 	crw.ResponseWriter.WriteHeader(statusCode)
 }
+
+// isCacheable reports whether the response is JSON and not personalized (e.g. A/B variants)
+// nor marked as never to be stored
+func isCacheable(header http.Header) bool {
+	cacheControl := header.Get("Cache-Control")
+	return strings.HasPrefix(header.Get("Content-Type"), "application/json") &&
+		!strings.Contains(cacheControl, "private") &&
+		!strings.Contains(cacheControl, "no-store")
+}
@@ -0,0 +1,204 @@
+package reader
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/builder"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// memoryCache is a ResponseCache without expiration.
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]map[string]any
+	tags  map[string][]string
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{items: map[string]map[string]any{}, tags: map[string][]string{}}
+}
+
+func (c *memoryCache) Get(key string) (map[string]any, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	content, ok := c.items[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+
+	return content, nil
+}
+
+func (c *memoryCache) Set(key string, content map[string]any, tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = content
+	for _, tag := range tags {
+		c.tags[tag] = append(c.tags[tag], key)
+	}
+	return nil
+}
+
+func (c *memoryCache) Invalidate(tags ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		for _, key := range c.tags[tag] {
+			delete(c.items, key)
+		}
+		delete(c.tags, tag)
+	}
+	return nil
+}
+
+func TestCacheMiddleware_GetContent(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+	video := entity.Video{VideoUrl: "https://cdn.example.com/a.mp4", TambnailUrl: "https://cdn.example.com/a.jpg"}
+	regional := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).
+		WithRegions(map[entity.RegionCode]entity.Video{"BR": {VideoUrl: "https://cdn.example.com/br.mp4", TambnailUrl: "https://cdn.example.com/br.jpg"}}).
+		Build()
+	static := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).Build()
+
+	serve := func(t *testing.T, rule entity.Enterprise) func(header http.Header) *httptest.ResponseRecorder {
+		ctrl := gomock.NewController(t)
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+			Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil).AnyTimes()
+
+		handler := NewHandler(NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, nil), nil, Placeholders{}, nil, "")
+		cache := NewCacheMiddleware(newMemoryCache())
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /content/{endpoint}", cache.WithCache(func(w http.ResponseWriter, r *http.Request) { handler.GetContent(w, r) }))
+
+		path := "/content/" + base64.URLEncoding.EncodeToString([]byte(endpoint.String()))
+		return func(header http.Header) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			for k, v := range header {
+				req.Header[k] = v
+			}
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			return rec
+		}
+	}
+
+	t.Run("regional rule is never cached", func(t *testing.T) {
+		get := serve(t, regional)
+
+		// the visitor out of the regions gets the default video, privately
+		generic := get(nil)
+		assert.Equal(t, http.StatusOK, generic.Code)
+		assert.Equal(t, "private, no-cache", generic.Header().Get("Cache-Control"))
+		assert.NotEmpty(t, generic.Header().Get("Vary"))
+		assert.Contains(t, generic.Body.String(), video.VideoUrl)
+
+		brazilian := get(http.Header{RegionOverrideHeader: {"BR"}})
+		assert.Equal(t, http.StatusOK, brazilian.Code)
+		assert.Empty(t, brazilian.Header().Get("X-Cache"))
+		assert.NotEqual(t, generic.Body.String(), brazilian.Body.String())
+		assert.Contains(t, brazilian.Body.String(), "https://cdn.example.com/br.mp4")
+	})
+
+	t.Run("static rule is cached", func(t *testing.T) {
+		get := serve(t, static)
+
+		first := get(nil)
+		assert.Equal(t, "no-cache", first.Header().Get("Cache-Control"))
+
+		second := get(nil)
+		assert.Equal(t, "HIT", second.Header().Get("X-Cache"))
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+	})
+}
+
+func TestCacheMiddleware_InvalidateCatalogVideo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	endpoint, _ := url.Parse("https://example.com/home")
+	rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(entity.Video{CatalogId: "intro"}).Build()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+		Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil).AnyTimes()
+
+	mockCatalog := NewMockCatalogRepository(ctrl)
+	gomock.InOrder(
+		mockCatalog.EXPECT().GetVideo(gomock.Any(), "intro").
+			Return(entity.CatalogVideo{Id: "intro", Video: entity.Video{VideoUrl: "https://cdn.example.com/v1.mp4"}}, nil),
+		mockCatalog.EXPECT().GetVideo(gomock.Any(), "intro").
+			Return(entity.CatalogVideo{Id: "intro", Video: entity.Video{VideoUrl: "https://cdn.example.com/v2.mp4"}}, nil),
+	)
+
+	handler := NewHandler(NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, nil), nil, Placeholders{}, nil, "")
+	responses := newMemoryCache()
+	cache := NewCacheMiddleware(responses)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /content/{endpoint}", cache.WithCache(func(w http.ResponseWriter, r *http.Request) { handler.GetContent(w, r) }))
+
+	path := "/content/" + base64.URLEncoding.EncodeToString([]byte(endpoint.String()))
+	get := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	first := get()
+	assert.Equal(t, "rule/example.com/home video/intro", first.Header().Get(SurrogateKeyHeader))
+	assert.Contains(t, first.Body.String(), "v1.mp4")
+	assert.Equal(t, "HIT", get().Header().Get("X-Cache"))
+
+	// the catalog video changed
+	assert.NoError(t, responses.Invalidate(entity.CatalogCacheTag("intro")))
+
+	updated := get()
+	assert.Empty(t, updated.Header().Get("X-Cache"))
+	assert.Contains(t, updated.Body.String(), "v2.mp4")
+}
+
+func TestCacheMiddleware_FallbackIsNotCached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	endpoint, _ := url.Parse("https://example.com/home")
+	broken := entity.Video{VideoUrl: "https://cdn.example.com/deleted.mp4", TambnailUrl: "https://cdn.example.com/a.jpg"}
+	fallback := entity.Video{VideoUrl: "https://cdn.example.com/backup.mp4", TambnailUrl: "https://cdn.example.com/backup.jpg"}
+	rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(broken).WithFallback(&fallback).Build()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+		Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil).AnyTimes()
+
+	mockLinks := NewMockLinkStatus(ctrl)
+	mockLinks.EXPECT().IsBroken(broken.VideoUrl).Return(true).AnyTimes()
+
+	handler := NewHandler(NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, mockLinks), nil, Placeholders{}, nil, "")
+	cache := NewCacheMiddleware(newMemoryCache())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /content/{endpoint}", cache.WithCache(func(w http.ResponseWriter, r *http.Request) { handler.GetContent(w, r) }))
+
+	path := "/content/" + base64.URLEncoding.EncodeToString([]byte(endpoint.String()))
+	for range 2 {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		assert.Empty(t, rec.Header().Get("X-Cache"))
+		assert.Contains(t, rec.Body.String(), fallback.VideoUrl)
+	}
+}
@@ -0,0 +1,79 @@
+package reader
+
+import (
+	"errors"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"net/http"
+)
+
+// redirectMaxAge is how long a shared redirect can be cached, short enough
+// for a new registration of the endpoint to show up quickly.
+const redirectMaxAge = "60"
+
+// Placeholders are the urls redirected to when an endpoint has no content.
+// An empty url makes the redirect answer 404 instead.
+type Placeholders struct {
+	Video     string
+	Thumbnail string
+}
+
+// RedirectVideo answers 302 to the video resolved for the endpoint,
+// so it can be used directly as the src of a <video> tag.
+func (h *HttpHandler) RedirectVideo(w http.ResponseWriter, r *http.Request) error {
+	return h.redirect(w, r, h.placeholders.Video, func(v entity.Video) string { return v.VideoUrl })
+}
+
+// RedirectThumbnail answers 302 to the thumbnail resolved for the endpoint,
+// so it can be used directly as the src of an <img> tag.
+func (h *HttpHandler) RedirectThumbnail(w http.ResponseWriter, r *http.Request) error {
+	return h.redirect(w, r, h.placeholders.Thumbnail, func(v entity.Video) string { return v.TambnailUrl })
+}
+
+func (h *HttpHandler) redirect(w http.ResponseWriter, r *http.Request, placeholder string, location func(entity.Video) string) error {
+	defer r.Body.Close()
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	endpoint, err := decodeEndpoint(r.PathValue("endpoint"))
+	if err != nil {
+		http.Error(w, "Invalid base64 encoding", http.StatusBadRequest)
+		return nil
+	}
+
+	visitor, isNewVisitor := visitorId(r)
+	input := ContentInputDto{
+		Endpoint:  endpoint,
+		VisitorId: visitor,
+		Region:    h.region(r),
+	}
+
+	content, err := h.service.GetContent(r.Context(), input)
+	if err != nil && !errors.Is(err, ErrContentNotFound) {
+		http.Error(w, "Failed to get content", http.StatusInternalServerError)
+		return err
+	}
+
+	target := location(content.Video)
+	if target == "" && placeholder == "" {
+		w.Header().Set("Cache-Control", "no-cache")
+		http.NotFound(w, r)
+		return nil
+	}
+
+	if target == "" {
+		target = placeholder
+	}
+
+	// only the redirects of static rules are shared: a CDN would serve the
+	// region or the variant of the first visitor to everyone else
+	w.Header().Set("Cache-Control", "public, max-age="+redirectMaxAge)
+	if content.Signed {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	setPersonalizationHeaders(w, content, visitor, isNewVisitor)
+	setFallbackHeaders(w, content)
+
+	http.Redirect(w, r, target, http.StatusFound)
+
+	return nil
+}
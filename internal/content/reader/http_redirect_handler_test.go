@@ -0,0 +1,155 @@
+package reader
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/builder"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHttpHandler_Redirect(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+	video := entity.Video{VideoUrl: "https://cdn.example.com/a.mp4", TambnailUrl: "https://cdn.example.com/a.jpg"}
+	rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).Build()
+	placeholders := Placeholders{Thumbnail: "https://cdn.example.com/placeholder.jpg"}
+
+	tests := []struct {
+		name         string
+		path         string
+		header       http.Header
+		rule         *entity.Enterprise
+		wantStatus   int
+		wantLocation string
+		wantCache    string
+		wantVary     bool
+	}{
+		{
+			name:         "video",
+			path:         "/v/",
+			rule:         &rule,
+			wantStatus:   http.StatusFound,
+			wantLocation: video.VideoUrl,
+			wantCache:    "public, max-age=60",
+		},
+		{
+			name:         "thumbnail",
+			path:         "/t/",
+			rule:         &rule,
+			wantStatus:   http.StatusFound,
+			wantLocation: video.TambnailUrl,
+			wantCache:    "public, max-age=60",
+		},
+		{
+			name: "regional thumbnail is private",
+			path: "/t/",
+			rule: func() *entity.Enterprise {
+				r := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).
+					WithRegions(map[entity.RegionCode]entity.Video{"BR": {VideoUrl: "https://cdn.example.com/br.mp4", TambnailUrl: "https://cdn.example.com/br.jpg"}}).
+					Build()
+				return &r
+			}(),
+			header:       http.Header{RegionOverrideHeader: {"BR-SP"}},
+			wantStatus:   http.StatusFound,
+			wantLocation: "https://cdn.example.com/br.jpg",
+			wantCache:    "private, no-cache",
+			wantVary:     true,
+		},
+		{
+			name: "regional video out of the regions is private",
+			path: "/v/",
+			rule: func() *entity.Enterprise {
+				r := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).
+					WithRegions(map[entity.RegionCode]entity.Video{"BR": {VideoUrl: "https://cdn.example.com/br.mp4"}}).
+					Build()
+				return &r
+			}(),
+			header:       http.Header{RegionOverrideHeader: {"US"}},
+			wantStatus:   http.StatusFound,
+			wantLocation: video.VideoUrl,
+			wantCache:    "private, no-cache",
+			wantVary:     true,
+		},
+		{
+			name: "placeholder of a regional rule is private",
+			path: "/t/",
+			rule: func() *entity.Enterprise {
+				r := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(entity.Video{VideoUrl: video.VideoUrl}).
+					WithRegions(map[entity.RegionCode]entity.Video{"BR": {VideoUrl: "https://cdn.example.com/br.mp4", TambnailUrl: "https://cdn.example.com/br.jpg"}}).
+					Build()
+				return &r
+			}(),
+			wantStatus:   http.StatusFound,
+			wantLocation: placeholders.Thumbnail,
+			wantCache:    "private, no-cache",
+			wantVary:     true,
+		},
+		{
+			name: "experiment video is private",
+			path: "/v/",
+			rule: func() *entity.Enterprise {
+				r := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).Build()
+				r.Experiment = &entity.Experiment{Id: "home", Variants: []entity.Variant{
+					{Id: "a", Weight: 1, Video: entity.Video{VideoUrl: "https://cdn.example.com/variant.mp4"}},
+				}}
+				return &r
+			}(),
+			wantStatus:   http.StatusFound,
+			wantLocation: "https://cdn.example.com/variant.mp4",
+			wantCache:    "private, no-cache",
+			wantVary:     true,
+		},
+		{
+			name:         "missing thumbnail redirects to placeholder",
+			path:         "/t/",
+			wantStatus:   http.StatusFound,
+			wantLocation: placeholders.Thumbnail,
+			wantCache:    "public, max-age=60",
+		},
+		{
+			name:       "missing video without placeholder",
+			path:       "/v/",
+			wantStatus: http.StatusNotFound,
+			wantCache:  "no-cache",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			data := EnterpriseData{}
+			if tt.rule != nil {
+				data = NewEnterprisesData(entity.NewPathKey(endpoint), *tt.rule)
+			}
+
+			mockRepo := NewMockRepository(ctrl)
+			mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).Return(data, nil)
+
+			handler := NewHandler(NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, nil), nil, placeholders, nil, "")
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /v/{endpoint}", func(w http.ResponseWriter, r *http.Request) { handler.RedirectVideo(w, r) })
+			mux.HandleFunc("GET /t/{endpoint}", func(w http.ResponseWriter, r *http.Request) { handler.RedirectThumbnail(w, r) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.path+base64.URLEncoding.EncodeToString([]byte(endpoint.String())), nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+
+			mux.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantLocation, rec.Header().Get("Location"))
+			assert.Equal(t, tt.wantCache, rec.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.wantVary, rec.Header().Get("Vary") != "")
+		})
+	}
+}
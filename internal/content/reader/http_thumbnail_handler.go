@@ -0,0 +1,158 @@
+package reader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/pkg/thumbnail"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// GetThumbnail serves the thumbnail of the endpoint resized to the query
+// parameters w, h, fit (cover, contain or fill), q (JPEG quality) and
+// format (jpeg or png). Only thumbnails uploaded as assets are resized,
+// any other thumbnail is redirected to as is.
+func (h *HttpHandler) GetThumbnail(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	endpoint, err := decodeEndpoint(r.PathValue("endpoint"))
+	if err != nil {
+		http.Error(w, "Invalid base64 encoding", http.StatusBadRequest)
+		return nil
+	}
+
+	opts, err := thumbnailOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	visitor, isNewVisitor := visitorId(r)
+	input := ContentInputDto{
+		Endpoint:  endpoint,
+		VisitorId: visitor,
+		Region:    h.region(r),
+	}
+
+	content, err := h.service.GetContent(r.Context(), input)
+	if err != nil && !errors.Is(err, ErrContentNotFound) {
+		http.Error(w, "Failed to get content", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "public, max-age="+redirectMaxAge)
+	if content.Signed {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+	setPersonalizationHeaders(w, content, visitor, isNewVisitor)
+	setFallbackHeaders(w, content)
+
+	if content.TambnailUrl == "" {
+		if h.placeholders.Thumbnail == "" {
+			http.NotFound(w, r)
+			return nil
+		}
+
+		http.Redirect(w, r, h.placeholders.Thumbnail, http.StatusFound)
+		return nil
+	}
+
+	assetId, ok := entity.AssetIdFromUrl(content.TambnailUrl, h.publicBaseUrl)
+	if !ok {
+		http.Redirect(w, r, content.TambnailUrl, http.StatusFound)
+		return nil
+	}
+
+	key := assetId + "/" + opts.Key()
+	sum := sha256.Sum256([]byte(key))
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	w.Header().Set("ETag", etag)
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	body, contentType, err := h.resizeThumbnail(r, assetId, key, opts)
+	if errors.Is(err, ErrAssetNotFound) {
+		w.Header().Del("ETag")
+		http.Redirect(w, r, content.TambnailUrl, http.StatusFound)
+		return nil
+	}
+
+	if err != nil {
+		w.Header().Del("ETag")
+		log.Printf("failed to resize thumbnail %s: %v", assetId, err)
+		http.Error(w, "Failed to resize thumbnail", http.StatusUnprocessableEntity)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(body)
+
+	return err
+}
+
+// resizeThumbnail returns the resized asset, from the cache when it was
+// already derived. The content type is kept as the first byte of the
+// cached data, so a cache hit needs no decoding.
+func (h *HttpHandler) resizeThumbnail(r *http.Request, assetId, key string, opts thumbnail.Options) ([]byte, string, error) {
+	if h.thumbnails != nil {
+		if data, ok := h.thumbnails.Get(key); ok && len(data) > 1 {
+			return data[1:], formatsByTag[data[0]].ContentType(), nil
+		}
+	}
+
+	_, file, err := h.service.GetAsset(r.Context(), assetId)
+	if err != nil {
+		return nil, "", err
+	}
+	defer file.Close()
+
+	body, format, err := thumbnail.Process(file, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if h.thumbnails != nil {
+		if err := h.thumbnails.Set(key, append([]byte{tagsByFormat[format]}, body...)); err != nil {
+			log.Printf("failed to cache thumbnail %s: %v", key, err)
+		}
+	}
+
+	return body, format.ContentType(), nil
+}
+
+var (
+	tagsByFormat = map[thumbnail.Format]byte{thumbnail.FormatJPEG: 'j', thumbnail.FormatPNG: 'p'}
+	formatsByTag = map[byte]thumbnail.Format{'j': thumbnail.FormatJPEG, 'p': thumbnail.FormatPNG}
+)
+
+func thumbnailOptions(r *http.Request) (thumbnail.Options, error) {
+	query := r.URL.Query()
+
+	var opts thumbnail.Options
+	for name, target := range map[string]*int{"w": &opts.Width, "h": &opts.Height, "q": &opts.Quality} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return thumbnail.Options{}, errors.New("invalid " + name + " parameter")
+		}
+		*target = n
+	}
+
+	opts.Fit = thumbnail.Fit(query.Get("fit"))
+	opts.Format = thumbnail.Format(query.Get("format"))
+
+	return opts.Validate(thumbnail.DefaultSizes)
+}
@@ -0,0 +1,138 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/builder"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type memoryThumbnailCache map[string][]byte
+
+func (c memoryThumbnailCache) Get(key string) ([]byte, bool) {
+	data, ok := c[key]
+	return data, ok
+}
+
+func (c memoryThumbnailCache) Set(key string, data []byte) error {
+	c[key] = data
+	return nil
+}
+
+func TestHttpHandler_GetThumbnail(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+	assetId := strings.Repeat("a", 64)
+
+	var source bytes.Buffer
+	require.NoError(t, png.Encode(&source, image.NewNRGBA(image.Rect(0, 0, 800, 400))))
+
+	local := builder.NewEnterpriseBuilder().WithUrl(endpoint).
+		WithVideo(entity.Video{VideoUrl: "https://cdn.example.com/a.mp4", TambnailUrl: "https://content.example.com/assets/" + assetId}).Build()
+	external := builder.NewEnterpriseBuilder().WithUrl(endpoint).
+		WithVideo(entity.Video{VideoUrl: "https://cdn.example.com/a.mp4", TambnailUrl: "https://cdn.example.com/a.jpg"}).Build()
+
+	path := "/thumb/" + base64.URLEncoding.EncodeToString([]byte(endpoint.String()))
+
+	newServer := func(t *testing.T, rule entity.Enterprise, cache ThumbnailCache, assetReads int) *http.ServeMux {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+			Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil).AnyTimes()
+
+		mockAssets := NewMockAssetRepository(ctrl)
+		if assetReads > 0 {
+			mockAssets.EXPECT().GetAsset(gomock.Any(), assetId).
+				Return(entity.Asset{Id: assetId}, nopSeekCloser{bytes.NewReader(source.Bytes())}, nil).
+				Times(assetReads)
+		}
+
+		handler := NewHandler(NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), mockAssets, nil, nil, nil), nil, Placeholders{}, cache, "https://content.example.com")
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /thumb/{endpoint}", func(w http.ResponseWriter, r *http.Request) { handler.GetThumbnail(w, r) })
+		return mux
+	}
+
+	get := func(mux *http.ServeMux, query string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path+query, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("resizes once and serves from cache", func(t *testing.T) {
+		mux := newServer(t, local, memoryThumbnailCache{}, 1)
+
+		for i := 0; i < 2; i++ {
+			rec := get(mux, "?w=200&fit=contain", nil)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+
+			img, err := png.Decode(rec.Body)
+			require.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 200, 100), img.Bounds())
+		}
+	})
+
+	t.Run("jpeg output and etag", func(t *testing.T) {
+		mux := newServer(t, local, nil, 1)
+
+		rec := get(mux, "?w=100&h=100&format=jpeg&q=60", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/jpeg", rec.Header().Get("Content-Type"))
+
+		rec = get(mux, "?w=100&h=100&format=jpeg&q=60", http.Header{"If-None-Match": {rec.Header().Get("ETag")}})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+	})
+
+	t.Run("external thumbnail is redirected", func(t *testing.T) {
+		rec := get(newServer(t, external, nil, 0), "?w=200", nil)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://cdn.example.com/a.jpg", rec.Header().Get("Location"))
+	})
+
+	t.Run("fallback thumbnail is not cached", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+
+		broken := entity.Video{VideoUrl: "https://cdn.example.com/deleted.mp4", TambnailUrl: "https://cdn.example.com/a.jpg"}
+		fallback := entity.Video{VideoUrl: "https://cdn.example.com/backup.mp4", TambnailUrl: "https://cdn.example.com/backup.jpg"}
+		rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(broken).WithFallback(&fallback).Build()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+			Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil).AnyTimes()
+
+		mockLinks := NewMockLinkStatus(ctrl)
+		mockLinks.EXPECT().IsBroken(broken.VideoUrl).Return(true).AnyTimes()
+
+		handler := NewHandler(NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, mockLinks), nil, Placeholders{}, nil, "https://content.example.com")
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /thumb/{endpoint}", func(w http.ResponseWriter, r *http.Request) { handler.GetThumbnail(w, r) })
+
+		rec := get(mux, "?w=200", nil)
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, fallback.TambnailUrl, rec.Header().Get("Location"))
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("size outside the allowlist", func(t *testing.T) {
+		rec := get(newServer(t, local, nil, 0), "?w=201", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
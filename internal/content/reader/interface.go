@@ -3,9 +3,49 @@ package reader
 import (
 	"context"
 	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"io"
+	"net/http"
 )
 
 type Repository interface {
 	Save(ctx context.Context, enterprise entity.Enterprise) error
 	Get(ctx context.Context, enterpriseKey entity.EnterpriseKey) (EnterpriseData, error)
 }
+
+type CaptionRepository interface {
+	// GetCaption returns ErrCaptionNotFound when there is no track with the id.
+	GetCaption(ctx context.Context, id string) (entity.CaptionTrack, error)
+}
+
+type AssetRepository interface {
+	// GetAsset returns ErrAssetNotFound when there is no asset with the id.
+	// The caller must close the returned file.
+	GetAsset(ctx context.Context, id string) (entity.Asset, io.ReadSeekCloser, error)
+}
+
+type CatalogRepository interface {
+	// GetVideo returns ErrVideoNotFound when there is no catalog video with the id.
+	GetVideo(ctx context.Context, id string) (entity.CatalogVideo, error)
+}
+
+// RegionResolver finds out the region code ("BR" or "BR-SP") of the client that sent the request.
+type RegionResolver interface {
+	Resolve(r *http.Request) (string, bool)
+}
+
+// UrlSigner signs the media urls of an enterprise. ok is false when the
+// enterprise has no signing configuration and the url must be served as is.
+type UrlSigner interface {
+	Sign(enterprise entity.EnterpriseKey, rawUrl string) (signed string, ok bool, err error)
+}
+
+// ThumbnailCache keeps the resized thumbnails, keyed by asset and options.
+type ThumbnailCache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, data []byte) error
+}
+
+// LinkStatus reports the urls the dead link checker found broken.
+type LinkStatus interface {
+	IsBroken(rawUrl string) bool
+}
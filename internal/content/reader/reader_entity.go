@@ -19,9 +19,14 @@ func (ed EnterpriseData) Append(pathKey entity.PathKey, data entity.Enterprise)
 }
 
 func (ed EnterpriseData) GetContent(input entity.PathKey) (entity.Video, bool) {
+	rule, found := ed.GetRule(input)
+	return rule.Video, found
+}
+
+func (ed EnterpriseData) GetRule(input entity.PathKey) (entity.Enterprise, bool) {
 	content, found := ed[input]
 	if found {
-		return content.Video, true
+		return content, true
 	}
 
 	inputPaths := input.ToListPaths()
@@ -55,9 +60,9 @@ func (ed EnterpriseData) GetContent(input entity.PathKey) (entity.Video, bool) {
 		}
 
 		if totalMatch == length && (len(paths) != 0 && len(inputPaths) != 0) {
-			return v.Video, true
+			return v, true
 		}
 	}
 
-	return entity.Video{}, false
+	return entity.Enterprise{}, false
 }
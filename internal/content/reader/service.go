@@ -2,38 +2,222 @@ package reader
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"io"
+	"math/rand/v2"
 )
 
 type Service interface {
-	GetContent(ctx context.Context, endpoint EndpointDto) (entity.Video, error)
+	GetContent(ctx context.Context, input ContentInputDto) (ContentOutputDto, error)
+	GetCaption(ctx context.Context, id string) (entity.CaptionTrack, error)
+	GetAsset(ctx context.Context, id string) (entity.Asset, io.ReadSeekCloser, error)
+	GetVideo(ctx context.Context, id string) (entity.CatalogVideo, error)
 }
 
 type ContentUseCase struct {
 	repository Repository
+	captions   CaptionRepository
+	assets     AssetRepository
+	catalog    CatalogRepository
+	signer     UrlSigner
+	links      LinkStatus
 }
 
-func NewContentUseCase(repository Repository) *ContentUseCase {
-	return &ContentUseCase{repository: repository}
+// NewContentUseCase creates the reader use case. signer may be nil
+// when no enterprise requires signed urls, and links when dead links
+// are not checked.
+func NewContentUseCase(repository Repository, captions CaptionRepository, assets AssetRepository, catalog CatalogRepository, signer UrlSigner, links LinkStatus) *ContentUseCase {
+	return &ContentUseCase{repository: repository, captions: captions, assets: assets, catalog: catalog, signer: signer, links: links}
 }
 
-func (s ContentUseCase) GetContent(ctx context.Context, endpoint EndpointDto) (entity.Video, error) {
-	url, err := endpoint.ToDomain()
+func (s ContentUseCase) GetContent(ctx context.Context, input ContentInputDto) (ContentOutputDto, error) {
+	url, err := input.Endpoint.ToDomain()
 	if err != nil {
-		return entity.Video{}, err
+		return ContentOutputDto{}, err
 	}
 
 	key := entity.NewEnterpriseKey(url)
 	data, err := s.repository.Get(ctx, key)
 	if err != nil {
-		return entity.Video{}, err
+		return ContentOutputDto{}, err
 	}
 
-	content, found := data.GetContent(entity.NewPathKey(url))
+	rule, found := data.GetRule(entity.NewPathKey(url))
 	if !found {
-		return entity.Video{}, errors.New("content not found")
+		return ContentOutputDto{}, ErrContentNotFound
 	}
 
-	return content, nil
+	if rule, err = JoinCatalog(ctx, s.catalog, rule); err != nil {
+		return ContentOutputDto{}, err
+	}
+
+	output := ContentOutputDto{Video: rule.Video, Varies: rule.Varies(), CacheTags: cacheTags(rule)}
+
+	// a regional video takes precedence over the experiment of the rule
+	var selected *entity.Video
+	if video, region, ok := rule.RegionVideo(input.Region); ok {
+		output.Video = video
+		output.Region = region
+		selected = &video
+	} else if rule.Experiment != nil && input.VisitorId != "" {
+		if variant, ok := rule.Experiment.Assign(input.VisitorId); ok {
+			output.Video = variant.Video
+			output.ExperimentId = rule.Experiment.Id
+			output.VariantId = variant.Id
+			selected = &variant.Video
+		}
+	}
+
+	output.Playlist = newPlaylist(rule, selected, input)
+	output.Shuffled = input.Shuffle && len(output.Playlist) > 1
+
+	s.fallback(rule, &output)
+
+	if err := s.sign(key, &output); err != nil {
+		return ContentOutputDto{}, err
+	}
+
+	return output, nil
+}
+
+// fallback replaces the videos found broken by the dead link checker
+// by the backup video of the rule, when it has one.
+func (s ContentUseCase) fallback(rule entity.Enterprise, output *ContentOutputDto) {
+	if s.links == nil || rule.Fallback == nil {
+		return
+	}
+
+	isBroken := func(video entity.Video) bool {
+		return s.links.IsBroken(video.VideoUrl) || s.links.IsBroken(video.TambnailUrl)
+	}
+
+	if isBroken(output.Video) {
+		output.Video = *rule.Fallback
+		output.FellBack = true
+	}
+
+	for i := range output.Playlist {
+		if isBroken(output.Playlist[i]) {
+			output.Playlist[i] = *rule.Fallback
+			output.FellBack = true
+		}
+	}
+}
+
+func cacheTags(rule entity.Enterprise) []string {
+	tags := []string{entity.RuleCacheTag(rule.Url)}
+	for _, id := range rule.CatalogIds() {
+		tags = append(tags, entity.CatalogCacheTag(id))
+	}
+
+	return tags
+}
+
+// sign rewrites the media urls of the output into signed urls. Videos are
+// copied first because their renditions are shared with the stored rule.
+// Each video is signed on its own: the playlist items (or the fallback)
+// may be hosted where the main video isn't.
+func (s ContentUseCase) sign(key entity.EnterpriseKey, output *ContentOutputDto) error {
+	if s.signer == nil {
+		return nil
+	}
+
+	video, ok, err := s.signVideo(key, output.Video)
+	if err != nil {
+		return err
+	}
+	output.Video = video
+	output.Signed = ok
+
+	for i := range output.Playlist {
+		if output.Playlist[i], ok, err = s.signVideo(key, output.Playlist[i]); err != nil {
+			return err
+		}
+		output.Signed = output.Signed || ok
+	}
+
+	return nil
+}
+
+func (s ContentUseCase) signVideo(key entity.EnterpriseKey, video entity.Video) (entity.Video, bool, error) {
+	var signed bool
+	signUrl := func(rawUrl *string) error {
+		if *rawUrl == "" {
+			return nil
+		}
+
+		value, ok, err := s.signer.Sign(key, *rawUrl)
+		if err != nil {
+			return fmt.Errorf("failed to sign url: %w", err)
+		}
+
+		if ok {
+			*rawUrl, signed = value, true
+		}
+
+		return nil
+	}
+
+	if err := signUrl(&video.VideoUrl); err != nil {
+		return entity.Video{}, false, err
+	}
+
+	if err := signUrl(&video.TambnailUrl); err != nil {
+		return entity.Video{}, false, err
+	}
+
+	renditions := make([]entity.Rendition, len(video.Renditions))
+	copy(renditions, video.Renditions)
+	for i := range renditions {
+		if err := signUrl(&renditions[i].Url); err != nil {
+			return entity.Video{}, false, err
+		}
+
+		if err := signUrl(&renditions[i].PlaylistUrl); err != nil {
+			return entity.Video{}, false, err
+		}
+	}
+
+	if video.Renditions != nil {
+		video.Renditions = renditions
+	}
+
+	return video, signed, nil
+}
+
+// newPlaylist copies the items of the rule, so shuffling never touches the
+// stored data. The selected video (region or variant), when there is one,
+// replaces the first item; otherwise the playlist is served as stored.
+func newPlaylist(rule entity.Enterprise, selected *entity.Video, input ContentInputDto) []entity.Video {
+	items := rule.Items()
+	playlist := make([]entity.Video, len(items))
+	copy(playlist, items)
+	if selected != nil {
+		playlist[0] = *selected
+	}
+
+	if input.Shuffle {
+		rand.Shuffle(len(playlist), func(i, j int) {
+			playlist[i], playlist[j] = playlist[j], playlist[i]
+		})
+	}
+
+	if input.MaxItems > 0 && input.MaxItems < len(playlist) {
+		playlist = playlist[:input.MaxItems]
+	}
+
+	return playlist
+}
+
+func (s ContentUseCase) GetCaption(ctx context.Context, id string) (entity.CaptionTrack, error) {
+	return s.captions.GetCaption(ctx, id)
+}
+
+func (s ContentUseCase) GetAsset(ctx context.Context, id string) (entity.Asset, io.ReadSeekCloser, error) {
+	return s.assets.GetAsset(ctx, id)
+}
+
+func (s ContentUseCase) GetVideo(ctx context.Context, id string) (entity.CatalogVideo, error) {
+	return s.catalog.GetVideo(ctx, id)
 }
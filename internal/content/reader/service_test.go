@@ -0,0 +1,398 @@
+package reader
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/builder"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestContentUseCase_GetContent(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+
+	videoA := builder.NewVideoBuilder().WithRandomData().Build()
+	videoB := builder.NewVideoBuilder().WithRandomData().Build()
+	control := builder.NewVideoBuilder().WithRandomData().Build()
+
+	experiment := &entity.Experiment{
+		Id: "hero",
+		Variants: []entity.Variant{
+			{Id: "a", Weight: 1, Video: videoA},
+			{Id: "b", Weight: 1, Video: videoB},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		rule       entity.Enterprise
+		input      ContentInputDto
+		assertFunc func(t *testing.T, output ContentOutputDto)
+	}{
+		{
+			name:  "single video",
+			rule:  builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), VisitorId: "visitor"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, control, output.Video)
+				assert.Equal(t, []entity.Video{control}, output.Playlist)
+				assert.False(t, output.IsPersonalized())
+			},
+		},
+		{
+			name: "ordered playlist",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(videoA).
+				WithPlaylist([]entity.Video{videoA, videoB, control}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, videoA, output.Video)
+				assert.Equal(t, []entity.Video{videoA, videoB, control}, output.Playlist)
+				assert.False(t, output.IsPersonalized())
+			},
+		},
+		{
+			name: "plain rule keeps its playlist",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).
+				WithPlaylist([]entity.Video{videoA, videoB}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), VisitorId: "visitor"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, control, output.Video)
+				// the video of the rule doesn't replace the first item
+				assert.Equal(t, []entity.Video{videoA, videoB}, output.Playlist)
+			},
+		},
+		{
+			name: "playlist limited by max items",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(videoA).
+				WithPlaylist([]entity.Video{videoA, videoB, control}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), MaxItems: 2},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, []entity.Video{videoA, videoB}, output.Playlist)
+			},
+		},
+		{
+			name: "shuffled playlist keeps every item",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(videoA).
+				WithPlaylist([]entity.Video{videoA, videoB, control}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), Shuffle: true},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.ElementsMatch(t, []entity.Video{videoA, videoB, control}, output.Playlist)
+				assert.True(t, output.IsPersonalized())
+			},
+		},
+		{
+			name: "regional video replaces the first playlist item",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(videoA).
+				WithPlaylist([]entity.Video{videoA, videoB}).
+				WithRegions(map[entity.RegionCode]entity.Video{"BR": control}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), Region: "BR"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, control, output.Video)
+				assert.Equal(t, []entity.Video{control, videoB}, output.Playlist)
+			},
+		},
+		{
+			name: "experiment assigns a variant",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).
+				WithExperiment(experiment).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), VisitorId: "visitor"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				variant, _ := experiment.Assign("visitor")
+
+				assert.True(t, output.IsPersonalized())
+				assert.Equal(t, "hero", output.ExperimentId)
+				assert.Equal(t, variant.Id, output.VariantId)
+				assert.Equal(t, variant.Video, output.Video)
+			},
+		},
+		{
+			name: "experiment without visitor falls back to the rule video",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).
+				WithExperiment(experiment).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, control, output.Video)
+				assert.Empty(t, output.ExperimentId)
+			},
+		},
+		{
+			name: "region subdivision video",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).
+				WithExperiment(experiment).
+				WithRegions(map[entity.RegionCode]entity.Video{"BR-SP": videoA, "BR": videoB}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), VisitorId: "visitor", Region: "BR-SP"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, videoA, output.Video)
+				assert.Equal(t, entity.RegionCode("BR-SP"), output.Region)
+				assert.Empty(t, output.ExperimentId)
+				assert.True(t, output.IsPersonalized())
+			},
+		},
+		{
+			name: "region falls back to country video",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).
+				WithRegions(map[entity.RegionCode]entity.Video{"BR": videoB}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), Region: "BR-RJ"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, videoB, output.Video)
+				assert.Equal(t, entity.RegionCode("BR"), output.Region)
+			},
+		},
+		{
+			name: "region without video uses the rule video",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(control).
+				WithRegions(map[entity.RegionCode]entity.Video{"BR": videoB}).Build(),
+			input: ContentInputDto{Endpoint: NewEndpointDto(endpoint.String()), Region: "US-CA"},
+			assertFunc: func(t *testing.T, output ContentOutputDto) {
+				assert.Equal(t, control, output.Video)
+				assert.Empty(t, output.Region)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			mockRepo.EXPECT().
+				Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+				Return(NewEnterprisesData(entity.NewPathKey(endpoint), tt.rule), nil)
+
+			service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, nil)
+
+			output, err := service.GetContent(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			tt.assertFunc(t, output)
+		})
+	}
+}
+
+func TestContentUseCase_GetContentSigned(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+	key := entity.NewEnterpriseKey(endpoint)
+
+	video := entity.Video{
+		VideoUrl:    "https://cdn.example.com/a.mp4",
+		TambnailUrl: "https://cdn.example.com/a.jpg",
+		Renditions:  []entity.Rendition{{PlaylistUrl: "https://cdn.example.com/a/720.m3u8", Bandwidth: 2500000}},
+	}
+	rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).Build()
+
+	t.Run("enterprise with signing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Get(gomock.Any(), key).Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil)
+
+		mockSigner := NewMockUrlSigner(ctrl)
+		mockSigner.EXPECT().
+			Sign(key, gomock.Any()).
+			DoAndReturn(func(_ entity.EnterpriseKey, rawUrl string) (string, bool, error) {
+				return rawUrl + "?sig=x", true, nil
+			}).
+			AnyTimes()
+
+		output, err := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, mockSigner, nil).
+			GetContent(context.Background(), ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())})
+
+		assert.NoError(t, err)
+		assert.True(t, output.Signed)
+		assert.Equal(t, "https://cdn.example.com/a.mp4?sig=x", output.VideoUrl)
+		assert.Equal(t, "https://cdn.example.com/a.jpg?sig=x", output.TambnailUrl)
+		assert.Equal(t, "https://cdn.example.com/a/720.m3u8?sig=x", output.Renditions[0].PlaylistUrl)
+		assert.Equal(t, output.Video, output.Playlist[0])
+		// the stored rule is never modified
+		assert.Equal(t, "https://cdn.example.com/a/720.m3u8", rule.Video.Renditions[0].PlaylistUrl)
+	})
+
+	t.Run("enterprise without signing", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Get(gomock.Any(), key).Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil)
+
+		mockSigner := NewMockUrlSigner(ctrl)
+		mockSigner.EXPECT().
+			Sign(key, gomock.Any()).
+			DoAndReturn(func(_ entity.EnterpriseKey, rawUrl string) (string, bool, error) {
+				return rawUrl, false, nil
+			}).
+			AnyTimes()
+
+		output, err := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, mockSigner, nil).
+			GetContent(context.Background(), ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())})
+
+		assert.NoError(t, err)
+		assert.False(t, output.Signed)
+		assert.Equal(t, video, output.Video)
+	})
+
+	t.Run("each video is signed on its own", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		private := entity.Video{VideoUrl: "https://private.example.com/b.mp4"}
+		broken := entity.Video{VideoUrl: "https://cdn.example.com/deleted.mp4"}
+		fallback := entity.Video{VideoUrl: "https://private.example.com/backup.mp4"}
+		rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(video).
+			WithPlaylist([]entity.Video{video, private, broken}).WithFallback(&fallback).Build()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().Get(gomock.Any(), key).Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil)
+
+		mockLinks := NewMockLinkStatus(ctrl)
+		mockLinks.EXPECT().IsBroken(gomock.Any()).DoAndReturn(func(rawUrl string) bool {
+			return rawUrl == broken.VideoUrl
+		}).AnyTimes()
+
+		// only the urls of private.example.com are signed
+		mockSigner := NewMockUrlSigner(ctrl)
+		mockSigner.EXPECT().
+			Sign(key, gomock.Any()).
+			DoAndReturn(func(_ entity.EnterpriseKey, rawUrl string) (string, bool, error) {
+				if strings.HasPrefix(rawUrl, "https://private.example.com/") {
+					return rawUrl + "?sig=x", true, nil
+				}
+				return rawUrl, false, nil
+			}).
+			AnyTimes()
+
+		output, err := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, mockSigner, mockLinks).
+			GetContent(context.Background(), ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())})
+
+		assert.NoError(t, err)
+		assert.True(t, output.Signed)
+		assert.Equal(t, video, output.Video)
+		assert.Equal(t, video, output.Playlist[0])
+		assert.Equal(t, "https://private.example.com/b.mp4?sig=x", output.Playlist[1].VideoUrl)
+		assert.Equal(t, "https://private.example.com/backup.mp4?sig=x", output.Playlist[2].VideoUrl)
+	})
+}
+
+func TestContentUseCase_GetContentFallback(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+	key := entity.NewEnterpriseKey(endpoint)
+
+	healthy := entity.Video{VideoUrl: "https://cdn.com/a.mp4", TambnailUrl: "https://cdn.com/a.jpg"}
+	broken := entity.Video{VideoUrl: "https://cdn.com/deleted.mp4", TambnailUrl: "https://cdn.com/b.jpg"}
+	fallback := entity.Video{VideoUrl: "https://cdn.com/backup.mp4", TambnailUrl: "https://cdn.com/backup.jpg"}
+
+	tests := []struct {
+		name         string
+		rule         entity.Enterprise
+		wantVideo    entity.Video
+		wantPlaylist []entity.Video
+	}{
+		{
+			name: "broken videos are replaced by the fallback",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(broken).
+				WithPlaylist([]entity.Video{broken, healthy}).WithFallback(&fallback).Build(),
+			wantVideo:    fallback,
+			wantPlaylist: []entity.Video{fallback, healthy},
+		},
+		{
+			name: "healthy video is kept",
+			rule: builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(healthy).
+				WithFallback(&fallback).Build(),
+			wantVideo:    healthy,
+			wantPlaylist: []entity.Video{healthy},
+		},
+		{
+			name:         "broken video without fallback is kept",
+			rule:         builder.NewEnterpriseBuilder().WithUrl(endpoint).WithVideo(broken).Build(),
+			wantVideo:    broken,
+			wantPlaylist: []entity.Video{broken},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := NewMockRepository(ctrl)
+			mockRepo.EXPECT().Get(gomock.Any(), key).Return(NewEnterprisesData(entity.NewPathKey(endpoint), tt.rule), nil)
+
+			mockLinks := NewMockLinkStatus(ctrl)
+			mockLinks.EXPECT().
+				IsBroken(gomock.Any()).
+				DoAndReturn(func(rawUrl string) bool { return rawUrl == broken.VideoUrl }).
+				AnyTimes()
+
+			output, err := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, mockLinks).
+				GetContent(context.Background(), ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVideo, output.Video)
+			assert.Equal(t, tt.wantPlaylist, output.Playlist)
+		})
+	}
+}
+
+func TestContentUseCase_GetContentCatalog(t *testing.T) {
+	endpoint, _ := url.Parse("https://example.com/home")
+
+	intro := entity.CatalogVideo{
+		Id:    "intro",
+		Video: entity.Video{VideoUrl: "https://cdn.example.com/intro.mp4", TambnailUrl: "https://cdn.example.com/intro.jpg"},
+	}
+	other := entity.Video{VideoUrl: "https://cdn.example.com/b.mp4", TambnailUrl: "https://cdn.example.com/b.jpg"}
+
+	rule := builder.NewEnterpriseBuilder().WithUrl(endpoint).
+		WithVideo(entity.Video{CatalogId: "intro"}).
+		WithPlaylist([]entity.Video{{CatalogId: "intro"}, other}).
+		Build()
+
+	t.Run("join catalog video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		data := NewEnterprisesData(entity.NewPathKey(endpoint), rule)
+
+		mockRepo := NewMockRepository(ctrl)
+		mockCatalog := NewMockCatalogRepository(ctrl)
+
+		mockRepo.EXPECT().Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).Return(data, nil)
+		// the video is read once, however many times the rule references it
+		mockCatalog.EXPECT().GetVideo(gomock.Any(), "intro").Return(intro, nil).Times(1)
+
+		output, err := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, nil).
+			GetContent(context.Background(), ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())})
+
+		joined := intro.Video
+		joined.CatalogId = "intro"
+
+		assert.NoError(t, err)
+		assert.Equal(t, joined, output.Video)
+		assert.Equal(t, []entity.Video{joined, other}, output.Playlist)
+		// the stored rule keeps the reference
+		assert.Equal(t, entity.Video{CatalogId: "intro"}, data[entity.NewPathKey(endpoint)].Playlist[0])
+	})
+
+	t.Run("missing catalog video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockCatalog := NewMockCatalogRepository(ctrl)
+
+		mockRepo.EXPECT().
+			Get(gomock.Any(), entity.NewEnterpriseKey(endpoint)).
+			Return(NewEnterprisesData(entity.NewPathKey(endpoint), rule), nil)
+		mockCatalog.EXPECT().GetVideo(gomock.Any(), "intro").Return(entity.CatalogVideo{}, ErrVideoNotFound)
+
+		_, err := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, nil).
+			GetContent(context.Background(), ContentInputDto{Endpoint: NewEndpointDto(endpoint.String())})
+
+		assert.ErrorIs(t, err, ErrVideoNotFound)
+	})
+}
@@ -0,0 +1,44 @@
+package reader
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+const (
+	VisitorIdHeader = "X-Visitor-Id"
+	VisitorIdCookie = "visitor_id"
+
+	visitorIdCookieMaxAge = 365 * 24 * time.Hour
+)
+
+// visitorId returns the visitor identifier sent by the client, looking first at
+// the header and then at the cookie. When the client sends none, a new one is
+// generated and isNew is true, so the caller can persist it with setVisitorCookie.
+func visitorId(r *http.Request) (id string, isNew bool) {
+	if id := r.Header.Get(VisitorIdHeader); id != "" {
+		return id, false
+	}
+
+	if cookie, err := r.Cookie(VisitorIdCookie); err == nil && cookie.Value != "" {
+		return cookie.Value, false
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b), true
+}
+
+func setVisitorCookie(w http.ResponseWriter, id string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     VisitorIdCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(visitorIdCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,113 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+)
+
+// CreateVideo adds a video to the catalog under a new id.
+func (s *ContentUseCase) CreateVideo(ctx context.Context, input CatalogVideoInputDto) (CatalogVideoOutputDto, error) {
+	return s.saveVideo(ctx, entity.NewCatalogId(), input)
+}
+
+// UpdateVideo replaces the catalog video with the id, creating it when
+// it doesn't exist. The rules that reference it serve the new video.
+func (s *ContentUseCase) UpdateVideo(ctx context.Context, id string, input CatalogVideoInputDto) (CatalogVideoOutputDto, error) {
+	if !entity.IsValidCatalogId(id) {
+		return CatalogVideoOutputDto{}, fmt.Errorf("%w: invalid id %q", ErrInvalidVideo, id)
+	}
+
+	return s.saveVideo(ctx, id, input)
+}
+
+func (s *ContentUseCase) saveVideo(ctx context.Context, id string, input CatalogVideoInputDto) (CatalogVideoOutputDto, error) {
+	if err := s.resolveAsset(ctx, &input.VideoUrl, input.VideoAssetId); err != nil {
+		return CatalogVideoOutputDto{}, err
+	}
+
+	if err := s.resolveAsset(ctx, &input.TambnailUrl, input.TambnailAssetId); err != nil {
+		return CatalogVideoOutputDto{}, err
+	}
+
+	video, err := input.ToDomain()
+	if err != nil {
+		return CatalogVideoOutputDto{}, fmt.Errorf("%w: %w", ErrInvalidVideo, err)
+	}
+
+	if err := s.saveFiles(ctx, &video); err != nil {
+		return CatalogVideoOutputDto{}, err
+	}
+
+	s.computePlaceholders(ctx, func(fn func(video *entity.Video)) { fn(&video) })
+
+	catalogVideo := entity.CatalogVideo{Id: id, Video: video, UpdatedAt: time.Now().UTC()}
+	if err := s.catalog.SaveVideo(ctx, catalogVideo); err != nil {
+		return CatalogVideoOutputDto{}, fmt.Errorf("failed to save video: %w", err)
+	}
+	s.invalidateVideo(id)
+
+	return CatalogVideoOutputDto{Id: id, Url: s.publicBaseUrl + catalogVideo.Path()}, nil
+}
+
+// DeleteVideo removes the video from the catalog. It fails with a
+// VideoInUseError while rules still reference it.
+func (s *ContentUseCase) DeleteVideo(ctx context.Context, id string) error {
+	s.references.Lock()
+	defer s.references.Unlock()
+
+	endpoints, err := s.repository.FindVideoReferences(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to find video references: %w", err)
+	}
+
+	if len(endpoints) > 0 {
+		return &VideoInUseError{Id: id, Endpoints: endpoints}
+	}
+
+	if err := s.catalog.DeleteVideo(ctx, id); err != nil {
+		return err
+	}
+	s.invalidateVideo(id)
+
+	return nil
+}
+
+// invalidateVideo drops the cached content of the rules that join the
+// catalog video. The video is already saved, so a failure only delays the
+// change until the content expires.
+func (s *ContentUseCase) invalidateVideo(id string) {
+	if s.cache == nil {
+		return
+	}
+
+	if err := s.cache.Invalidate(entity.CatalogCacheTag(id)); err != nil {
+		log.Println("[WARNING] Failed to invalidate the content of video", id+":", err)
+	}
+}
+
+// GetVideoReferences lists the endpoints of the rules that use the catalog video.
+func (s *ContentUseCase) GetVideoReferences(ctx context.Context, id string) (VideoReferencesOutputDto, error) {
+	exists, err := s.catalog.VideoExists(ctx, id)
+	if err != nil {
+		return VideoReferencesOutputDto{}, fmt.Errorf("failed to check video: %w", err)
+	}
+
+	if !exists {
+		return VideoReferencesOutputDto{}, ErrVideoNotFound
+	}
+
+	endpoints, err := s.repository.FindVideoReferences(ctx, id)
+	if err != nil {
+		return VideoReferencesOutputDto{}, fmt.Errorf("failed to find video references: %w", err)
+	}
+
+	if endpoints == nil {
+		endpoints = []string{}
+	}
+
+	return VideoReferencesOutputDto{Id: id, Endpoints: endpoints}, nil
+}
@@ -0,0 +1,182 @@
+package writer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestService_CreateVideo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockCatalog := NewMockCatalogRepository(ctrl)
+
+	var saved entity.CatalogVideo
+	mockCatalog.EXPECT().
+		SaveVideo(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, video entity.CatalogVideo) error {
+			saved = video
+			return nil
+		})
+
+	service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, nil, "https://content.com/")
+
+	output, err := service.CreateVideo(context.Background(), CatalogVideoInputDto{
+		VideoUrl:    "https://example.com/video.mp4",
+		TambnailUrl: "https://example.com/thumb.jpg",
+	})
+
+	require.NoError(t, err)
+	assert.True(t, entity.IsValidCatalogId(output.Id))
+	assert.Equal(t, "https://content.com/videos/"+output.Id, output.Url)
+	assert.Equal(t, output.Id, saved.Id)
+	assert.Equal(t, "https://example.com/video.mp4", saved.Video.VideoUrl)
+	assert.False(t, saved.UpdatedAt.IsZero())
+}
+
+func TestService_UpdateVideo(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		input     CatalogVideoInputDto
+		setupMock func(mockCatalog *MockCatalogRepository, mockCache *MockContentCache)
+		wantErr   error
+	}{
+		{
+			name:  "replace video",
+			id:    "intro",
+			input: CatalogVideoInputDto{VideoUrl: "https://example.com/v2.mp4", TambnailUrl: "https://example.com/v2.jpg"},
+			setupMock: func(mockCatalog *MockCatalogRepository, mockCache *MockContentCache) {
+				save := mockCatalog.EXPECT().
+					SaveVideo(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, video entity.CatalogVideo) error {
+						assert.Equal(t, "intro", video.Id)
+						assert.Equal(t, "https://example.com/v2.mp4", video.Video.VideoUrl)
+						return nil
+					})
+				mockCache.EXPECT().Invalidate("video/intro").Return(nil).After(save)
+			},
+		},
+		{
+			name:      "invalid id",
+			id:        "../intro",
+			input:     CatalogVideoInputDto{VideoUrl: "https://example.com/v2.mp4", TambnailUrl: "https://example.com/v2.jpg"},
+			setupMock: func(mockCatalog *MockCatalogRepository, mockCache *MockContentCache) {},
+			wantErr:   ErrInvalidVideo,
+		},
+		{
+			name:      "invalid video",
+			id:        "intro",
+			input:     CatalogVideoInputDto{VideoUrl: "https://example.com/v2.mp4"},
+			setupMock: func(mockCatalog *MockCatalogRepository, mockCache *MockContentCache) {},
+			wantErr:   ErrInvalidVideo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockCatalog := NewMockCatalogRepository(ctrl)
+			mockCache := NewMockContentCache(ctrl)
+			tt.setupMock(mockCatalog, mockCache)
+
+			service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, mockCache, "https://content.com/")
+
+			_, err := service.UpdateVideo(context.Background(), tt.id, tt.input)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestService_DeleteVideo(t *testing.T) {
+	t.Run("unreferenced video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockCatalog := NewMockCatalogRepository(ctrl)
+
+		mockCache := NewMockContentCache(ctrl)
+
+		mockRepo.EXPECT().FindVideoReferences(gomock.Any(), "intro").Return(nil, nil)
+		mockCatalog.EXPECT().DeleteVideo(gomock.Any(), "intro").Return(nil)
+		mockCache.EXPECT().Invalidate("video/intro").Return(nil)
+
+		service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, mockCache, "https://content.com/")
+
+		assert.NoError(t, service.DeleteVideo(context.Background(), "intro"))
+	})
+
+	t.Run("referenced video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockRepo.EXPECT().
+			FindVideoReferences(gomock.Any(), "intro").
+			Return([]string{"https://example.com/about", "https://example.com/home"}, nil)
+
+		service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), NewMockCatalogRepository(ctrl), nil, NewMockContentCache(ctrl), "https://content.com/")
+
+		err := service.DeleteVideo(context.Background(), "intro")
+
+		var inUse *VideoInUseError
+		require.ErrorAs(t, err, &inUse)
+		assert.Equal(t, []string{"https://example.com/about", "https://example.com/home"}, inUse.Endpoints)
+	})
+}
+
+func TestService_RegisterCatalogReference(t *testing.T) {
+	t.Run("existing video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockCatalog := NewMockCatalogRepository(ctrl)
+
+		mockCatalog.EXPECT().VideoExists(gomock.Any(), "intro").Return(true, nil)
+		mockRepo.EXPECT().
+			Save(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, enterprise entity.Enterprise) error {
+				assert.Equal(t, entity.Video{CatalogId: "intro"}, enterprise.Video)
+				return nil
+			})
+
+		service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), VideoInputDto{Endpoint: "https://example.com/home", VideoId: "intro"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing video", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockCatalog := NewMockCatalogRepository(ctrl)
+		mockCatalog.EXPECT().VideoExists(gomock.Any(), "intro").Return(false, nil)
+
+		service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), mockCatalog, nil, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), VideoInputDto{
+			Endpoint:    "https://example.com/home",
+			VideoUrl:    "https://example.com/video.mp4",
+			TambnailUrl: "https://example.com/thumb.jpg",
+			Fallback:    &PlaylistItemInputDto{VideoId: "intro"},
+		})
+
+		assert.ErrorIs(t, err, ErrVideoNotFound)
+	})
+}
@@ -2,15 +2,360 @@ package writer
 
 import (
 	"errors"
+	"fmt"
 	"github.com/IsaacDSC/search_content/internal/content/entity"
 	"net/url"
 	"strings"
+	"time"
 )
 
 type VideoInputDto struct {
+	VideoUrl    string              `json:"video_url"`
+	TambnailUrl string              `json:"thumbnail_url"`
+	Endpoint    string              `json:"endpoint"`
+	Metadata    *MetadataInputDto   `json:"metadata,omitempty"`
+	Renditions  []RenditionInputDto `json:"renditions,omitempty"`
+	Experiment  *ExperimentInputDto `json:"experiment,omitempty"`
+	// Regions maps ISO 3166 codes ("BR" or "BR-SP") to the video served in that region.
+	Regions map[string]RegionInputDto `json:"regions,omitempty"`
+	// Playlist is the ordered list of videos of the endpoint. When it is set,
+	// video_url and thumbnail_url can be omitted.
+	Playlist []PlaylistItemInputDto `json:"playlist,omitempty"`
+	// VideoAssetId and TambnailAssetId reference files uploaded to this service
+	// (POST /assets or a tus upload) in place of video_url and thumbnail_url.
+	VideoAssetId    string `json:"video_asset_id,omitempty"`
+	TambnailAssetId string `json:"thumbnail_asset_id,omitempty"`
+	// Fallback is the backup video served while a video of the rule is broken.
+	Fallback *PlaylistItemInputDto `json:"fallback,omitempty"`
+	// VideoId references a video of the catalog (POST /videos) in place
+	// of the urls, so the rule follows the changes made to it.
+	VideoId string `json:"video_id,omitempty"`
+}
+
+// AssetOutputDto is the response of an upload, Url is the stable
+// address to use as video_url or thumbnail_url.
+type AssetOutputDto struct {
+	Id   string `json:"id"`
+	Url  string `json:"url"`
+	Size int64  `json:"size"`
+}
+
+const maxPlaylistItems = 50
+
+type PlaylistItemInputDto struct {
+	VideoUrl        string              `json:"video_url"`
+	TambnailUrl     string              `json:"thumbnail_url"`
+	Metadata        *MetadataInputDto   `json:"metadata,omitempty"`
+	Renditions      []RenditionInputDto `json:"renditions,omitempty"`
+	VideoAssetId    string              `json:"video_asset_id,omitempty"`
+	TambnailAssetId string              `json:"thumbnail_asset_id,omitempty"`
+	VideoId         string              `json:"video_id,omitempty"`
+}
+
+func (p PlaylistItemInputDto) ToDomain() (entity.Video, error) {
+	if p.VideoId != "" {
+		return newCatalogReference(p.VideoId, p.VideoUrl, p.TambnailUrl, p.Metadata, p.Renditions)
+	}
+
+	return newVideo(p.VideoUrl, p.TambnailUrl, p.Metadata, p.Renditions)
+}
+
+// CatalogVideoInputDto is a video of the catalog, which rules reference by id.
+type CatalogVideoInputDto struct {
+	VideoUrl        string              `json:"video_url"`
+	TambnailUrl     string              `json:"thumbnail_url"`
+	Metadata        *MetadataInputDto   `json:"metadata,omitempty"`
+	Renditions      []RenditionInputDto `json:"renditions,omitempty"`
+	VideoAssetId    string              `json:"video_asset_id,omitempty"`
+	TambnailAssetId string              `json:"thumbnail_asset_id,omitempty"`
+}
+
+func (c CatalogVideoInputDto) ToDomain() (entity.Video, error) {
+	return newVideo(c.VideoUrl, c.TambnailUrl, c.Metadata, c.Renditions)
+}
+
+// CatalogVideoOutputDto is the response of a catalog write, Id is the
+// value to use as video_id in the rules.
+type CatalogVideoOutputDto struct {
+	Id  string `json:"id"`
+	Url string `json:"url"`
+}
+
+// VideoReferencesOutputDto lists the endpoints of the rules that use a catalog video.
+type VideoReferencesOutputDto struct {
+	Id        string   `json:"id"`
+	Endpoints []string `json:"endpoints"`
+}
+
+// RenditionInputDto is one encoding listed in the HLS/DASH manifests.
+type RenditionInputDto struct {
+	Url         string  `json:"url"`
+	PlaylistUrl string  `json:"playlist_url"`
+	Bandwidth   int     `json:"bandwidth"`
+	Width       int     `json:"width"`
+	Height      int     `json:"height"`
+	Codecs      string  `json:"codecs"`
+	FrameRate   float64 `json:"frame_rate"`
+	MimeType    string  `json:"mime_type"`
+}
+
+const maxRenditions = 20
+
+func toRenditionsDomain(input []RenditionInputDto) ([]entity.Rendition, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	if len(input) > maxRenditions {
+		return nil, fmt.Errorf("more than %d renditions", maxRenditions)
+	}
+
+	renditions := make([]entity.Rendition, 0, len(input))
+	for i, r := range input {
+		rendition := entity.Rendition{
+			Url:         r.Url,
+			PlaylistUrl: r.PlaylistUrl,
+			Bandwidth:   r.Bandwidth,
+			Width:       r.Width,
+			Height:      r.Height,
+			Codecs:      normalizeCodecs(r.Codecs),
+			FrameRate:   r.FrameRate,
+			MimeType:    strings.TrimSpace(r.MimeType),
+		}
+
+		if err := rendition.Validate(); err != nil {
+			return nil, fmt.Errorf("rendition %d: %w", i, err)
+		}
+
+		renditions = append(renditions, rendition)
+	}
+
+	return renditions, nil
+}
+
+// normalizeCodecs removes the spaces around the codecs of the list, which
+// the type attribute of HTML allows, e.g. "avc1.64001f, mp4a.40.2".
+func normalizeCodecs(codecs string) string {
+	list := strings.Split(codecs, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+
+	return strings.Join(list, ",")
+}
+
+func toPlaylistDomain(input []PlaylistItemInputDto) ([]entity.Video, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	if len(input) > maxPlaylistItems {
+		return nil, fmt.Errorf("playlist has more than %d items", maxPlaylistItems)
+	}
+
+	playlist := make([]entity.Video, 0, len(input))
+	for i, item := range input {
+		video, err := item.ToDomain()
+		if err != nil {
+			return nil, fmt.Errorf("playlist item %d: %w", i, err)
+		}
+
+		playlist = append(playlist, video)
+	}
+
+	return playlist, nil
+}
+
+type RegionInputDto struct {
+	VideoUrl    string `json:"video_url"`
+	TambnailUrl string `json:"thumbnail_url"`
+}
+
+func toRegionsDomain(input map[string]RegionInputDto) (map[entity.RegionCode]entity.Video, error) {
+	if len(input) == 0 {
+		return nil, nil
+	}
+
+	regions := make(map[entity.RegionCode]entity.Video, len(input))
+	for code, v := range input {
+		region := entity.NewRegionCode(code)
+		if !region.IsValid() {
+			return nil, fmt.Errorf("invalid region code %q", code)
+		}
+
+		if _, exists := regions[region]; exists {
+			return nil, fmt.Errorf("duplicated region code %q", code)
+		}
+
+		if v.VideoUrl == "" || v.TambnailUrl == "" {
+			return nil, fmt.Errorf("video or thumbnail url is empty for region %q", code)
+		}
+
+		if _, err := url.Parse(v.VideoUrl); err != nil {
+			return nil, fmt.Errorf("invalid video url for region %q", code)
+		}
+
+		if _, err := url.Parse(v.TambnailUrl); err != nil {
+			return nil, fmt.Errorf("invalid thumbnail url for region %q", code)
+		}
+
+		regions[region] = entity.Video{
+			VideoUrl:    v.VideoUrl,
+			TambnailUrl: v.TambnailUrl,
+		}
+	}
+
+	return regions, nil
+}
+
+type MetadataInputDto struct {
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	DurationSeconds float64           `json:"duration_seconds"`
+	AspectRatio     string            `json:"aspect_ratio"`
+	PosterAlt       string            `json:"poster_alt"`
+	UploadDate      string            `json:"upload_date"` // RFC 3339 or YYYY-MM-DD
+	Captions        []CaptionInputDto `json:"captions"`
+	Preview         *PreviewInputDto  `json:"preview,omitempty"`
+	Attributes      map[string]string `json:"attributes"`
+}
+
+// PreviewInputDto lists the frames of the seek preview, one every
+// IntervalSeconds. Frames are urls of images available locally: uploaded
+// assets or files under the configured thumbnail roots.
+type PreviewInputDto struct {
+	FrameUrls       []string `json:"frame_urls"`
+	IntervalSeconds float64  `json:"interval_seconds"`
+	TileWidth       int      `json:"tile_width"`
+}
+
+// CaptionInputDto points to an external track by Url or carries
+// an inline WebVTT Body that is stored and served by this service.
+type CaptionInputDto struct {
+	Language string `json:"language"`
+	Kind     string `json:"kind"`
+	Label    string `json:"label"`
+	Url      string `json:"url"`
+	Body     string `json:"body"`
+}
+
+func (m *MetadataInputDto) ToDomain() (*entity.VideoMetadata, error) {
+	metadata := &entity.VideoMetadata{
+		Title:           strings.TrimSpace(m.Title),
+		Description:     strings.TrimSpace(m.Description),
+		DurationSeconds: m.DurationSeconds,
+		AspectRatio:     strings.TrimSpace(m.AspectRatio),
+		PosterAlt:       strings.TrimSpace(m.PosterAlt),
+		Attributes:      m.Attributes,
+	}
+
+	if m.UploadDate != "" {
+		uploadDate, err := parseDate(m.UploadDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upload date %q", m.UploadDate)
+		}
+		metadata.UploadDate = &uploadDate
+	}
+
+	for _, c := range m.Captions {
+		kind := entity.CaptionKind(strings.ToLower(c.Kind))
+		if kind == "" {
+			kind = entity.CaptionKindSubtitles
+		}
+
+		metadata.Captions = append(metadata.Captions, entity.Caption{
+			Language: c.Language,
+			Kind:     kind,
+			Label:    c.Label,
+			Url:      c.Url,
+			Body:     c.Body,
+		})
+	}
+
+	if m.Preview != nil {
+		metadata.Preview = &entity.PreviewTrack{
+			Frames:          m.Preview.FrameUrls,
+			IntervalSeconds: m.Preview.IntervalSeconds,
+			TileWidth:       m.Preview.TileWidth,
+		}
+	}
+
+	if err := metadata.Validate(); err != nil {
+		return nil, err
+	}
+
+	return metadata, nil
+}
+
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+type ExperimentInputDto struct {
+	Id       string            `json:"id"`
+	Variants []VariantInputDto `json:"variants"`
+}
+
+type VariantInputDto struct {
+	Id          string `json:"id"`
+	Weight      int    `json:"weight"`
 	VideoUrl    string `json:"video_url"`
 	TambnailUrl string `json:"thumbnail_url"`
-	Endpoint    string `json:"endpoint"`
+}
+
+func (e *ExperimentInputDto) ToDomain() (*entity.Experiment, error) {
+	if e.Id == "" {
+		return nil, errors.New("experiment id is empty")
+	}
+
+	if len(e.Variants) == 0 {
+		return nil, errors.New("experiment has no variants")
+	}
+
+	experiment := &entity.Experiment{Id: e.Id}
+	seen := make(map[string]bool, len(e.Variants))
+
+	for _, v := range e.Variants {
+		if v.Id == "" {
+			return nil, errors.New("variant id is empty")
+		}
+
+		if seen[v.Id] {
+			return nil, fmt.Errorf("duplicated variant id %q", v.Id)
+		}
+		seen[v.Id] = true
+
+		if v.Weight <= 0 {
+			return nil, fmt.Errorf("invalid weight for variant %q", v.Id)
+		}
+
+		if v.VideoUrl == "" || v.TambnailUrl == "" {
+			return nil, fmt.Errorf("video or thumbnail url is empty for variant %q", v.Id)
+		}
+
+		if _, err := url.Parse(v.VideoUrl); err != nil {
+			return nil, fmt.Errorf("invalid video url for variant %q", v.Id)
+		}
+
+		if _, err := url.Parse(v.TambnailUrl); err != nil {
+			return nil, fmt.Errorf("invalid thumbnail url for variant %q", v.Id)
+		}
+
+		experiment.Variants = append(experiment.Variants, entity.Variant{
+			Id:     v.Id,
+			Weight: v.Weight,
+			Video: entity.Video{
+				VideoUrl:    v.VideoUrl,
+				TambnailUrl: v.TambnailUrl,
+			},
+		})
+	}
+
+	return experiment, nil
 }
 
 func (v *VideoInputDto) ToDomain() (entity.Enterprise, error) {
@@ -23,30 +368,109 @@ func (v *VideoInputDto) ToDomain() (entity.Enterprise, error) {
 	path := endpoint.Path
 	paths := strings.Split(path, "/")[1:] // Remove a primeira barra
 
-	if v.VideoUrl == "" {
-		return entity.Enterprise{}, errors.New("video url is empty")
+	playlist, err := toPlaylistDomain(v.Playlist)
+	if err != nil {
+		return entity.Enterprise{}, err
 	}
 
-	if v.TambnailUrl == "" {
-		return entity.Enterprise{}, errors.New("thumbnail url is empty")
+	var video entity.Video
+	if len(playlist) > 0 && v.VideoUrl == "" && v.TambnailUrl == "" && v.VideoId == "" {
+		// a playlist rule may omit the single video, the first item takes its place
+		video = playlist[0]
+	} else if v.VideoId != "" {
+		if video, err = newCatalogReference(v.VideoId, v.VideoUrl, v.TambnailUrl, v.Metadata, v.Renditions); err != nil {
+			return entity.Enterprise{}, err
+		}
+	} else {
+		if video, err = newVideo(v.VideoUrl, v.TambnailUrl, v.Metadata, v.Renditions); err != nil {
+			return entity.Enterprise{}, err
+		}
 	}
 
-	if _, err := url.Parse(v.VideoUrl); err != nil {
-		return entity.Enterprise{}, errors.New("invalid video url")
+	var experiment *entity.Experiment
+	if v.Experiment != nil {
+		if experiment, err = v.Experiment.ToDomain(); err != nil {
+			return entity.Enterprise{}, err
+		}
+	}
+
+	regions, err := toRegionsDomain(v.Regions)
+	if err != nil {
+		return entity.Enterprise{}, err
 	}
 
-	if _, err := url.Parse(v.TambnailUrl); err != nil {
-		return entity.Enterprise{}, errors.New("invalid thumbnail url")
+	var fallback *entity.Video
+	if f := v.Fallback; f != nil {
+		video, err := f.ToDomain()
+		if err != nil {
+			return entity.Enterprise{}, fmt.Errorf("fallback: %w", err)
+		}
+		fallback = &video
 	}
 
 	return entity.Enterprise{
-		Url:    endpoint,
-		Origin: origin,
-		Paths:  paths,
-		Path:   path,
-		Video: entity.Video{
-			VideoUrl:    v.VideoUrl,
-			TambnailUrl: v.TambnailUrl,
-		},
+		Url:        endpoint,
+		Origin:     origin,
+		Paths:      paths,
+		Path:       path,
+		Video:      video,
+		Playlist:   playlist,
+		Experiment: experiment,
+		Regions:    regions,
+		Fallback:   fallback,
 	}, nil
 }
+
+func newVideo(videoUrl, thumbnailUrl string, metadataInput *MetadataInputDto, renditionsInput []RenditionInputDto) (entity.Video, error) {
+	if videoUrl == "" {
+		return entity.Video{}, errors.New("video url is empty")
+	}
+
+	if thumbnailUrl == "" {
+		return entity.Video{}, errors.New("thumbnail url is empty")
+	}
+
+	if _, err := url.Parse(videoUrl); err != nil {
+		return entity.Video{}, errors.New("invalid video url")
+	}
+
+	if _, err := url.Parse(thumbnailUrl); err != nil {
+		return entity.Video{}, errors.New("invalid thumbnail url")
+	}
+
+	var (
+		metadata *entity.VideoMetadata
+		err      error
+	)
+	if metadataInput != nil {
+		if metadata, err = metadataInput.ToDomain(); err != nil {
+			return entity.Video{}, err
+		}
+	}
+
+	renditions, err := toRenditionsDomain(renditionsInput)
+	if err != nil {
+		return entity.Video{}, err
+	}
+
+	return entity.Video{
+		VideoUrl:    videoUrl,
+		TambnailUrl: thumbnailUrl,
+		Metadata:    metadata,
+		Renditions:  renditions,
+	}, nil
+}
+
+// newCatalogReference creates a video that only references the catalog,
+// its urls and metadata come from the catalog video.
+func newCatalogReference(id, videoUrl, thumbnailUrl string, metadata *MetadataInputDto, renditions []RenditionInputDto) (entity.Video, error) {
+	if !entity.IsValidCatalogId(id) {
+		return entity.Video{}, fmt.Errorf("invalid video id %q", id)
+	}
+
+	if videoUrl != "" || thumbnailUrl != "" || metadata != nil || len(renditions) > 0 {
+		return entity.Video{}, fmt.Errorf("video id %s must not be set together with urls, metadata or renditions", id)
+	}
+
+	return entity.Video{CatalogId: id}, nil
+}
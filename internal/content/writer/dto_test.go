@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestVideoInputDto_ToDomain(t *testing.T) {
@@ -70,6 +71,372 @@ func TestVideoInputDto_ToDomain(t *testing.T) {
 			wantErr:     true,
 			errContains: "invalid",
 		},
+		{
+			name: "Video input with experiment",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Experiment: &ExperimentInputDto{
+					Id: "hero",
+					Variants: []VariantInputDto{
+						{Id: "a", Weight: 1, VideoUrl: "https://example.com/a.mp4", TambnailUrl: "https://example.com/a.jpg"},
+						{Id: "b", Weight: 3, VideoUrl: "https://example.com/b.mp4", TambnailUrl: "https://example.com/b.jpg"},
+					},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video: entity.Video{
+						VideoUrl:    "https://example.com/a.mp4",
+						TambnailUrl: "https://example.com/a.jpg",
+					},
+					Experiment: &entity.Experiment{
+						Id: "hero",
+						Variants: []entity.Variant{
+							{Id: "a", Weight: 1, Video: entity.Video{VideoUrl: "https://example.com/a.mp4", TambnailUrl: "https://example.com/a.jpg"}},
+							{Id: "b", Weight: 3, Video: entity.Video{VideoUrl: "https://example.com/b.mp4", TambnailUrl: "https://example.com/b.jpg"}},
+						},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Experiment with duplicated variant",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Experiment: &ExperimentInputDto{
+					Id: "hero",
+					Variants: []VariantInputDto{
+						{Id: "a", Weight: 1, VideoUrl: "https://example.com/a.mp4", TambnailUrl: "https://example.com/a.jpg"},
+						{Id: "a", Weight: 1, VideoUrl: "https://example.com/b.mp4", TambnailUrl: "https://example.com/b.jpg"},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "duplicated",
+		},
+		{
+			name: "Experiment with invalid weight",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Experiment: &ExperimentInputDto{
+					Id: "hero",
+					Variants: []VariantInputDto{
+						{Id: "a", Weight: 0, VideoUrl: "https://example.com/a.mp4", TambnailUrl: "https://example.com/a.jpg"},
+					},
+				},
+			},
+			wantErr:     true,
+			errContains: "weight",
+		},
+		{
+			name: "Video input with regions",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Regions: map[string]RegionInputDto{
+					"br-sp": {VideoUrl: "https://example.com/sp.mp4", TambnailUrl: "https://example.com/sp.jpg"},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video: entity.Video{
+						VideoUrl:    "https://example.com/a.mp4",
+						TambnailUrl: "https://example.com/a.jpg",
+					},
+					Regions: map[entity.RegionCode]entity.Video{
+						"BR-SP": {VideoUrl: "https://example.com/sp.mp4", TambnailUrl: "https://example.com/sp.jpg"},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Invalid region code",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Regions: map[string]RegionInputDto{
+					"Brazil": {VideoUrl: "https://example.com/sp.mp4", TambnailUrl: "https://example.com/sp.jpg"},
+				},
+			},
+			wantErr:     true,
+			errContains: "invalid region",
+		},
+		{
+			name: "Video input with metadata",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Metadata: &MetadataInputDto{
+					Title:           " Summer ",
+					DurationSeconds: 12.5,
+					AspectRatio:     "9:16",
+					PosterAlt:       "Blue shirt",
+					UploadDate:      "2024-05-01",
+					Captions:        []CaptionInputDto{{Language: "pt-BR", Url: "https://example.com/pt.vtt"}},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				uploadDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video: entity.Video{
+						VideoUrl:    "https://example.com/a.mp4",
+						TambnailUrl: "https://example.com/a.jpg",
+						Metadata: &entity.VideoMetadata{
+							Title:           "Summer",
+							DurationSeconds: 12.5,
+							AspectRatio:     "9:16",
+							PosterAlt:       "Blue shirt",
+							UploadDate:      &uploadDate,
+							Captions: []entity.Caption{
+								{Language: "pt-BR", Kind: entity.CaptionKindSubtitles, Url: "https://example.com/pt.vtt"},
+							},
+						},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Invalid metadata upload date",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/a.mp4",
+				TambnailUrl: "https://example.com/a.jpg",
+				Endpoint:    "https://example.com/home",
+				Metadata:    &MetadataInputDto{UploadDate: "yesterday"},
+			},
+			wantErr:     true,
+			errContains: "upload date",
+		},
+		{
+			name: "Playlist without single video",
+			videoInput: VideoInputDto{
+				Endpoint: "https://example.com/camisas",
+				Playlist: []PlaylistItemInputDto{
+					{VideoUrl: "https://example.com/1.mp4", TambnailUrl: "https://example.com/1.jpg"},
+					{VideoUrl: "https://example.com/2.mp4", TambnailUrl: "https://example.com/2.jpg"},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/camisas")
+				first := entity.Video{VideoUrl: "https://example.com/1.mp4", TambnailUrl: "https://example.com/1.jpg"}
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"camisas"},
+					Path:   "/camisas",
+					Video:  first,
+					Playlist: []entity.Video{
+						first,
+						{VideoUrl: "https://example.com/2.mp4", TambnailUrl: "https://example.com/2.jpg"},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Playlist with invalid item",
+			videoInput: VideoInputDto{
+				Endpoint: "https://example.com/camisas",
+				Playlist: []PlaylistItemInputDto{
+					{VideoUrl: "https://example.com/1.mp4", TambnailUrl: "https://example.com/1.jpg"},
+					{VideoUrl: "https://example.com/2.mp4"},
+				},
+			},
+			wantErr:     true,
+			errContains: "playlist item 1: thumbnail url is empty",
+		},
+		{
+			name: "Video input with renditions",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+				Renditions: []RenditionInputDto{
+					{Url: "https://cdn.example.com/720.mp4", PlaylistUrl: "https://cdn.example.com/720.m3u8", Bandwidth: 2500000, Width: 1280, Height: 720, Codecs: "avc1.64001f,mp4a.40.2"},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video: entity.Video{
+						VideoUrl:    "https://example.com/video.mp4",
+						TambnailUrl: "https://example.com/thumb.jpg",
+						Renditions: []entity.Rendition{
+							{Url: "https://cdn.example.com/720.mp4", PlaylistUrl: "https://cdn.example.com/720.m3u8", Bandwidth: 2500000, Width: 1280, Height: 720, Codecs: "avc1.64001f,mp4a.40.2"},
+						},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Rendition without bandwidth",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+				Renditions:  []RenditionInputDto{{Url: "https://cdn.example.com/720.mp4"}},
+			},
+			wantErr:     true,
+			errContains: "bandwidth",
+		},
+		{
+			name: "Rendition with codecs injecting a manifest tag",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+				Renditions: []RenditionInputDto{{
+					PlaylistUrl: "https://cdn.example.com/720.m3u8",
+					Bandwidth:   2500000,
+					Codecs:      "avc1.64001f\"\n#EXT-X-STREAM-INF:BANDWIDTH=1\nhttps://evil.com/a.m3u8",
+				}},
+			},
+			wantErr:     true,
+			errContains: "rendition 0: rendition codecs",
+		},
+		{
+			name: "Rendition with spaces between the codecs",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+				Renditions: []RenditionInputDto{
+					{PlaylistUrl: "https://cdn.example.com/720.m3u8", Bandwidth: 2500000, Codecs: " avc1.64001f, mp4a.40.2 "},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video: entity.Video{
+						VideoUrl:    "https://example.com/video.mp4",
+						TambnailUrl: "https://example.com/thumb.jpg",
+						Renditions: []entity.Rendition{
+							{PlaylistUrl: "https://cdn.example.com/720.m3u8", Bandwidth: 2500000, Codecs: "avc1.64001f,mp4a.40.2"},
+						},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Video input with fallback",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+				Fallback:    &PlaylistItemInputDto{VideoUrl: "https://backup.com/video.mp4", TambnailUrl: "https://backup.com/thumb.jpg"},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video: entity.Video{
+						VideoUrl:    "https://example.com/video.mp4",
+						TambnailUrl: "https://example.com/thumb.jpg",
+					},
+					Fallback: &entity.Video{
+						VideoUrl:    "https://backup.com/video.mp4",
+						TambnailUrl: "https://backup.com/thumb.jpg",
+					},
+				}
+			}(),
+		},
+		{
+			name: "Fallback without thumbnail",
+			videoInput: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+				Fallback:    &PlaylistItemInputDto{VideoUrl: "https://backup.com/video.mp4"},
+			},
+			wantErr:     true,
+			errContains: "fallback: thumbnail url is empty",
+		},
+		{
+			name: "Video input referencing the catalog",
+			videoInput: VideoInputDto{
+				VideoId:  "intro",
+				Endpoint: "https://example.com/home",
+				Playlist: []PlaylistItemInputDto{
+					{VideoId: "intro"},
+					{VideoUrl: "https://example.com/video.mp4", TambnailUrl: "https://example.com/thumb.jpg"},
+				},
+			},
+			wantErr: false,
+			wantDomain: func() entity.Enterprise {
+				u, _ := url.Parse("https://example.com/home")
+				return entity.Enterprise{
+					Url:    u,
+					Origin: "https://example.com",
+					Paths:  []string{"home"},
+					Path:   "/home",
+					Video:  entity.Video{CatalogId: "intro"},
+					Playlist: []entity.Video{
+						{CatalogId: "intro"},
+						{VideoUrl: "https://example.com/video.mp4", TambnailUrl: "https://example.com/thumb.jpg"},
+					},
+				}
+			}(),
+		},
+		{
+			name: "Video id with urls",
+			videoInput: VideoInputDto{
+				VideoId:     "intro",
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumb.jpg",
+				Endpoint:    "https://example.com/home",
+			},
+			wantErr:     true,
+			errContains: "must not be set together",
+		},
+		{
+			name: "Invalid video id",
+			videoInput: VideoInputDto{
+				VideoId:  "../intro",
+				Endpoint: "https://example.com/home",
+			},
+			wantErr:     true,
+			errContains: "invalid video id",
+		},
 		{
 			name: "Empty video URL",
 			videoInput: VideoInputDto{
@@ -125,6 +492,26 @@ func TestVideoInputDto_ToDomain(t *testing.T) {
 					t.Errorf("VideoInputDto.ToDomain() Video = %v, want %v",
 						gotDomain.Video, tt.wantDomain.Video)
 				}
+
+				if !reflect.DeepEqual(gotDomain.Experiment, tt.wantDomain.Experiment) {
+					t.Errorf("VideoInputDto.ToDomain() Experiment = %v, want %v",
+						gotDomain.Experiment, tt.wantDomain.Experiment)
+				}
+
+				if !reflect.DeepEqual(gotDomain.Regions, tt.wantDomain.Regions) {
+					t.Errorf("VideoInputDto.ToDomain() Regions = %v, want %v",
+						gotDomain.Regions, tt.wantDomain.Regions)
+				}
+
+				if !reflect.DeepEqual(gotDomain.Playlist, tt.wantDomain.Playlist) {
+					t.Errorf("VideoInputDto.ToDomain() Playlist = %v, want %v",
+						gotDomain.Playlist, tt.wantDomain.Playlist)
+				}
+
+				if !reflect.DeepEqual(gotDomain.Fallback, tt.wantDomain.Fallback) {
+					t.Errorf("VideoInputDto.ToDomain() Fallback = %v, want %v",
+						gotDomain.Fallback, tt.wantDomain.Fallback)
+				}
 			}
 		})
 	}
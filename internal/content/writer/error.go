@@ -1,5 +1,26 @@
 package writer
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-var ErrInvalidDataType = errors.New("invalid data type")
+var (
+	ErrEmptyAsset      = errors.New("asset is empty")
+	ErrAssetNotFound   = errors.New("asset not found")
+	ErrFrameNotFound   = errors.New("preview frame not found")
+	ErrVideoNotFound   = errors.New("video not found")
+	ErrInvalidVideo    = errors.New("invalid video")
+	ErrInvalidEndpoint = errors.New("invalid endpoint")
+)
+
+// VideoInUseError is returned when deleting a catalog video that rules still reference.
+type VideoInUseError struct {
+	Id        string
+	Endpoints []string
+}
+
+func (e *VideoInUseError) Error() string {
+	return fmt.Sprintf("video %s is used by %s", e.Id, strings.Join(e.Endpoints, ", "))
+}
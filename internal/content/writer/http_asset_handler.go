@@ -0,0 +1,84 @@
+package writer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+)
+
+// MaxAssetSize is the largest file accepted by UploadAsset and by resumable uploads.
+const MaxAssetSize = 1 << 30 // 1GB
+
+// assetFormField is the multipart field that carries the file.
+const assetFormField = "file"
+
+// UploadAsset stores a video or an image sent either as the raw request
+// body or as the "file" field of a multipart form, and answers with the
+// url it is served at. The file is streamed to the storage, never held in memory.
+func (h *HttpHandler) UploadAsset(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	defer r.Body.Close()
+
+	r.Body = http.MaxBytesReader(w, r.Body, MaxAssetSize)
+
+	body, err := assetBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	output, err := h.service.UploadAsset(r.Context(), body)
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		http.Error(w, "Asset is too large", http.StatusRequestEntityTooLarge)
+		return nil
+	}
+
+	if errors.Is(err, ErrEmptyAsset) {
+		http.Error(w, "Asset is empty", http.StatusBadRequest)
+		return nil
+	}
+
+	if err != nil {
+		log.Printf("failed to upload asset: %v", err)
+		http.Error(w, "Failed to upload asset", http.StatusInternalServerError)
+		return nil
+	}
+
+	w.Header().Set("Location", output.Url)
+	w.WriteHeader(http.StatusCreated)
+
+	return json.NewEncoder(w).Encode(output)
+}
+
+// assetBody returns the reader of the uploaded file.
+func assetBody(r *http.Request) (io.Reader, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return nil, errors.New("invalid multipart body")
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New(`missing "file" field`)
+		}
+
+		if err != nil {
+			return nil, errors.New("invalid multipart body")
+		}
+
+		if part.FormName() == assetFormField {
+			return part, nil
+		}
+	}
+}
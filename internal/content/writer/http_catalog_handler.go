@@ -0,0 +1,98 @@
+package writer
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// CreateVideo adds a video to the catalog and answers with its id.
+func (h *HttpHandler) CreateVideo(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	defer r.Body.Close()
+
+	var body CatalogVideoInputDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil
+	}
+
+	output, err := h.service.CreateVideo(r.Context(), body)
+	if err != nil {
+		return writeVideoError(w, err)
+	}
+
+	w.Header().Set("Location", output.Url)
+	w.WriteHeader(http.StatusCreated)
+
+	return json.NewEncoder(w).Encode(output)
+}
+
+// UpdateVideo replaces the catalog video, or creates it with the id of the path.
+func (h *HttpHandler) UpdateVideo(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	defer r.Body.Close()
+
+	var body CatalogVideoInputDto
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil
+	}
+
+	output, err := h.service.UpdateVideo(r.Context(), r.PathValue("id"), body)
+	if err != nil {
+		return writeVideoError(w, err)
+	}
+
+	return json.NewEncoder(w).Encode(output)
+}
+
+// DeleteVideo removes the catalog video. A video still referenced by
+// rules is kept and the response lists their endpoints.
+func (h *HttpHandler) DeleteVideo(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+
+	err := h.service.DeleteVideo(r.Context(), r.PathValue("id"))
+
+	var inUse *VideoInUseError
+	if errors.As(err, &inUse) {
+		w.WriteHeader(http.StatusConflict)
+		return json.NewEncoder(w).Encode(VideoReferencesOutputDto{Id: inUse.Id, Endpoints: inUse.Endpoints})
+	}
+
+	if err != nil {
+		return writeVideoError(w, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// GetVideoReferences answers with the endpoints of the rules that use the catalog video.
+func (h *HttpHandler) GetVideoReferences(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "application/json")
+	// the references change with every rule written, the response must not be cached
+	w.Header().Set("Cache-Control", "no-store")
+
+	output, err := h.service.GetVideoReferences(r.Context(), r.PathValue("id"))
+	if err != nil {
+		return writeVideoError(w, err)
+	}
+
+	return json.NewEncoder(w).Encode(output)
+}
+
+func writeVideoError(w http.ResponseWriter, err error) error {
+	switch {
+	case errors.Is(err, ErrVideoNotFound):
+		http.Error(w, "Video not found", http.StatusNotFound)
+	case errors.Is(err, ErrInvalidVideo), errors.Is(err, ErrAssetNotFound):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("failed to write video: %v", err)
+		http.Error(w, "Failed to write video", http.StatusInternalServerError)
+	}
+
+	return nil
+}
@@ -2,22 +2,40 @@ package writer
 
 import (
 	"encoding/json"
+	"errors"
+	"github.com/IsaacDSC/search_content/pkg/sprite"
+	"github.com/IsaacDSC/search_content/pkg/tus"
 	"log"
 	"net/http"
 )
 
 type Handler interface {
 	SaveContent(w http.ResponseWriter, r *http.Request) error
+	UploadAsset(w http.ResponseWriter, r *http.Request) error
+
+	CreateVideo(w http.ResponseWriter, r *http.Request) error
+	UpdateVideo(w http.ResponseWriter, r *http.Request) error
+	DeleteVideo(w http.ResponseWriter, r *http.Request) error
+	GetVideoReferences(w http.ResponseWriter, r *http.Request) error
+
+	UploadOptions(w http.ResponseWriter, r *http.Request) error
+	CreateUpload(w http.ResponseWriter, r *http.Request) error
+	GetUploadOffset(w http.ResponseWriter, r *http.Request) error
+	PatchUpload(w http.ResponseWriter, r *http.Request) error
+	DeleteUpload(w http.ResponseWriter, r *http.Request) error
 }
 
 type HttpHandler struct {
 	service Service
+	uploads *tus.Handler
 }
 
 var _ Handler = (*HttpHandler)(nil)
 
-func NewHandler(service Service) *HttpHandler {
-	return &HttpHandler{service: service}
+// NewHandler creates the writer handler. uploads serves the resumable
+// uploads, whose completed files are stored as assets.
+func NewHandler(service Service, uploads *tus.Handler) *HttpHandler {
+	return &HttpHandler{service: service, uploads: uploads}
 }
 
 func (h *HttpHandler) SaveContent(w http.ResponseWriter, r *http.Request) error {
@@ -30,7 +48,13 @@ func (h *HttpHandler) SaveContent(w http.ResponseWriter, r *http.Request) error
 		return nil
 	}
 
-	if err := h.service.Register(r.Context(), body); err != nil {
+	err := h.service.Register(r.Context(), body)
+	if errors.Is(err, ErrVideoNotFound) || errors.Is(err, ErrInvalidVideo) || errors.Is(err, ErrInvalidEndpoint) || errors.Is(err, sprite.ErrInvalidOptions) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	if err != nil {
 		log.Printf("failed to register video: %v", err)
 		http.Error(w, "Failed to register content", http.StatusInternalServerError)
 		return nil
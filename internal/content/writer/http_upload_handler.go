@@ -0,0 +1,27 @@
+package writer
+
+import "net/http"
+
+// The resumable uploads follow the tus protocol, implemented by pkg/tus.
+// Once complete, the upload is stored as an asset and its url is returned
+// in the Content-Location header of the last PATCH and of HEAD.
+
+func (h *HttpHandler) UploadOptions(w http.ResponseWriter, r *http.Request) error {
+	return h.uploads.Options(w, r)
+}
+
+func (h *HttpHandler) CreateUpload(w http.ResponseWriter, r *http.Request) error {
+	return h.uploads.Create(w, r)
+}
+
+func (h *HttpHandler) GetUploadOffset(w http.ResponseWriter, r *http.Request) error {
+	return h.uploads.Head(w, r)
+}
+
+func (h *HttpHandler) PatchUpload(w http.ResponseWriter, r *http.Request) error {
+	return h.uploads.Patch(w, r)
+}
+
+func (h *HttpHandler) DeleteUpload(w http.ResponseWriter, r *http.Request) error {
+	return h.uploads.Delete(w, r)
+}
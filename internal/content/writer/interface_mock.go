@@ -1,9 +1,9 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: internal/content/writer/interface.go
+// Source: internal/content/writer/repository.go
 //
 // Generated by this command:
 //
-//	mockgen -source=internal/content/writer/interface.go -destination=internal/content/writer/interface_mock.go -package=writer
+//	mockgen -source=internal/content/writer/repository.go -destination=internal/content/writer/interface_mock.go -package=writer
 //
 
 // Package writer is a generated GoMock package.
@@ -11,6 +11,7 @@ package writer
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	entity "github.com/IsaacDSC/search_content/internal/content/entity"
@@ -41,6 +42,21 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// FindVideoReferences mocks base method.
+func (m *MockRepository) FindVideoReferences(ctx context.Context, id string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindVideoReferences", ctx, id)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindVideoReferences indicates an expected call of FindVideoReferences.
+func (mr *MockRepositoryMockRecorder) FindVideoReferences(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindVideoReferences", reflect.TypeOf((*MockRepository)(nil).FindVideoReferences), ctx, id)
+}
+
 // Save mocks base method.
 func (m *MockRepository) Save(ctx context.Context, enterprise entity.Enterprise) error {
 	m.ctrl.T.Helper()
@@ -54,3 +70,244 @@ func (mr *MockRepositoryMockRecorder) Save(ctx, enterprise any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRepository)(nil).Save), ctx, enterprise)
 }
+
+// MockCatalogRepository is a mock of CatalogRepository interface.
+type MockCatalogRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCatalogRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCatalogRepositoryMockRecorder is the mock recorder for MockCatalogRepository.
+type MockCatalogRepositoryMockRecorder struct {
+	mock *MockCatalogRepository
+}
+
+// NewMockCatalogRepository creates a new mock instance.
+func NewMockCatalogRepository(ctrl *gomock.Controller) *MockCatalogRepository {
+	mock := &MockCatalogRepository{ctrl: ctrl}
+	mock.recorder = &MockCatalogRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCatalogRepository) EXPECT() *MockCatalogRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteVideo mocks base method.
+func (m *MockCatalogRepository) DeleteVideo(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVideo", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteVideo indicates an expected call of DeleteVideo.
+func (mr *MockCatalogRepositoryMockRecorder) DeleteVideo(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVideo", reflect.TypeOf((*MockCatalogRepository)(nil).DeleteVideo), ctx, id)
+}
+
+// SaveVideo mocks base method.
+func (m *MockCatalogRepository) SaveVideo(ctx context.Context, video entity.CatalogVideo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveVideo", ctx, video)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveVideo indicates an expected call of SaveVideo.
+func (mr *MockCatalogRepositoryMockRecorder) SaveVideo(ctx, video any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveVideo", reflect.TypeOf((*MockCatalogRepository)(nil).SaveVideo), ctx, video)
+}
+
+// VideoExists mocks base method.
+func (m *MockCatalogRepository) VideoExists(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VideoExists", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VideoExists indicates an expected call of VideoExists.
+func (mr *MockCatalogRepositoryMockRecorder) VideoExists(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VideoExists", reflect.TypeOf((*MockCatalogRepository)(nil).VideoExists), ctx, id)
+}
+
+// MockCaptionRepository is a mock of CaptionRepository interface.
+type MockCaptionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCaptionRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockCaptionRepositoryMockRecorder is the mock recorder for MockCaptionRepository.
+type MockCaptionRepositoryMockRecorder struct {
+	mock *MockCaptionRepository
+}
+
+// NewMockCaptionRepository creates a new mock instance.
+func NewMockCaptionRepository(ctrl *gomock.Controller) *MockCaptionRepository {
+	mock := &MockCaptionRepository{ctrl: ctrl}
+	mock.recorder = &MockCaptionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCaptionRepository) EXPECT() *MockCaptionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SaveCaption mocks base method.
+func (m *MockCaptionRepository) SaveCaption(ctx context.Context, track entity.CaptionTrack) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCaption", ctx, track)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCaption indicates an expected call of SaveCaption.
+func (mr *MockCaptionRepositoryMockRecorder) SaveCaption(ctx, track any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCaption", reflect.TypeOf((*MockCaptionRepository)(nil).SaveCaption), ctx, track)
+}
+
+// MockAssetRepository is a mock of AssetRepository interface.
+type MockAssetRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAssetRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockAssetRepositoryMockRecorder is the mock recorder for MockAssetRepository.
+type MockAssetRepositoryMockRecorder struct {
+	mock *MockAssetRepository
+}
+
+// NewMockAssetRepository creates a new mock instance.
+func NewMockAssetRepository(ctrl *gomock.Controller) *MockAssetRepository {
+	mock := &MockAssetRepository{ctrl: ctrl}
+	mock.recorder = &MockAssetRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAssetRepository) EXPECT() *MockAssetRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AssetExists mocks base method.
+func (m *MockAssetRepository) AssetExists(ctx context.Context, id string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssetExists", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AssetExists indicates an expected call of AssetExists.
+func (mr *MockAssetRepositoryMockRecorder) AssetExists(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssetExists", reflect.TypeOf((*MockAssetRepository)(nil).AssetExists), ctx, id)
+}
+
+// SaveAsset mocks base method.
+func (m *MockAssetRepository) SaveAsset(ctx context.Context, r io.Reader) (entity.Asset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAsset", ctx, r)
+	ret0, _ := ret[0].(entity.Asset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveAsset indicates an expected call of SaveAsset.
+func (mr *MockAssetRepositoryMockRecorder) SaveAsset(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAsset", reflect.TypeOf((*MockAssetRepository)(nil).SaveAsset), ctx, r)
+}
+
+// MockThumbnailSource is a mock of ThumbnailSource interface.
+type MockThumbnailSource struct {
+	ctrl     *gomock.Controller
+	recorder *MockThumbnailSourceMockRecorder
+	isgomock struct{}
+}
+
+// MockThumbnailSourceMockRecorder is the mock recorder for MockThumbnailSource.
+type MockThumbnailSourceMockRecorder struct {
+	mock *MockThumbnailSource
+}
+
+// NewMockThumbnailSource creates a new mock instance.
+func NewMockThumbnailSource(ctrl *gomock.Controller) *MockThumbnailSource {
+	mock := &MockThumbnailSource{ctrl: ctrl}
+	mock.recorder = &MockThumbnailSourceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockThumbnailSource) EXPECT() *MockThumbnailSourceMockRecorder {
+	return m.recorder
+}
+
+// OpenThumbnail mocks base method.
+func (m *MockThumbnailSource) OpenThumbnail(ctx context.Context, rawUrl string) (io.ReadCloser, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenThumbnail", ctx, rawUrl)
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// OpenThumbnail indicates an expected call of OpenThumbnail.
+func (mr *MockThumbnailSourceMockRecorder) OpenThumbnail(ctx, rawUrl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenThumbnail", reflect.TypeOf((*MockThumbnailSource)(nil).OpenThumbnail), ctx, rawUrl)
+}
+
+// MockContentCache is a mock of ContentCache interface.
+type MockContentCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockContentCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockContentCacheMockRecorder is the mock recorder for MockContentCache.
+type MockContentCacheMockRecorder struct {
+	mock *MockContentCache
+}
+
+// NewMockContentCache creates a new mock instance.
+func NewMockContentCache(ctrl *gomock.Controller) *MockContentCache {
+	mock := &MockContentCache{ctrl: ctrl}
+	mock.recorder = &MockContentCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockContentCache) EXPECT() *MockContentCacheMockRecorder {
+	return m.recorder
+}
+
+// Invalidate mocks base method.
+func (m *MockContentCache) Invalidate(tags ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []any{}
+	for _, a := range tags {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Invalidate", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Invalidate indicates an expected call of Invalidate.
+func (mr *MockContentCacheMockRecorder) Invalidate(tags ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invalidate", reflect.TypeOf((*MockContentCache)(nil).Invalidate), tags...)
+}
@@ -0,0 +1,77 @@
+package writer
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/pkg/blurhash"
+	"github.com/IsaacDSC/search_content/pkg/thumbnail"
+)
+
+// placeholderSize is the box the thumbnail is reduced to before hashing,
+// a BlurHash has no use for more detail than that.
+const placeholderSize = 32
+
+// computePlaceholders sets the placeholder of every video visited by each
+// (e.g. Enterprise.EachVideo) from its thumbnail. Placeholders are computed
+// on each write, so they follow the thumbnail when it changes. They are
+// optional: a thumbnail that isn't available locally, or fails to decode,
+// leaves the video without one.
+func (s *ContentUseCase) computePlaceholders(ctx context.Context, each func(fn func(video *entity.Video))) {
+	if s.thumbnails == nil {
+		return
+	}
+
+	// the same thumbnail is often repeated across the videos of a rule
+	computed := map[string]*entity.ImagePlaceholder{}
+	each(func(video *entity.Video) {
+		if video.TambnailUrl == "" {
+			return
+		}
+
+		placeholder, ok := computed[video.TambnailUrl]
+		if !ok {
+			var err error
+			placeholder, err = s.placeholder(ctx, video.TambnailUrl)
+			if err != nil {
+				log.Printf("failed to compute placeholder of %s: %v", video.TambnailUrl, err)
+			}
+			computed[video.TambnailUrl] = placeholder
+		}
+
+		video.Placeholder = placeholder
+	})
+}
+
+func (s *ContentUseCase) placeholder(ctx context.Context, rawUrl string) (*entity.ImagePlaceholder, error) {
+	rc, ok, err := s.thumbnails.OpenThumbnail(ctx, rawUrl)
+	if err != nil || !ok {
+		return nil, err
+	}
+	defer rc.Close()
+
+	img, _, err := thumbnail.Decode(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	small := thumbnail.Resize(img, thumbnail.Options{Width: placeholderSize, Height: placeholderSize, Fit: thumbnail.FitContain})
+
+	// more components along the longer side
+	x, y := 4, 3
+	if b := small.Bounds(); b.Dy() > b.Dx() {
+		x, y = 3, 4
+	}
+
+	hash, err := blurhash.Encode(small, x, y)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode blurhash: %w", err)
+	}
+
+	return &entity.ImagePlaceholder{
+		BlurHash:      hash,
+		DominantColor: thumbnail.HexColor(thumbnail.DominantColor(small)),
+	}, nil
+}
@@ -0,0 +1,95 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"github.com/IsaacDSC/search_content/pkg/sprite"
+	"github.com/IsaacDSC/search_content/pkg/thumbnail"
+	"github.com/IsaacDSC/search_content/pkg/webvtt"
+)
+
+// spriteQuality is the JPEG quality of the sprite sheets, the tiles are
+// small and shown for a moment while seeking.
+const spriteQuality = 70
+
+// savePreview composes the frames of the preview track into a sprite sheet,
+// stores it as an asset and its WebVTT track as a caption track, served as
+// text/vtt, and replaces the frames by the url of the track.
+func (s *ContentUseCase) savePreview(ctx context.Context, video *entity.Video) error {
+	if video.Metadata == nil || video.Metadata.Preview == nil || !video.Metadata.Preview.IsPending() {
+		return nil
+	}
+
+	preview := *video.Metadata.Preview
+
+	// frames are decoded one at a time, only the sheet is kept in memory
+	var sheet *sprite.Sheet
+	for i, frameUrl := range preview.Frames {
+		frame, err := s.openFrame(ctx, frameUrl)
+		if err != nil {
+			return fmt.Errorf("preview frame %d: %w", i, err)
+		}
+
+		if sheet == nil {
+			if sheet, err = sprite.NewSheet(len(preview.Frames), preview.TileWidth, 0, frame.Bounds().Size()); err != nil {
+				return err
+			}
+		}
+
+		sheet.Draw(i, frame)
+	}
+
+	var spriteBody bytes.Buffer
+	if err := thumbnail.Encode(&spriteBody, sheet.Image(), thumbnail.FormatJPEG, spriteQuality); err != nil {
+		return fmt.Errorf("failed to encode sprite: %w", err)
+	}
+
+	spriteAsset, err := s.assets.SaveAsset(ctx, &spriteBody)
+	if err != nil {
+		return fmt.Errorf("failed to save sprite: %w", err)
+	}
+
+	interval := time.Duration(preview.IntervalSeconds * float64(time.Second))
+	duration := time.Duration(video.Metadata.DurationSeconds * float64(time.Second))
+	spriteUrl := s.publicBaseUrl + spriteAsset.Path()
+
+	track := entity.NewCaptionTrack(string(webvtt.Encode(sheet.Cues(spriteUrl, interval, duration))))
+	if err := s.captions.SaveCaption(ctx, track); err != nil {
+		return fmt.Errorf("failed to save preview track: %w", err)
+	}
+
+	preview.Url = s.publicBaseUrl + track.Path()
+	preview.SpriteUrl = spriteUrl
+	preview.Frames = nil
+	video.Metadata.Preview = &preview
+
+	return nil
+}
+
+func (s *ContentUseCase) openFrame(ctx context.Context, rawUrl string) (image.Image, error) {
+	if s.thumbnails == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFrameNotFound, rawUrl)
+	}
+
+	rc, ok, err := s.thumbnails.OpenThumbnail(ctx, rawUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open frame: %w", err)
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrFrameNotFound, rawUrl)
+	}
+	defer rc.Close()
+
+	frame, _, err := thumbnail.Decode(rc)
+	if err != nil {
+		return nil, err
+	}
+
+	return frame, nil
+}
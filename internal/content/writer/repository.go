@@ -3,8 +3,42 @@ package writer
 import (
 	"context"
 	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"io"
 )
 
 type Repository interface {
 	Save(ctx context.Context, enterprise entity.Enterprise) error
+	// FindVideoReferences returns the endpoints of the rules that reference
+	// the catalog video, in order.
+	FindVideoReferences(ctx context.Context, id string) ([]string, error)
+}
+
+type CatalogRepository interface {
+	SaveVideo(ctx context.Context, video entity.CatalogVideo) error
+	VideoExists(ctx context.Context, id string) (bool, error)
+	// DeleteVideo returns ErrVideoNotFound when there is no video with the id.
+	DeleteVideo(ctx context.Context, id string) error
+}
+
+type CaptionRepository interface {
+	SaveCaption(ctx context.Context, track entity.CaptionTrack) error
+}
+
+type AssetRepository interface {
+	// SaveAsset streams r to the storage and returns the stored asset.
+	SaveAsset(ctx context.Context, r io.Reader) (entity.Asset, error)
+	AssetExists(ctx context.Context, id string) (bool, error)
+}
+
+// ThumbnailSource opens thumbnails that can be read without going to the
+// network, such as uploaded assets or a local copy of the CDN. ok is false
+// when rawUrl isn't available locally.
+type ThumbnailSource interface {
+	OpenThumbnail(ctx context.Context, rawUrl string) (rc io.ReadCloser, ok bool, err error)
+}
+
+// ContentCache holds the rendered content, tagged with the catalog videos
+// it joins (entity.CatalogCacheTag).
+type ContentCache interface {
+	Invalidate(tags ...string) error
 }
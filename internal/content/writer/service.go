@@ -1,31 +1,236 @@
 package writer
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/IsaacDSC/search_content/internal/content/entity"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
 )
 
 type Service interface {
 	Register(ctx context.Context, input VideoInputDto) error
+	UploadAsset(ctx context.Context, r io.Reader) (AssetOutputDto, error)
+
+	CreateVideo(ctx context.Context, input CatalogVideoInputDto) (CatalogVideoOutputDto, error)
+	UpdateVideo(ctx context.Context, id string, input CatalogVideoInputDto) (CatalogVideoOutputDto, error)
+	DeleteVideo(ctx context.Context, id string) error
+	GetVideoReferences(ctx context.Context, id string) (VideoReferencesOutputDto, error)
 }
 
 type ContentUseCase struct {
-	repository Repository
+	repository    Repository
+	captions      CaptionRepository
+	assets        AssetRepository
+	catalog       CatalogRepository
+	thumbnails    ThumbnailSource
+	cache         ContentCache
+	publicBaseUrl string
+
+	// references keeps a rule from referencing a catalog video while it is deleted
+	references sync.Mutex
 }
 
-func NewContentUseCase(repository Repository) *ContentUseCase {
-	return &ContentUseCase{repository: repository}
+// NewContentUseCase creates the writer use case. publicBaseUrl is the address
+// this service is reachable at, used to build the url of the files it serves.
+// thumbnails may be nil, in which case no image placeholder is computed,
+// and cache when the content isn't cached.
+func NewContentUseCase(repository Repository, captions CaptionRepository, assets AssetRepository, catalog CatalogRepository, thumbnails ThumbnailSource, cache ContentCache, publicBaseUrl string) *ContentUseCase {
+	return &ContentUseCase{
+		repository:    repository,
+		captions:      captions,
+		assets:        assets,
+		catalog:       catalog,
+		thumbnails:    thumbnails,
+		cache:         cache,
+		publicBaseUrl: strings.TrimSuffix(publicBaseUrl, "/"),
+	}
 }
 
 func (s *ContentUseCase) Register(ctx context.Context, input VideoInputDto) error {
+	if err := s.resolveAssets(ctx, &input); err != nil {
+		return err
+	}
+
 	entity, err := input.ToDomain()
 	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidVideo, err)
+	}
+
+	if err := s.saveFiles(ctx, &entity.Video); err != nil {
 		return err
 	}
 
+	for i := range entity.Playlist {
+		if err := s.saveFiles(ctx, &entity.Playlist[i]); err != nil {
+			return err
+		}
+	}
+
+	if entity.Fallback != nil {
+		if err := s.saveFiles(ctx, entity.Fallback); err != nil {
+			return err
+		}
+	}
+
+	s.computePlaceholders(ctx, entity.EachVideo)
+
+	s.references.Lock()
+	defer s.references.Unlock()
+
+	for _, id := range entity.CatalogIds() {
+		exists, err := s.catalog.VideoExists(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to check video: %w", err)
+		}
+
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrVideoNotFound, id)
+		}
+	}
+
 	if err = s.repository.Save(ctx, entity); err != nil {
 		return fmt.Errorf("failed to save entity: %w", err)
 	}
+	s.invalidateRule(entity.Url)
 
 	return nil
 }
+
+// invalidateRule drops the cached content of the rule, which may now vary
+// by visitor or serve another video. The rule is already saved, so a
+// failure only delays the change until the content expires.
+func (s *ContentUseCase) invalidateRule(u *url.URL) {
+	if s.cache == nil {
+		return
+	}
+
+	if err := s.cache.Invalidate(entity.RuleCacheTag(u)); err != nil {
+		log.Println("[WARNING] Failed to invalidate the content of rule", u.String()+":", err)
+	}
+}
+
+// resolveAssets replaces the asset ids of the input by the url the
+// assets are served at, after checking that they were uploaded.
+func (s *ContentUseCase) resolveAssets(ctx context.Context, input *VideoInputDto) error {
+	if err := s.resolveAsset(ctx, &input.VideoUrl, input.VideoAssetId); err != nil {
+		return err
+	}
+
+	if err := s.resolveAsset(ctx, &input.TambnailUrl, input.TambnailAssetId); err != nil {
+		return err
+	}
+
+	// the items are copied so the caller's slice is left untouched
+	playlist := make([]PlaylistItemInputDto, len(input.Playlist))
+	copy(playlist, input.Playlist)
+	for i := range playlist {
+		if err := s.resolveAsset(ctx, &playlist[i].VideoUrl, playlist[i].VideoAssetId); err != nil {
+			return fmt.Errorf("playlist item %d: %w", i, err)
+		}
+
+		if err := s.resolveAsset(ctx, &playlist[i].TambnailUrl, playlist[i].TambnailAssetId); err != nil {
+			return fmt.Errorf("playlist item %d: %w", i, err)
+		}
+	}
+
+	if input.Playlist != nil {
+		input.Playlist = playlist
+	}
+
+	if input.Fallback != nil {
+		fallback := *input.Fallback
+		if err := s.resolveAsset(ctx, &fallback.VideoUrl, fallback.VideoAssetId); err != nil {
+			return fmt.Errorf("fallback: %w", err)
+		}
+
+		if err := s.resolveAsset(ctx, &fallback.TambnailUrl, fallback.TambnailAssetId); err != nil {
+			return fmt.Errorf("fallback: %w", err)
+		}
+		input.Fallback = &fallback
+	}
+
+	return nil
+}
+
+func (s *ContentUseCase) resolveAsset(ctx context.Context, url *string, assetId string) error {
+	if assetId == "" {
+		return nil
+	}
+
+	if *url != "" {
+		return fmt.Errorf("asset %s and url %s must not be set together", assetId, *url)
+	}
+
+	exists, err := s.assets.AssetExists(ctx, assetId)
+	if err != nil {
+		return fmt.Errorf("failed to check asset: %w", err)
+	}
+
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrAssetNotFound, assetId)
+	}
+
+	*url = s.publicBaseUrl + entity.Asset{Id: assetId}.Path()
+
+	return nil
+}
+
+// saveFiles stores the files generated from the metadata of the video.
+func (s *ContentUseCase) saveFiles(ctx context.Context, video *entity.Video) error {
+	if err := s.saveCaptions(ctx, video); err != nil {
+		return err
+	}
+
+	return s.savePreview(ctx, video)
+}
+
+// saveCaptions stores the inline tracks of the video and replaces
+// their body by the url where they are served.
+func (s *ContentUseCase) saveCaptions(ctx context.Context, video *entity.Video) error {
+	if video.Metadata == nil {
+		return nil
+	}
+
+	for i, c := range video.Metadata.Captions {
+		if !c.IsInline() {
+			continue
+		}
+
+		track := entity.NewCaptionTrack(c.Body)
+		if err := s.captions.SaveCaption(ctx, track); err != nil {
+			return fmt.Errorf("failed to save caption: %w", err)
+		}
+
+		c.TrackId = track.Id
+		c.Url = s.publicBaseUrl + track.Path()
+		c.Body = ""
+		video.Metadata.Captions[i] = c
+	}
+
+	return nil
+}
+
+// UploadAsset stores the file read from r and returns the url it is served at.
+func (s *ContentUseCase) UploadAsset(ctx context.Context, r io.Reader) (AssetOutputDto, error) {
+	body := bufio.NewReader(r)
+	if _, err := body.Peek(1); errors.Is(err, io.EOF) {
+		return AssetOutputDto{}, ErrEmptyAsset
+	}
+
+	asset, err := s.assets.SaveAsset(ctx, body)
+	if err != nil {
+		return AssetOutputDto{}, fmt.Errorf("failed to save asset: %w", err)
+	}
+
+	return AssetOutputDto{
+		Id:   asset.Id,
+		Url:  s.publicBaseUrl + asset.Path(),
+		Size: asset.Size,
+	}, nil
+}
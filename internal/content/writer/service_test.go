@@ -1,11 +1,19 @@
 package writer
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"io"
 	"strings"
 	"testing"
 
+	"github.com/IsaacDSC/search_content/internal/content/entity"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
@@ -14,7 +22,7 @@ func TestService_Register(t *testing.T) {
 	tests := []struct {
 		name        string
 		input       VideoInputDto
-		setupMocks  func(mockRepo *MockRepository)
+		setupMocks  func(mockRepo *MockRepository, mockCaptions *MockCaptionRepository)
 		wantErr     bool
 		errContains string
 	}{
@@ -25,7 +33,7 @@ func TestService_Register(t *testing.T) {
 				TambnailUrl: "https://example.com/thumbnail.jpg",
 				Endpoint:    "/api/videos",
 			},
-			setupMocks: func(mockRepo *MockRepository) {
+			setupMocks: func(mockRepo *MockRepository, _ *MockCaptionRepository) {
 				mockRepo.EXPECT().
 					Save(gomock.Any(), gomock.Any()).
 					Return(nil)
@@ -39,7 +47,7 @@ func TestService_Register(t *testing.T) {
 				TambnailUrl: "https://example.com/thumbnail.jpg",
 				Endpoint:    "/api/videos",
 			},
-			setupMocks: func(mockRepo *MockRepository) {
+			setupMocks: func(mockRepo *MockRepository, _ *MockCaptionRepository) {
 				// No repository calls expected for invalid input
 			},
 			wantErr:     true,
@@ -52,7 +60,7 @@ func TestService_Register(t *testing.T) {
 				TambnailUrl: "https://example.com/thumbnail.jpg",
 				Endpoint:    "/api/videos",
 			},
-			setupMocks: func(mockRepo *MockRepository) {
+			setupMocks: func(mockRepo *MockRepository, _ *MockCaptionRepository) {
 				mockRepo.EXPECT().
 					Save(gomock.Any(), gomock.Any()).
 					Return(errors.New("database error"))
@@ -60,6 +68,91 @@ func TestService_Register(t *testing.T) {
 			wantErr:     true,
 			errContains: "failed to save entity",
 		},
+		{
+			name: "inline caption is stored and referenced by url",
+			input: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumbnail.jpg",
+				Endpoint:    "https://example.com/home",
+				Metadata: &MetadataInputDto{
+					Captions: []CaptionInputDto{
+						{Language: "pt-BR", Body: "WEBVTT\n\n00:00.000 --> 00:01.000\nOlá\n"},
+						{Language: "en", Url: "https://cdn.com/en.vtt"},
+					},
+				},
+			},
+			setupMocks: func(mockRepo *MockRepository, mockCaptions *MockCaptionRepository) {
+				track := entity.NewCaptionTrack("WEBVTT\n\n00:00.000 --> 00:01.000\nOlá\n")
+
+				mockCaptions.EXPECT().
+					SaveCaption(gomock.Any(), track).
+					Return(nil)
+
+				mockRepo.EXPECT().
+					Save(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, enterprise entity.Enterprise) error {
+						captions := enterprise.Video.Metadata.Captions
+						assert.Equal(t, "https://content.com/captions/"+track.Id+".vtt", captions[0].Url)
+						assert.Equal(t, track.Id, captions[0].TrackId)
+						assert.Empty(t, captions[0].Body)
+						assert.Equal(t, "https://cdn.com/en.vtt", captions[1].Url)
+						return nil
+					})
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid inline caption",
+			input: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumbnail.jpg",
+				Endpoint:    "https://example.com/home",
+				Metadata: &MetadataInputDto{
+					Captions: []CaptionInputDto{{Language: "pt-BR", Body: "not a webvtt file"}},
+				},
+			},
+			setupMocks: func(mockRepo *MockRepository, mockCaptions *MockCaptionRepository) {
+				// No repository calls expected for invalid input
+			},
+			wantErr:     true,
+			errContains: "invalid caption body",
+		},
+		{
+			name: "rendition codecs breaking out of the manifest attribute",
+			input: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumbnail.jpg",
+				Endpoint:    "https://example.com/home",
+				Renditions: []RenditionInputDto{{
+					PlaylistUrl: "https://cdn.example.com/720.m3u8",
+					Bandwidth:   2500000,
+					Codecs:      `avc1",URI="https://evil.com/a.m3u8`,
+				}},
+			},
+			setupMocks: func(mockRepo *MockRepository, mockCaptions *MockCaptionRepository) {
+				// No repository calls expected for invalid input
+			},
+			wantErr:     true,
+			errContains: "invalid video: rendition 0: rendition codecs",
+		},
+		{
+			name: "caption save error",
+			input: VideoInputDto{
+				VideoUrl:    "https://example.com/video.mp4",
+				TambnailUrl: "https://example.com/thumbnail.jpg",
+				Endpoint:    "https://example.com/home",
+				Metadata: &MetadataInputDto{
+					Captions: []CaptionInputDto{{Language: "pt-BR", Body: "WEBVTT\n"}},
+				},
+			},
+			setupMocks: func(mockRepo *MockRepository, mockCaptions *MockCaptionRepository) {
+				mockCaptions.EXPECT().
+					SaveCaption(gomock.Any(), gomock.Any()).
+					Return(errors.New("disk full"))
+			},
+			wantErr:     true,
+			errContains: "failed to save caption",
+		},
 	}
 
 	for _, tt := range tests {
@@ -68,16 +161,17 @@ func TestService_Register(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 
-			// Create the mock repository
+			// Create the mock repositories
 			mockRepo := NewMockRepository(ctrl)
+			mockCaptions := NewMockCaptionRepository(ctrl)
 
 			// Setup expectations based on the test case
 			if tt.setupMocks != nil {
-				tt.setupMocks(mockRepo)
+				tt.setupMocks(mockRepo, mockCaptions)
 			}
 
-			// Create service with mock repository
-			service := NewContentUseCase(mockRepo)
+			// Create service with mock repositories
+			service := NewContentUseCase(mockRepo, mockCaptions, NewMockAssetRepository(ctrl), nil, nil, nil, "https://content.com/")
 
 			// Execute the method being tested
 			err := service.Register(context.Background(), tt.input)
@@ -95,3 +189,302 @@ func TestService_Register(t *testing.T) {
 		})
 	}
 }
+
+func TestService_RegisterInvalidatesRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockCache := NewMockContentCache(ctrl)
+
+	// the rule gains an experiment, its cached content served one video to everyone
+	save := mockRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Return(nil)
+	mockCache.EXPECT().Invalidate("rule/example.com/home").Return(nil).After(save)
+
+	service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, mockCache, "https://content.com/")
+
+	err := service.Register(context.Background(), VideoInputDto{
+		VideoUrl:    "https://example.com/video.mp4",
+		TambnailUrl: "https://example.com/thumbnail.jpg",
+		Endpoint:    "https://example.com/home",
+		Experiment: &ExperimentInputDto{Id: "home", Variants: []VariantInputDto{
+			{Id: "a", Weight: 50, VideoUrl: "https://example.com/a.mp4", TambnailUrl: "https://example.com/a.jpg"},
+			{Id: "b", Weight: 50, VideoUrl: "https://example.com/b.mp4", TambnailUrl: "https://example.com/b.jpg"},
+		}},
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestService_UploadAsset(t *testing.T) {
+	asset := entity.Asset{Id: strings.Repeat("a", 64), Size: 5}
+
+	tests := []struct {
+		name       string
+		body       string
+		setupMocks func(mockAssets *MockAssetRepository)
+		want       AssetOutputDto
+		wantErr    error
+	}{
+		{
+			name: "stores the body",
+			body: "video",
+			setupMocks: func(mockAssets *MockAssetRepository) {
+				mockAssets.EXPECT().
+					SaveAsset(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, r io.Reader) (entity.Asset, error) {
+						body, _ := io.ReadAll(r)
+						assert.Equal(t, "video", string(body))
+						return asset, nil
+					})
+			},
+			want: AssetOutputDto{Id: asset.Id, Url: "https://content.com/assets/" + asset.Id, Size: 5},
+		},
+		{
+			name:    "empty body",
+			body:    "",
+			wantErr: ErrEmptyAsset,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAssets := NewMockAssetRepository(ctrl)
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockAssets)
+			}
+
+			service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), mockAssets, nil, nil, nil, "https://content.com/")
+
+			got, err := service.UploadAsset(context.Background(), strings.NewReader(tt.body))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestService_RegisterWithAssets(t *testing.T) {
+	videoId := strings.Repeat("a", 64)
+	thumbnailId := strings.Repeat("b", 64)
+
+	t.Run("asset ids become asset urls", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockAssets := NewMockAssetRepository(ctrl)
+
+		mockAssets.EXPECT().AssetExists(gomock.Any(), videoId).Return(true, nil)
+		mockAssets.EXPECT().AssetExists(gomock.Any(), thumbnailId).Return(true, nil)
+		mockRepo.EXPECT().
+			Save(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, enterprise entity.Enterprise) error {
+				assert.Equal(t, "https://content.com/assets/"+videoId, enterprise.Video.VideoUrl)
+				assert.Equal(t, "https://content.com/assets/"+thumbnailId, enterprise.Video.TambnailUrl)
+				return nil
+			})
+
+		service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), mockAssets, nil, nil, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), VideoInputDto{
+			Endpoint:        "https://example.com/home",
+			VideoAssetId:    videoId,
+			TambnailAssetId: thumbnailId,
+		})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing asset", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockAssets := NewMockAssetRepository(ctrl)
+		mockAssets.EXPECT().AssetExists(gomock.Any(), videoId).Return(false, nil)
+
+		service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), mockAssets, nil, nil, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), VideoInputDto{
+			Endpoint:    "https://example.com/home",
+			TambnailUrl: "https://example.com/thumb.jpg",
+			Playlist:    []PlaylistItemInputDto{{VideoAssetId: videoId, TambnailUrl: "https://example.com/thumb.jpg"}},
+		})
+
+		assert.ErrorIs(t, err, ErrAssetNotFound)
+	})
+
+	t.Run("asset id and url together", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, nil, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), VideoInputDto{
+			Endpoint:     "https://example.com/home",
+			VideoUrl:     "https://example.com/video.mp4",
+			VideoAssetId: videoId,
+			TambnailUrl:  "https://example.com/thumb.jpg",
+		})
+
+		assert.ErrorContains(t, err, "must not be set together")
+	})
+}
+
+func TestService_RegisterPlaceholders(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 32))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.NRGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	var thumb bytes.Buffer
+	assert.NoError(t, png.Encode(&thumb, img))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockRepository(ctrl)
+	mockThumbnails := NewMockThumbnailSource(ctrl)
+
+	// repeated thumbnails are opened once
+	mockThumbnails.EXPECT().
+		OpenThumbnail(gomock.Any(), "https://cdn.com/red.png").
+		Return(io.NopCloser(bytes.NewReader(thumb.Bytes())), true, nil)
+	mockThumbnails.EXPECT().
+		OpenThumbnail(gomock.Any(), "https://other.com/thumb.jpg").
+		Return(nil, false, nil)
+	mockThumbnails.EXPECT().
+		OpenThumbnail(gomock.Any(), "https://cdn.com/broken.png").
+		Return(io.NopCloser(strings.NewReader("not an image")), true, nil)
+
+	mockRepo.EXPECT().
+		Save(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, enterprise entity.Enterprise) error {
+			placeholder := enterprise.Video.Placeholder
+			if assert.NotNil(t, placeholder) {
+				assert.Len(t, placeholder.BlurHash, 4+2*4*3)
+				assert.Equal(t, "#ff0000", placeholder.DominantColor)
+			}
+
+			assert.Equal(t, placeholder, enterprise.Playlist[0].Placeholder)
+			assert.Nil(t, enterprise.Playlist[1].Placeholder)
+			assert.Nil(t, enterprise.Playlist[2].Placeholder)
+			return nil
+		})
+
+	service := NewContentUseCase(mockRepo, NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, mockThumbnails, nil, "https://content.com/")
+
+	err := service.Register(context.Background(), VideoInputDto{
+		Endpoint:    "https://example.com/home",
+		VideoUrl:    "https://cdn.com/a.mp4",
+		TambnailUrl: "https://cdn.com/red.png",
+		Playlist: []PlaylistItemInputDto{
+			{VideoUrl: "https://cdn.com/a.mp4", TambnailUrl: "https://cdn.com/red.png"},
+			{VideoUrl: "https://cdn.com/b.mp4", TambnailUrl: "https://other.com/thumb.jpg"},
+			{VideoUrl: "https://cdn.com/c.mp4", TambnailUrl: "https://cdn.com/broken.png"},
+		},
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestService_RegisterPreview(t *testing.T) {
+	frame := image.NewNRGBA(image.Rect(0, 0, 320, 180))
+	var frameBody bytes.Buffer
+	assert.NoError(t, png.Encode(&frameBody, frame))
+
+	spriteAsset := entity.Asset{Id: strings.Repeat("a", 64)}
+
+	input := func(frames ...string) VideoInputDto {
+		return VideoInputDto{
+			Endpoint:    "https://example.com/home",
+			VideoUrl:    "https://cdn.com/a.mp4",
+			TambnailUrl: "https://cdn.com/a.jpg",
+			Metadata: &MetadataInputDto{
+				DurationSeconds: 5,
+				Preview:         &PreviewInputDto{FrameUrls: frames, IntervalSeconds: 2},
+			},
+		}
+	}
+
+	t.Run("frames become a sprite and a track", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := NewMockRepository(ctrl)
+		mockCaptions := NewMockCaptionRepository(ctrl)
+		mockAssets := NewMockAssetRepository(ctrl)
+		mockThumbnails := NewMockThumbnailSource(ctrl)
+
+		mockThumbnails.EXPECT().
+			OpenThumbnail(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, rawUrl string) (io.ReadCloser, bool, error) {
+				if strings.HasPrefix(rawUrl, "https://content.com/frames/") {
+					return io.NopCloser(bytes.NewReader(frameBody.Bytes())), true, nil
+				}
+				return nil, false, nil
+			}).
+			AnyTimes()
+
+		mockAssets.EXPECT().
+			SaveAsset(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, r io.Reader) (entity.Asset, error) {
+				sheet, format, err := image.Decode(r)
+				assert.NoError(t, err)
+				assert.Equal(t, "jpeg", format)
+				// three 160x90 tiles in a single row
+				assert.Equal(t, image.Rect(0, 0, 480, 90), sheet.Bounds())
+				return spriteAsset, nil
+			})
+
+		// the track is served as text/vtt with the captions
+		var track entity.CaptionTrack
+		mockCaptions.EXPECT().
+			SaveCaption(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, saved entity.CaptionTrack) error {
+				assert.Contains(t, saved.Body, "00:00:04.000 --> 00:00:05.000\nhttps://content.com/assets/"+spriteAsset.Id+"#xywh=320,0,160,90\n")
+				track = saved
+				return nil
+			})
+
+		mockRepo.EXPECT().
+			Save(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, enterprise entity.Enterprise) error {
+				preview := enterprise.Video.Metadata.Preview
+				assert.Equal(t, "https://content.com"+track.Path(), preview.Url)
+				assert.Equal(t, "https://content.com/assets/"+spriteAsset.Id, preview.SpriteUrl)
+				assert.Empty(t, preview.Frames)
+				return nil
+			})
+
+		service := NewContentUseCase(mockRepo, mockCaptions, mockAssets, nil, mockThumbnails, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), input(
+			"https://content.com/frames/1.png",
+			"https://content.com/frames/2.png",
+			"https://content.com/frames/3.png",
+		))
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("frame not available locally", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockThumbnails := NewMockThumbnailSource(ctrl)
+		mockThumbnails.EXPECT().OpenThumbnail(gomock.Any(), gomock.Any()).Return(nil, false, nil)
+
+		service := NewContentUseCase(NewMockRepository(ctrl), NewMockCaptionRepository(ctrl), NewMockAssetRepository(ctrl), nil, mockThumbnails, nil, "https://content.com/")
+
+		err := service.Register(context.Background(), input("https://cdn.com/frame.png"))
+
+		assert.ErrorIs(t, err, ErrFrameNotFound)
+	})
+}
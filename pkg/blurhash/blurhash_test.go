@@ -0,0 +1,58 @@
+package blurhash
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncode_SolidColor(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 24))
+	draw.Draw(img, img.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	hash, err := Encode(img, 4, 3)
+	require.NoError(t, err)
+
+	assert.Len(t, hash, 4+2*4*3)
+	// size flag "L" is 4x3 and the DC component is #FF0000
+	assert.Equal(t, "L", hash[:1])
+	assert.Equal(t, "TI:j", hash[2:6])
+}
+
+func TestEncode_Gradient(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	for x := 0; x < 32; x++ {
+		for y := 0; y < 32; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 8), G: uint8(y * 8), B: 128, A: 255})
+		}
+	}
+
+	hash, err := Encode(img, 4, 3)
+	require.NoError(t, err)
+	assert.Len(t, hash, 4+2*4*3)
+
+	solid := image.NewRGBA(image.Rect(0, 0, 32, 32))
+	draw.Draw(solid, solid.Bounds(), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+
+	red, err := Encode(solid, 4, 3)
+	require.NoError(t, err)
+	assert.NotEqual(t, red, hash)
+
+	single, err := Encode(img, 1, 1)
+	require.NoError(t, err)
+	assert.Len(t, single, 6)
+}
+
+func TestEncode_InvalidComponents(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+
+	_, err := Encode(img, 0, 3)
+	assert.ErrorIs(t, err, ErrInvalidComponents)
+
+	_, err = Encode(img, 4, 10)
+	assert.ErrorIs(t, err, ErrInvalidComponents)
+}
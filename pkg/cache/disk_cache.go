@@ -0,0 +1,188 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// DiskCache keeps files on the local disk up to a maximum total size,
+// evicting the least recently used ones. It is meant for derived data
+// that can always be rebuilt, such as resized images.
+type DiskCache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	size  int64
+	items map[string]*list.Element // file name -> element of lru
+	lru   *list.List               // front is the most recently used
+}
+
+type diskEntry struct {
+	name string
+	size int64
+}
+
+// NewDiskCache creates the cache in dir, indexing the files left by a
+// previous run from the most to the least recently modified.
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type existing struct {
+		diskEntry
+		modTime int64
+	}
+
+	var files []existing
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		if filepath.Ext(e.Name()) == ".tmp" {
+			os.Remove(filepath.Join(dir, e.Name()))
+			continue
+		}
+
+		files = append(files, existing{diskEntry{e.Name(), info.Size()}, info.ModTime().UnixNano()})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+
+	c := &DiskCache{dir: dir, maxBytes: maxBytes, items: make(map[string]*list.Element), lru: list.New()}
+	for _, f := range files {
+		c.items[f.name] = c.lru.PushBack(f.diskEntry)
+		c.size += f.size
+	}
+
+	c.mu.Lock()
+	c.evict()
+	c.mu.Unlock()
+
+	return c, nil
+}
+
+func diskFileName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the data stored for key.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	name := diskFileName(key)
+
+	c.mu.Lock()
+	el, ok := c.items[name]
+	if ok {
+		c.lru.MoveToFront(el)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	// an open file stays readable even if it is evicted meanwhile
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if err != nil {
+		c.mu.Lock()
+		c.remove(name)
+		c.mu.Unlock()
+		return nil, false
+	}
+
+	return data, true
+}
+
+// Set stores data for key, evicting old entries to stay under the maximum size.
+// Data larger than the maximum size is not stored.
+func (c *DiskCache) Set(key string, data []byte) error {
+	size := int64(len(data))
+	if size > c.maxBytes {
+		return nil
+	}
+
+	name := diskFileName(key)
+	path := filepath.Join(c.dir, name)
+
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store cache file: %w", err)
+	}
+
+	if el, ok := c.items[name]; ok {
+		c.size -= el.Value.(diskEntry).size
+		c.lru.Remove(el)
+	}
+
+	c.items[name] = c.lru.PushFront(diskEntry{name: name, size: size})
+	c.size += size
+	c.evict()
+
+	return nil
+}
+
+// Size returns the total size of the cached files.
+func (c *DiskCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.size
+}
+
+// evict removes the least recently used files until the cache fits. c.mu must be held.
+func (c *DiskCache) evict() {
+	for c.size > c.maxBytes {
+		el := c.lru.Back()
+		if el == nil {
+			return
+		}
+
+		name := el.Value.(diskEntry).name
+		os.Remove(filepath.Join(c.dir, name))
+		c.remove(name)
+	}
+}
+
+// remove drops name from the index. c.mu must be held.
+func (c *DiskCache) remove(name string) {
+	el, ok := c.items[name]
+	if !ok {
+		return
+	}
+
+	c.size -= el.Value.(diskEntry).size
+	c.lru.Remove(el)
+	delete(c.items, name)
+}
@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := NewDiskCache(dir, 10)
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set("a", []byte("aaaa")))
+	require.NoError(t, c.Set("b", []byte("bbbb")))
+
+	// a becomes the most recently used, so b is evicted next
+	got, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "aaaa", string(got))
+
+	require.NoError(t, c.Set("c", []byte("cccc")))
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, int64(8), c.Size())
+
+	// larger than the whole cache
+	require.NoError(t, c.Set("huge", []byte(strings.Repeat("x", 11))))
+	_, ok = c.Get("huge")
+	assert.False(t, ok)
+
+	entries, _ := os.ReadDir(dir)
+	assert.Len(t, entries, 2)
+}
+
+func TestDiskCache_ReopenKeepsEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	c, err := NewDiskCache(dir, 10)
+	require.NoError(t, err)
+	require.NoError(t, c.Set("a", []byte("aaaa")))
+	require.NoError(t, c.Set("b", []byte("bbbb")))
+
+	reopened, err := NewDiskCache(dir, 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), reopened.Size())
+}
@@ -57,8 +57,13 @@ func (c *LRUCache) Get(path string) (map[string]any, error) {
 	return content, nil
 }
 
-// Set stores content with TTL based on popularity
-func (c *LRUCache) Set(path string, content map[string]any) error {
+// tagTTL is how long the keys of a tag are kept, as long as the content
+// cached the longest.
+const tagTTL = 24 * time.Hour
+
+// Set stores content with TTL based on popularity, and adds its key to the
+// set of each tag so that Invalidate finds it
+func (c *LRUCache) Set(path string, content map[string]any, tags ...string) error {
 	key := c.prefix + path
 
 	data, err := json.Marshal(content)
@@ -74,7 +79,35 @@ func (c *LRUCache) Set(path string, content map[string]any) error {
 		ttl = 24 * time.Hour // Popular content stays longer -> TODO: passar para utilizar em uma env
 	}
 
-	return c.client.Set(c.ctx, key, data, ttl).Err()
+	pipe := c.client.TxPipeline()
+	pipe.Set(c.ctx, key, data, ttl)
+	for _, tag := range tags {
+		pipe.SAdd(c.ctx, c.tagKey(tag), key)
+		pipe.Expire(c.ctx, c.tagKey(tag), tagTTL)
+	}
+
+	_, err = pipe.Exec(c.ctx)
+	return err
+}
+
+// Invalidate deletes the content stored with any of the tags
+func (c *LRUCache) Invalidate(tags ...string) error {
+	for _, tag := range tags {
+		keys, err := c.client.SMembers(c.ctx, c.tagKey(tag)).Result()
+		if err != nil {
+			return err
+		}
+
+		if err := c.client.Del(c.ctx, append(keys, c.tagKey(tag))...).Err(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *LRUCache) tagKey(tag string) string {
+	return "content:tag:" + tag
 }
 
 // PrewarmCache loads top popular content
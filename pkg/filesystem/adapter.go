@@ -4,6 +4,7 @@ package filesystem
 
 import (
 	"context"
+	"io"
 )
 
 // Driver defines the operations available for interacting with
@@ -14,13 +15,49 @@ type Driver interface {
 	FileExists(ctx context.Context, key FileName) (bool, error)
 
 	// Save stores data to a file specified by key.
-	// The data can be any type and will be marshaled to JSON.
+	// The data can be any type and will be marshaled with the format
+	// of the driver.
 	Save(ctx context.Context, key FileName, data any) error
 
 	// Get retrieves data from a file specified by key.
-	// It reads the file and unmarshals the JSON content.
-	Get(ctx context.Context, key FileName) (any, error)
+	// It reads the file and unmarshals its content into v,
+	// which must be a pointer.
+	Get(ctx context.Context, key FileName, v any) error
+
+	// Update replaces the content of the file specified by key by what fn
+	// returns for its current content, which fn decodes with current
+	// (nil when the file doesn't exist), atomically with respect to the
+	// other operations on the file.
+	Update(ctx context.Context, key FileName, fn func(current DecodeFunc) (any, error)) error
+
+	// Delete removes the file specified by key.
+	// Returns ErrFileNotFound if there is no such file.
+	Delete(ctx context.Context, key FileName) error
+
+	// List returns the files saved with NewFileName, in lexical order.
+	// Files in subdirectories (e.g. "captions/<id>") are not included.
+	List(ctx context.Context) ([]FileName, error)
+}
+
+// DecodeFunc unmarshals the content of a file into v, which must be a pointer.
+type DecodeFunc func(v any) error
+
+// BlobStore defines the operations available for storing binary
+// files, such as videos and images, which are streamed instead of
+// being loaded in memory.
+type BlobStore interface {
+	// Put streams r to the store and returns the blob info.
+	// Blobs are content addressed: the id is the SHA-256 of the content,
+	// so storing the same content twice returns the same id.
+	Put(ctx context.Context, r io.Reader) (BlobInfo, error)
+
+	// Open returns a reader of the blob, which supports seeking for
+	// partial reads. Returns ErrFileNotFound if there is no blob with the id.
+	Open(ctx context.Context, id string) (io.ReadSeekCloser, BlobInfo, error)
 }
 
 // Ensure FileSystem implements the Driver interface
 var _ Driver = (*FileSystem)(nil)
+
+// Ensure BlobFileSystem implements the BlobStore interface
+var _ BlobStore = (*BlobFileSystem)(nil)
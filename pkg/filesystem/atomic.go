@@ -0,0 +1,140 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempMarker is part of the name of every temporary file. They are named
+// ".<file>.tmp-<random>", so they never end with fileNameExt and are never listed.
+const tempMarker = ".tmp-"
+
+// file is the part of *os.File used to write a temporary file.
+type file interface {
+	io.Writer
+	Name() string
+	Chmod(mode os.FileMode) error
+	Sync() error
+	Close() error
+}
+
+// fileOps are the system calls of an atomic write. Tests replace them
+// to simulate a full disk or a crash in the middle of a write.
+type fileOps interface {
+	CreateTemp(dir, pattern string) (file, error)
+	Rename(oldPath, newPath string) error
+	Remove(path string) error
+	// SyncDir flushes the directory entry, so a rename survives a power loss.
+	SyncDir(dir string) error
+}
+
+type osFileOps struct{}
+
+func (osFileOps) CreateTemp(dir, pattern string) (file, error) {
+	return os.CreateTemp(dir, pattern)
+}
+
+func (osFileOps) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+func (osFileOps) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (osFileOps) SyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// isTempFile reports whether name is a temporary file left by writeFile.
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") && strings.Contains(name, tempMarker)
+}
+
+// writeFile replaces the file at path with data so that a reader, or the
+// process after a crash, sees either the old or the new content, never a
+// mix of both: data is written and synced to a temporary file in the same
+// directory, which is renamed over path, then the directory is synced.
+func (fs *FileSystem) writeFile(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+
+	tmp, err := fs.ops.CreateTemp(dir, "."+filepath.Base(path)+tempMarker+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmp.Close()
+			fs.ops.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set file mode: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+
+	if err := fs.ops.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	renamed = true
+
+	if err := fs.ops.SyncDir(dir); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+
+	return nil
+}
+
+// recoverTempFiles removes the temporary files left in the data directory
+// by writes interrupted by a crash. Their Save never returned, so the file
+// they were replacing still holds the last committed content.
+func (fs *FileSystem) recoverTempFiles() (int, error) {
+	removed := 0
+
+	err := filepath.WalkDir(fs.dir, func(path string, entry os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() || !isTempFile(entry.Name()) {
+			return nil
+		}
+
+		if err := fs.ops.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		removed++
+
+		return nil
+	})
+
+	return removed, err
+}
@@ -0,0 +1,203 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// faultyOps fails the atomic write at the configured step. With crash set,
+// the temporary file is not removed, as if the process died mid-write.
+type faultyOps struct {
+	osFileOps
+	diskSpace  int // bytes written before the disk is full, -1 is unlimited
+	failSync   bool
+	failRename bool
+	crash      bool
+}
+
+func (o faultyOps) CreateTemp(dir, pattern string) (file, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return &faultyFile{File: f, ops: o}, nil
+}
+
+func (o faultyOps) Rename(oldPath, newPath string) error {
+	if o.failRename {
+		return syscall.EIO
+	}
+
+	return os.Rename(oldPath, newPath)
+}
+
+func (o faultyOps) Remove(path string) error {
+	if o.crash {
+		return nil
+	}
+
+	return os.Remove(path)
+}
+
+type faultyFile struct {
+	*os.File
+	ops     faultyOps
+	written int
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) {
+	if f.ops.diskSpace >= 0 && f.written+len(p) > f.ops.diskSpace {
+		n, _ := f.File.Write(p[:f.ops.diskSpace-f.written])
+		f.written += n
+		return n, syscall.ENOSPC
+	}
+
+	n, err := f.File.Write(p)
+	f.written += n
+	return n, err
+}
+
+func (f *faultyFile) Sync() error {
+	if f.ops.failSync {
+		return syscall.EIO
+	}
+
+	return f.File.Sync()
+}
+
+func TestFileSystem_SaveInterrupted(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  faultyOps
+	}{
+		{name: "disk full", ops: faultyOps{diskSpace: 10}},
+		{name: "sync fails", ops: faultyOps{diskSpace: -1, failSync: true}},
+		{name: "rename fails", ops: faultyOps{diskSpace: -1, failRename: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			fs := newFileSystem(t.TempDir(), osFileOps{})
+			fileName := NewFileName("shop.com")
+
+			if err := fs.Save(ctx, fileName, `{"version":1}`); err != nil {
+				t.Fatalf("Failed to save data: %v", err)
+			}
+
+			fs.ops = tt.ops
+			if err := fs.Save(ctx, fileName, `{"version":2,"padding":"0123456789"}`); err == nil {
+				t.Fatal("Expected the interrupted save to fail")
+			}
+
+			var data map[string]any
+			if err := fs.Get(ctx, fileName, &data); err != nil {
+				t.Fatalf("Failed to get data after interrupted save: %v", err)
+			}
+
+			if version := data["version"]; version != float64(1) {
+				t.Fatalf("Expected the previous content, got version %v", version)
+			}
+
+			entries, err := os.ReadDir(fs.dir)
+			if err != nil {
+				t.Fatalf("Failed to read directory: %v", err)
+			}
+
+			if len(entries) != 1 {
+				t.Fatalf("Expected the temporary file to be removed, got %d files", len(entries))
+			}
+		})
+	}
+}
+
+func TestFileSystem_RecoverTempFiles(t *testing.T) {
+	ctx := context.Background()
+	fs := newFileSystem(t.TempDir(), osFileOps{})
+
+	for _, key := range []string{"shop.com", "videos/intro"} {
+		if err := fs.Save(ctx, NewFileName(key), `{"version":1}`); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	// the process dies in the middle of both writes
+	fs.ops = faultyOps{diskSpace: 5, crash: true}
+	for _, key := range []string{"shop.com", "videos/intro"} {
+		if err := fs.Save(ctx, NewFileName(key), `{"version":2}`); err == nil {
+			t.Fatal("Expected the interrupted save to fail")
+		}
+	}
+
+	// the leftovers are never listed nor read
+	files, err := fs.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+
+	if len(files) != 1 || files[0] != NewFileName("shop.com") {
+		t.Fatalf("Unexpected files: %v", files)
+	}
+
+	fs.ops = osFileOps{}
+	removed, err := fs.recoverTempFiles()
+	if err != nil {
+		t.Fatalf("Failed to recover temporary files: %v", err)
+	}
+
+	if removed != 2 {
+		t.Fatalf("Expected 2 temporary files to be removed, got %d", removed)
+	}
+
+	for _, key := range []string{"shop.com", "videos/intro"} {
+		var data map[string]any
+		if err := fs.Get(ctx, NewFileName(key), &data); err != nil {
+			t.Fatalf("Failed to get %s: %v", key, err)
+		}
+
+		if version := data["version"]; version != float64(1) {
+			t.Fatalf("Expected the committed content of %s, got version %v", key, version)
+		}
+	}
+
+	for _, dir := range []string{fs.dir, filepath.Join(fs.dir, "videos")} {
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			if isTempFile(entry.Name()) {
+				t.Fatalf("Temporary file %s was not removed", entry.Name())
+			}
+		}
+	}
+}
+
+func TestFileSystem_RecoverTempFilesWithoutData(t *testing.T) {
+	fs := newFileSystem(t.TempDir(), osFileOps{})
+
+	removed, err := fs.recoverTempFiles()
+	if err != nil || removed != 0 {
+		t.Fatalf("Expected nothing to recover, got %d, %v", removed, err)
+	}
+}
+
+func TestFileSystem_SaveKeepsMode(t *testing.T) {
+	fs := newFileSystem(t.TempDir(), osFileOps{})
+	fileName := NewFileName("shop.com")
+
+	if err := fs.Save(context.Background(), fileName, "{}"); err != nil {
+		t.Fatalf("Failed to save data: %v", err)
+	}
+
+	path, _ := fs.getFullPath(fileName)
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	if stat.Mode().Perm() != 0644 {
+		t.Fatalf("Expected mode 0644, got %v", stat.Mode().Perm())
+	}
+}
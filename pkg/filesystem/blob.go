@@ -0,0 +1,141 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// blobDir is where blobs are stored, relative to the base directory.
+// Each blob lives in a sub directory named after the first two characters
+// of its id, so a single directory never holds too many files.
+const blobDir = "assets/blobs"
+
+var blobIdPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// BlobInfo describes a stored blob.
+type BlobInfo struct {
+	Id      string // hex encoded SHA-256 of the content
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobFileSystem stores blobs as plain files in the local filesystem.
+type BlobFileSystem struct {
+	baseDir string // base directory for all file operations
+}
+
+// NewBlobFileSystem creates a BlobFileSystem using the current working
+// directory as the base directory, like NewFileSystem.
+func NewBlobFileSystem() *BlobFileSystem {
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = "."
+	}
+
+	return &BlobFileSystem{baseDir: cwd}
+}
+
+// blobPath returns the absolute path of the blob with the given id.
+func (bs *BlobFileSystem) blobPath(id string) string {
+	return filepath.Join(bs.baseDir, blobDir, id[:2], id)
+}
+
+// Put writes r to a temporary file while hashing it, then moves the file
+// to its content address. Readers never see a partially written blob.
+func (bs *BlobFileSystem) Put(ctx context.Context, r io.Reader) (BlobInfo, error) {
+	tmpDir := filepath.Join(bs.baseDir, blobDir, "tmp")
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(tmpDir, "upload-*")
+	if err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to create temporary blob: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), contextReader{ctx: ctx, r: r})
+	if err != nil {
+		tmp.Close()
+		return BlobInfo{}, fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return BlobInfo{}, fmt.Errorf("failed to sync blob: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to close blob: %w", err)
+	}
+
+	id := hex.EncodeToString(hash.Sum(nil))
+	path := bs.blobPath(id)
+
+	if stat, err := os.Stat(path); err == nil {
+		// the same content was already stored
+		return BlobInfo{Id: id, Size: stat.Size(), ModTime: stat.ModTime()}, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return BlobInfo{}, fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return BlobInfo{}, err
+	}
+
+	return BlobInfo{Id: id, Size: size, ModTime: stat.ModTime()}, nil
+}
+
+// Open opens the blob for reading. The caller must close the returned reader.
+func (bs *BlobFileSystem) Open(ctx context.Context, id string) (io.ReadSeekCloser, BlobInfo, error) {
+	if !blobIdPattern.MatchString(id) {
+		return nil, BlobInfo{}, ErrInvalidBlobId
+	}
+
+	file, err := os.Open(bs.blobPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, BlobInfo{}, ErrFileNotFound
+	}
+
+	if err != nil {
+		return nil, BlobInfo{}, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, BlobInfo{}, fmt.Errorf("failed to stat blob: %w", err)
+	}
+
+	return file, BlobInfo{Id: id, Size: stat.Size(), ModTime: stat.ModTime()}, nil
+}
+
+// contextReader stops a long upload as soon as the context is canceled.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (cr contextReader) Read(p []byte) (int, error) {
+	if err := cr.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	return cr.r.Read(p)
+}
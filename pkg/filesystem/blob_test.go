@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBlobFileSystem_PutAndOpen(t *testing.T) {
+	bs := &BlobFileSystem{baseDir: t.TempDir()}
+	ctx := context.Background()
+	content := []byte("fake video content")
+
+	info, err := bs.Put(ctx, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to put blob: %v", err)
+	}
+
+	if len(info.Id) != 64 || info.Size != int64(len(content)) {
+		t.Fatalf("Unexpected blob info: %+v", info)
+	}
+
+	again, err := bs.Put(ctx, bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("Failed to put blob again: %v", err)
+	}
+	if again.Id != info.Id {
+		t.Errorf("Expected the same id for the same content, got %s and %s", info.Id, again.Id)
+	}
+
+	file, openInfo, err := bs.Open(ctx, info.Id)
+	if err != nil {
+		t.Fatalf("Failed to open blob: %v", err)
+	}
+	defer file.Close()
+
+	if openInfo.Size != info.Size {
+		t.Errorf("Expected size %d, got %d", info.Size, openInfo.Size)
+	}
+
+	if _, err := file.Seek(5, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek blob: %v", err)
+	}
+	rest, _ := io.ReadAll(file)
+	if string(rest) != "video content" {
+		t.Errorf("Expected %q, got %q", "video content", rest)
+	}
+
+	tmpFiles, _ := os.ReadDir(bs.baseDir + "/" + blobDir + "/tmp")
+	if len(tmpFiles) != 0 {
+		t.Errorf("Expected no temporary files left, got %d", len(tmpFiles))
+	}
+}
+
+func TestBlobFileSystem_Open(t *testing.T) {
+	bs := &BlobFileSystem{baseDir: t.TempDir()}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{name: "missing blob", id: strings.Repeat("a", 64), wantErr: ErrFileNotFound},
+		{name: "path traversal", id: "../../etc/passwd", wantErr: ErrInvalidBlobId},
+		{name: "upper case id", id: strings.Repeat("A", 64), wantErr: ErrInvalidBlobId},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := bs.Open(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBlobFileSystem_PutCanceled(t *testing.T) {
+	bs := &BlobFileSystem{baseDir: t.TempDir()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := bs.Put(ctx, strings.NewReader("content")); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
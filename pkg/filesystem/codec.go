@@ -0,0 +1,246 @@
+package filesystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Codec encodes the content of the files.
+type Codec interface {
+	// Id identifies the codec in the header of the files.
+	Id() byte
+	Name() string
+	Marshal(v any) ([]byte, error)
+	// Unmarshal decodes data into v, which must be a pointer.
+	Unmarshal(data []byte, v any) error
+}
+
+// Compression compresses the encoded content of the files.
+type Compression interface {
+	// Id identifies the compression in the header of the files.
+	Id() byte
+	Name() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+// Format is how the files are written. Each file records its format in a
+// header, so a FileSystem reads the files of every format whatever the
+// one it writes.
+type Format struct {
+	Codec       Codec
+	Compression Compression // nil when not compressed
+}
+
+// JSONFormat writes plain JSON files without a header, as they were
+// written before the other formats existed.
+var JSONFormat = Format{Codec: JSONCodec}
+
+var (
+	codecs       = map[byte]Codec{}
+	compressions = map[byte]Compression{}
+)
+
+func init() {
+	for _, codec := range []Codec{JSONCodec, MessagePackCodec, ProtobufCodec} {
+		codecs[codec.Id()] = codec
+	}
+
+	for _, compression := range []Compression{GzipCompression, ZstdCompression} {
+		compressions[compression.Id()] = compression
+	}
+}
+
+// ParseFormat returns the format with the given name: a codec, "json",
+// "msgpack" or "protobuf", optionally followed by a compression, "+gzip"
+// or "+zstd", e.g. "msgpack+zstd".
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return JSONFormat, nil
+	}
+
+	codecName, compressionName, compressed := strings.Cut(name, "+")
+
+	var format Format
+	for _, codec := range codecs {
+		if codec.Name() == codecName {
+			format.Codec = codec
+		}
+	}
+
+	if format.Codec == nil {
+		return Format{}, fmt.Errorf("unknown codec %q", codecName)
+	}
+
+	if !compressed {
+		return format, nil
+	}
+
+	for _, compression := range compressions {
+		if compression.Name() == compressionName {
+			format.Compression = compression
+		}
+	}
+
+	if format.Compression == nil {
+		return Format{}, fmt.Errorf("unknown compression %q", compressionName)
+	}
+
+	return format, nil
+}
+
+// String returns the name of the format, as accepted by ParseFormat.
+func (f Format) String() string {
+	if f.Compression == nil {
+		return f.Codec.Name()
+	}
+
+	return f.Codec.Name() + "+" + f.Compression.Name()
+}
+
+// headerMagic starts the header of the files not in JSONFormat. It can't
+// start a JSON document, so files without a header are read as JSON.
+var headerMagic = []byte{0xFF, 'S', 'C'}
+
+// headerSize is the magic followed by the codec and the compression ids,
+// 0 when not compressed.
+const headerSize = 5
+
+// encode returns the content of a file holding v.
+func (f Format) encode(v any) ([]byte, error) {
+	data, err := f.Codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	if f == JSONFormat {
+		return data, nil
+	}
+
+	header := append(bytes.Clone(headerMagic), f.Codec.Id(), 0)
+	if f.Compression != nil {
+		header[headerSize-1] = f.Compression.Id()
+
+		if data, err = f.Compression.Compress(data); err != nil {
+			return nil, fmt.Errorf("failed to compress data: %w", err)
+		}
+	}
+
+	return append(header, data...), nil
+}
+
+// readFormat returns the format of the content of a file, and the
+// content without its header.
+func readFormat(data []byte) (Format, []byte, error) {
+	if !bytes.HasPrefix(data, headerMagic) {
+		return JSONFormat, data, nil
+	}
+
+	if len(data) < headerSize {
+		return Format{}, nil, fmt.Errorf("truncated header")
+	}
+
+	var format Format
+	var ok bool
+	if format.Codec, ok = codecs[data[3]]; !ok {
+		return Format{}, nil, fmt.Errorf("unknown codec %d", data[3])
+	}
+
+	if id := data[4]; id != 0 {
+		if format.Compression, ok = compressions[id]; !ok {
+			return Format{}, nil, fmt.Errorf("unknown compression %d", id)
+		}
+	}
+
+	return format, data[headerSize:], nil
+}
+
+// decode unmarshals the content of a file, in any format, into v.
+func decode(data []byte, v any) error {
+	format, data, err := readFormat(data)
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+
+	if format.Compression != nil {
+		if data, err = format.Compression.Decompress(data); err != nil {
+			return fmt.Errorf("failed to decompress file: %w", err)
+		}
+	}
+
+	if err := format.Codec.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to unmarshal file: %w", err)
+	}
+
+	return nil
+}
+
+type jsonCodec struct{}
+
+// JSONCodec encodes the files with encoding/json.
+var JSONCodec Codec = jsonCodec{}
+
+func (jsonCodec) Id() byte     { return 'j' }
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// toJSONValue returns v as encoding/json sees it, for the values protobuf
+// encodes without a schema: maps, slices, strings, bools, nil and numbers,
+// as int64 when integral.
+func toJSONValue(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var output any
+	if err := d.Decode(&output); err != nil {
+		return nil, err
+	}
+
+	return normalizeNumbers(output), nil
+}
+
+func normalizeNumbers(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = normalizeNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+
+		f, _ := v.Float64()
+		return f
+	}
+
+	return v
+}
+
+// fromJSONValue decodes the value returned by toJSONValue into v.
+func fromJSONValue(value any, v any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,100 @@
+package filesystem
+
+import (
+	"bytes"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type messagePackCodec struct{}
+
+// MessagePackCodec encodes the files with MessagePack, directly from the
+// types of the values. Fields are named by their msgpack tag or, without
+// one, by their json tag, so a type keeps its field names, omitted fields
+// and "-" whatever the codec of its files.
+var MessagePackCodec Codec = messagePackCodec{}
+
+func (messagePackCodec) Id() byte     { return 'm' }
+func (messagePackCodec) Name() string { return "msgpack" }
+
+func (messagePackCodec) Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (messagePackCodec) Unmarshal(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+
+	return dec.Decode(v)
+}
+
+// ProtoMarshaler is implemented by the values written with a protobuf
+// message of their own, such as a persisted schema mapped to its generated
+// message, without being a proto.Message themselves.
+type ProtoMarshaler interface {
+	MarshalProto() ([]byte, error)
+}
+
+// ProtoUnmarshaler decodes the message written by a ProtoMarshaler.
+type ProtoUnmarshaler interface {
+	UnmarshalProto(data []byte) error
+}
+
+type protobufCodec struct{}
+
+// ProtobufCodec encodes proto.Message values, and the values implementing
+// ProtoMarshaler and ProtoUnmarshaler, with their own message. The other
+// values have no schema: they are encoded as the google.protobuf.Value of
+// their JSON, where every number is a double, so large integers lose
+// their precision.
+var ProtobufCodec Codec = protobufCodec{}
+
+func (protobufCodec) Id() byte     { return 'p' }
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case proto.Message:
+		return proto.Marshal(v)
+	case ProtoMarshaler:
+		return v.MarshalProto()
+	}
+
+	value, err := toJSONValue(v)
+	if err != nil {
+		return nil, err
+	}
+
+	pb, err := structpb.NewValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, v)
+	case ProtoUnmarshaler:
+		return v.UnmarshalProto(data)
+	}
+
+	var pb structpb.Value
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return err
+	}
+
+	return fromJSONValue(pb.AsInterface(), v)
+}
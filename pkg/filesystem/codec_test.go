@@ -0,0 +1,193 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type codecDocument struct {
+	Name      string
+	Count     int
+	Ratio     float64
+	Optional  string `json:",omitempty"`
+	Hidden    string `json:"-"`
+	CreatedAt *time.Time
+	Tags      map[string]string
+	Items     []codecDocument `json:",omitempty"`
+}
+
+func newCodecDocument() codecDocument {
+	createdAt := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	return codecDocument{
+		Name:      "shop.com",
+		Count:     42,
+		Ratio:     1.5,
+		CreatedAt: &createdAt,
+		Tags:      map[string]string{"campaign": "summer"},
+		Items:     []codecDocument{{Name: "nested", Count: -1}},
+	}
+}
+
+// inUTC returns the document with its times in UTC: MessagePack keeps the
+// instant of a time, not its zone, and reads it in the local one.
+func (d codecDocument) inUTC() codecDocument {
+	if d.CreatedAt != nil {
+		createdAt := d.CreatedAt.UTC()
+		d.CreatedAt = &createdAt
+	}
+
+	return d
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	for _, name := range []string{"json", "json+gzip", "msgpack", "msgpack+zstd", "protobuf", "protobuf+gzip"} {
+		t.Run(name, func(t *testing.T) {
+			format, err := ParseFormat(name)
+			if err != nil {
+				t.Fatalf("Failed to parse format: %v", err)
+			}
+
+			if format.String() != name {
+				t.Fatalf("Expected name %s, got %s", name, format)
+			}
+
+			want := newCodecDocument()
+			data, err := format.encode(want)
+			if err != nil {
+				t.Fatalf("Failed to encode: %v", err)
+			}
+
+			if hasHeader := bytes.HasPrefix(data, headerMagic); hasHeader != (format != JSONFormat) {
+				t.Fatalf("Unexpected header in %q", data[:headerSize])
+			}
+
+			var got codecDocument
+			if err := decode(data, &got); err != nil {
+				t.Fatalf("Failed to decode: %v", err)
+			}
+
+			if !reflect.DeepEqual(want, got.inUTC()) {
+				t.Fatalf("Expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestFormat_ProtobufMessage(t *testing.T) {
+	want, _ := structpb.NewStruct(map[string]any{"name": "shop.com"})
+	data, err := Format{Codec: ProtobufCodec}.encode(want)
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	var got structpb.Struct
+	if err := decode(data, &got); err != nil {
+		t.Fatalf("Failed to decode: %v", err)
+	}
+
+	if got.Fields["name"].GetStringValue() != "shop.com" {
+		t.Fatalf("Unexpected message %v", &got)
+	}
+}
+
+func TestParseFormat_Unknown(t *testing.T) {
+	for _, name := range []string{"xml", "json+brotli", "json+"} {
+		if _, err := ParseFormat(name); err == nil {
+			t.Fatalf("Expected %q to be rejected", name)
+		}
+	}
+}
+
+func TestDecode_InvalidHeader(t *testing.T) {
+	for _, data := range [][]byte{
+		append(bytes.Clone(headerMagic), 'j'),
+		append(bytes.Clone(headerMagic), 'x', 0),
+		append(bytes.Clone(headerMagic), 'j', 'x'),
+	} {
+		var v any
+		if err := decode(data, &v); err == nil {
+			t.Fatalf("Expected %q to fail", data)
+		}
+	}
+}
+
+func TestFileSystem_MixedFormats(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	want := newCodecDocument()
+
+	// every instance writes its own format in the same directory
+	formats := []Format{JSONFormat, {Codec: MessagePackCodec, Compression: ZstdCompression}, {Codec: ProtobufCodec}}
+	for i, format := range formats {
+		fs := NewFileSystem(WithDir(dir), WithFormat(format))
+		if err := fs.Save(ctx, NewFileName(format.String()), want); err != nil {
+			t.Fatalf("Failed to save with format %d: %v", i, err)
+		}
+	}
+
+	fs := NewFileSystem(WithDir(dir))
+	for _, format := range formats {
+		var got codecDocument
+		if err := fs.Get(ctx, NewFileName(format.String()), &got); err != nil {
+			t.Fatalf("Failed to get %s: %v", format, err)
+		}
+
+		if !reflect.DeepEqual(want, got.inUTC()) {
+			t.Fatalf("Expected %+v from %s, got %+v", want, format, got)
+		}
+	}
+}
+
+func TestFileSystem_Convert(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	want := newCodecDocument()
+
+	jsonFs := NewFileSystem(WithDir(dir), WithLayout(ShardedLayout))
+	for _, key := range []string{"shop.com", "captions/123"} {
+		if err := jsonFs.Save(ctx, NewFileName(key), want); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	newValue := func(FileName) any { return new(codecDocument) }
+	format := Format{Codec: MessagePackCodec, Compression: GzipCompression}
+	fs := NewFileSystem(WithDir(dir), WithLayout(ShardedLayout), WithFormat(format))
+	converted, err := fs.Convert(ctx, newValue)
+	if err != nil || converted != 2 {
+		t.Fatalf("Expected 2 files to be converted, got %d, %v", converted, err)
+	}
+
+	for _, key := range []string{"shop.com", "captions/123"} {
+		path, _ := fs.getFullPath(NewFileName(key))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", key, err)
+		}
+
+		if got, _, _ := readFormat(data); got != format {
+			t.Fatalf("Expected %s to be in %s, got %s", key, format, got)
+		}
+
+		var got codecDocument
+		if err := jsonFs.Get(ctx, NewFileName(key), &got); err != nil {
+			t.Fatalf("Failed to get %s: %v", key, err)
+		}
+
+		if !reflect.DeepEqual(want, got.inUTC()) {
+			t.Fatalf("Expected %+v, got %+v", want, got)
+		}
+	}
+
+	// converted files are left as they are
+	converted, err = fs.Convert(ctx, newValue)
+	if err != nil || converted != 0 {
+		t.Fatalf("Expected nothing to convert, got %d, %v", converted, err)
+	}
+}
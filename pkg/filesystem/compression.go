@@ -0,0 +1,70 @@
+package filesystem
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type gzipCompression struct{}
+
+// GzipCompression compresses the files with gzip.
+var GzipCompression Compression = gzipCompression{}
+
+func (gzipCompression) Id() byte     { return 'g' }
+func (gzipCompression) Name() string { return "gzip" }
+
+func (gzipCompression) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (gzipCompression) Decompress(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// zstdCompression shares an encoder and a decoder, which are safe for
+// concurrent use through EncodeAll and DecodeAll.
+type zstdCompression struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// ZstdCompression compresses the files with Zstandard.
+var ZstdCompression Compression = newZstdCompression()
+
+func newZstdCompression() *zstdCompression {
+	// with no writer or reader, the constructors only fail on invalid options
+	encoder, _ := zstd.NewWriter(nil)
+	decoder, _ := zstd.NewReader(nil)
+
+	return &zstdCompression{encoder: encoder, decoder: decoder}
+}
+
+func (*zstdCompression) Id() byte     { return 'z' }
+func (*zstdCompression) Name() string { return "zstd" }
+
+func (c *zstdCompression) Compress(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCompression) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
@@ -0,0 +1,91 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Convert rewrites the files that are not in the format of fs, and returns
+// the number of files rewritten. Each file is rewritten atomically under
+// its lock, so fs can be used while it converts, but not another instance
+// or process on the same directory.
+//
+// A file is decoded into the value newValue returns for its name, a
+// pointer to the type it was saved from: a codec may need the type to
+// decode the file, as protobuf does for a message.
+func (fs *FileSystem) Convert(ctx context.Context, newValue func(name FileName) any) (int, error) {
+	var names []FileName
+	err := fs.walk(fs.layout, false, func(name FileName, _ string) error {
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	converted := 0
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return converted, err
+		}
+
+		ok, err := fs.convert(name, newValue(name))
+		if err != nil {
+			return converted, fmt.Errorf("failed to convert %s: %w", name, err)
+		}
+
+		if ok {
+			converted++
+		}
+	}
+
+	return converted, nil
+}
+
+// convert rewrites the file with the format of fs, decoding it into value.
+// It returns false when the file was already in that format or was deleted
+// meanwhile.
+func (fs *FileSystem) convert(name FileName, value any) (bool, error) {
+	lock := fs.locks.get(name)
+	lock.Lock()
+	defer lock.Unlock()
+
+	b, err := fs.read(name)
+	if errors.Is(err, ErrFileNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	format, _, err := readFormat(b)
+	if err != nil {
+		return false, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	if format == fs.format {
+		return false, nil
+	}
+
+	if err := decode(b, value); err != nil {
+		return false, err
+	}
+
+	data, err := fs.format.encode(value)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	fullPath, err := fs.getFullPath(name)
+	if err != nil {
+		return false, err
+	}
+
+	if err := fs.writeFile(fullPath, data, fs.fileMode); err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return true, nil
+}
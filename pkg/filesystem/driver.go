@@ -4,63 +4,121 @@ package filesystem
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
-	"sync"
+	"slices"
+	"strings"
 )
 
-var (
-	once sync.Once
-	fs   *FileSystem
-)
+// defaultDir is the directory of the files, relative to the working
+// directory, when no WithDir option is given.
+const defaultDir = "assets/tmp"
 
 // FileSystem provides thread-safe file operations for storing
 // and retrieving data using the local filesystem.
 type FileSystem struct {
-	mu      sync.RWMutex // protects concurrent access to files
-	baseDir string       // base directory for all file operations
+	locks    keyLocks    // protects concurrent access to each file
+	dir      string      // directory holding the files
+	layout   Layout      // path of each file in dir
+	format   Format      // format of the files written
+	fileMode os.FileMode // mode of the files
+	dirMode  os.FileMode // mode of the directories created for the files
+	ops      fileOps     // system calls of the atomic writes
 }
 
-// NewFileSystem creates a singleton instance of FileSystem.
-// It uses the current working directory as the base directory.
-// If getting the working directory fails, it falls back to ".".
-func NewFileSystem() *FileSystem {
-	once.Do(func() {
-		// Get the current working directory as base directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			// Fallback to relative path if we can't get working directory
-			cwd = "."
-		}
+// Option configures a FileSystem.
+type Option func(*FileSystem)
 
-		fs = &FileSystem{
-			baseDir: cwd,
-		}
-	})
+// WithDir sets the directory holding the files.
+func WithDir(dir string) Option {
+	return func(fs *FileSystem) {
+		fs.dir = dir
+	}
+}
 
-	return fs
+// WithLayout sets how the files are laid out in the directory.
+// Use Relayout to move the files of a directory to another layout.
+func WithLayout(layout Layout) Option {
+	return func(fs *FileSystem) {
+		fs.layout = layout
+	}
+}
+
+// WithFormat sets the format of the files written. Files written with
+// another format are still read, and rewritten with this one when saved.
+func WithFormat(format Format) Option {
+	return func(fs *FileSystem) {
+		fs.format = format
+	}
+}
+
+// WithFileMode sets the permissions of the files and of the
+// directories created for them.
+func WithFileMode(fileMode, dirMode os.FileMode) Option {
+	return func(fs *FileSystem) {
+		fs.fileMode = fileMode
+		fs.dirMode = dirMode
+	}
 }
 
-// FileName is a type for file names used in the filesystem.
-// It encapsulates the naming convention for files.
-type FileName string
+// NewFileSystem creates a FileSystem. By default the files are laid out
+// flat in "assets/tmp" under the current working directory; if getting
+// the working directory fails, the path is relative.
+// Every call returns an independent instance. Instances don't share their
+// locks, so a directory must not be used by two instances at the same time.
+// The temporary files left by writes interrupted by a crash are removed, and
+// the files stored under their key as is, before the keys were escaped, are
+// renamed to their escaped name.
+func NewFileSystem(opts ...Option) *FileSystem {
+	cwd, err := os.Getwd()
+	if err != nil {
+		// Fallback to relative path if we can't get working directory
+		cwd = "."
+	}
 
-// NewFileName creates a new FileName with the specified input
-// and applies standard filepath formatting.
-func NewFileName(input string) FileName {
-	return FileName(fmt.Sprintf("assets/tmp/%s.json", input))
+	fs := newFileSystem(filepath.Join(cwd, defaultDir), osFileOps{})
+	for _, opt := range opts {
+		opt(fs)
+	}
+
+	if removed, err := fs.recoverTempFiles(); err != nil {
+		log.Printf("[WARNING] Failed to remove temporary files: %v", err)
+	} else if removed > 0 {
+		log.Printf("removed %d temporary files of interrupted writes", removed)
+	}
+
+	if renamed, err := fs.renameLegacyFiles(); err != nil {
+		log.Printf("[WARNING] Failed to rename the files stored before the keys were escaped: %v", err)
+	} else if renamed > 0 {
+		log.Printf("renamed %d files stored before the keys were escaped", renamed)
+	}
+
+	return fs
 }
 
-// String returns the string representation of the FileName.
-func (fn FileName) String() string {
-	return string(fn)
+func newFileSystem(dir string, ops fileOps) *FileSystem {
+	return &FileSystem{
+		locks:    newKeyLocks(lockStripes),
+		dir:      dir,
+		layout:   FlatLayout,
+		format:   JSONFormat,
+		fileMode: 0644,
+		dirMode:  0755,
+		ops:      ops,
+	}
 }
 
 // getFullPath returns the absolute path for a given filename.
-func (fs *FileSystem) getFullPath(filename FileName) string {
-	return filepath.Join(fs.baseDir, filename.String())
+// Returns ErrInvalidFileName if the name can't be stored.
+func (fs *FileSystem) getFullPath(filename FileName) (string, error) {
+	if err := filename.Validate(); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(fs.dir, fs.layout.Path(filename)), nil
 }
 
 // ensureDir ensures that the directory for the given file path exists.
@@ -68,7 +126,7 @@ func (fs *FileSystem) getFullPath(filename FileName) string {
 func (fs *FileSystem) ensureDir(path string) error {
 	dir := filepath.Dir(path)
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return os.MkdirAll(dir, 0755)
+		return os.MkdirAll(dir, fs.dirMode)
 	}
 	return nil
 }
@@ -77,11 +135,16 @@ func (fs *FileSystem) ensureDir(path string) error {
 // Returns true if the file exists, false otherwise.
 // Any errors other than "file not exists" are returned.
 func (fs *FileSystem) FileExists(ctx context.Context, key FileName) (bool, error) {
-	fs.mu.RLock()
-	defer fs.mu.RUnlock()
+	lock := fs.locks.get(key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	fullPath, err := fs.getFullPath(key)
+	if err != nil {
+		return false, err
+	}
 
-	fullPath := fs.getFullPath(key)
-	_, err := os.Stat(fullPath)
+	_, err = os.Stat(fullPath)
 	if err == nil {
 		return true, nil
 	}
@@ -92,14 +155,103 @@ func (fs *FileSystem) FileExists(ctx context.Context, key FileName) (bool, error
 }
 
 // Save stores data to a file specified by key.
-// The data can be any type and will be marshaled to JSON
-// unless it's already a string, in which case it's stored directly.
-// It ensures the target directory exists before writing.
+// The data can be any type and will be marshaled with the format of fs
+// unless it's already a string, in which case it's stored directly as JSON.
+// It ensures the target directory exists before writing, and replaces
+// the file atomically: an interrupted Save leaves the previous content.
 func (fs *FileSystem) Save(ctx context.Context, key FileName, data any) error {
-	fs.mu.Lock()
-	defer fs.mu.Unlock()
+	lock := fs.locks.get(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	// Use context to potentially handle timeout
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	fullPath := fs.getFullPath(key)
+	return fs.write(key, data)
+}
+
+// Get retrieves data from a file specified by key.
+// It reads the file and unmarshals its content, in any format, into v.
+// The context can be used for cancellation or timeout.
+func (fs *FileSystem) Get(ctx context.Context, key FileName, v any) error {
+	lock := fs.locks.get(key)
+	lock.RLock()
+	defer lock.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b, err := fs.read(key)
+	if err != nil {
+		return err
+	}
+
+	return decode(b, v)
+}
+
+// Update reads the file specified by key, passes a decoder of its content
+// to fn and saves what fn returns, holding the lock of the file during the
+// whole cycle so no concurrent write is lost. fn receives a nil decoder when
+// the file doesn't exist. The file is left untouched when fn returns an error.
+func (fs *FileSystem) Update(ctx context.Context, key FileName, fn func(current DecodeFunc) (any, error)) error {
+	lock := fs.locks.get(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var current DecodeFunc
+	b, err := fs.read(key)
+	if err == nil {
+		current = func(v any) error {
+			return decode(b, v)
+		}
+	} else if !errors.Is(err, ErrFileNotFound) {
+		return err
+	}
+
+	next, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return fs.write(key, next)
+}
+
+// read returns the content of the file, the caller holds the lock.
+func (fs *FileSystem) read(key FileName) ([]byte, error) {
+	fullPath, err := fs.getFullPath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(fullPath)
+	if os.IsNotExist(err) {
+		return nil, ErrFileNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return b, nil
+}
+
+// write marshals data and replaces the file, the caller holds the lock.
+func (fs *FileSystem) write(key FileName, data any) error {
+	fullPath, err := fs.getFullPath(key)
+	if err != nil {
+		return err
+	}
 
 	// Ensure target directory exists
 	if err := fs.ensureDir(fullPath); err != nil {
@@ -107,64 +259,108 @@ func (fs *FileSystem) Save(ctx context.Context, key FileName, data any) error {
 	}
 
 	var bytes []byte
-	var err error
 
-	// Check if data is already a string
+	// Check if data is already a string, which is stored as JSON
 	if str, ok := data.(string); ok {
 		bytes = []byte(str)
 	} else {
-		// Marshal the data to JSON if it's not already a string
-		bytes, err = json.Marshal(data)
+		// Marshal the data with the format of fs if it's not already a string
+		bytes, err = fs.format.encode(data)
 		if err != nil {
 			return fmt.Errorf("failed to marshal data: %w", err)
 		}
 	}
 
-	// Use context to potentially handle timeout
-	select {
-	case <-ctx.Done():
-		return ctx.Err()
-	default:
-		if err := os.WriteFile(fullPath, bytes, 0644); err != nil {
-			return fmt.Errorf("failed to write file: %w", err)
-		}
+	if err := fs.writeFile(fullPath, bytes, fs.fileMode); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
-// Get retrieves data from a file specified by key.
-// It reads the file and unmarshals the JSON content into a map[string]any.
-// The context can be used for cancellation or timeout.
-func (fs *FileSystem) Get(ctx context.Context, key FileName) (any, error) {
-	fs.mu.RLock()
-	defer fs.mu.RUnlock()
+// Delete removes the file specified by key.
+// Returns ErrFileNotFound if the file doesn't exist.
+func (fs *FileSystem) Delete(ctx context.Context, key FileName) error {
+	lock := fs.locks.get(key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-	fileExists, err := fs.FileExists(ctx, key)
+	fullPath, err := fs.getFullPath(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check file existence: %w", err)
+		return err
 	}
 
-	if !fileExists {
-		return nil, ErrFileNotFound
+	err = os.Remove(fullPath)
+	if os.IsNotExist(err) {
+		return ErrFileNotFound
 	}
 
-	fullPath := fs.getFullPath(key)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
 
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	default:
-		b, err := os.ReadFile(fullPath)
+	return nil
+}
+
+// List returns the files saved with NewFileName, in lexical order.
+// Files in subdirectories are not included.
+// No lock is taken: files are replaced by a rename, so the directory never
+// holds a partially written one.
+func (fs *FileSystem) List(ctx context.Context) ([]FileName, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var output []FileName
+	err := fs.walk(fs.layout, true, func(name FileName, _ string) error {
+		output = append(output, name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	// sharded layouts are walked in the order of the hashes
+	slices.Sort(output)
+
+	return output, nil
+}
+
+// walk calls fn with the name and the path of every file stored in the
+// directory with layout. With topLevel, the names holding a "/" are skipped,
+// as well as the directories they are stored in.
+func (fs *FileSystem) walk(layout Layout, topLevel bool, fn func(name FileName, path string) error) error {
+	err := filepath.WalkDir(fs.dir, func(path string, entry os.DirEntry, err error) error {
 		if err != nil {
-			return nil, fmt.Errorf("failed to read file: %w", err)
+			return err
 		}
 
-		output := make(map[string]any)
-		if err := json.Unmarshal(b, &output); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal file: %w", err)
+		rel, err := filepath.Rel(fs.dir, path)
+		if err != nil || rel == "." {
+			return err
 		}
 
-		return output, nil
+		if entry.IsDir() {
+			if topLevel && strings.Count(filepath.ToSlash(rel), "/") >= layout.Levels {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name, ok := layout.Name(rel)
+		if !ok || (topLevel && strings.Contains(name.String(), "/")) {
+			return nil
+		}
+
+		return fn(name, path)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
 	}
+
+	return err
 }
@@ -1,8 +1,11 @@
 package filesystem
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
 )
 
@@ -99,3 +102,48 @@ func BenchmarkDirectoryListing(b *testing.B) {
 		_ = files
 	}
 }
+
+// BenchmarkFileSystem_MixedHosts reads and writes the files of many hosts in
+// parallel, nine reads for each write. With a single stripe every operation
+// contends for the same lock, as a global RWMutex would.
+func BenchmarkFileSystem_MixedHosts(b *testing.B) {
+	const hosts = 64
+
+	for _, stripes := range []int{1, lockStripes} {
+		b.Run(fmt.Sprintf("stripes=%d", stripes), func(b *testing.B) {
+			fs := newFileSystem(b.TempDir(), osFileOps{})
+			fs.locks = newKeyLocks(stripes)
+			ctx := context.Background()
+
+			keys := make([]FileName, hosts)
+			for i := range keys {
+				keys[i] = NewFileName(fmt.Sprintf("host-%d.com", i))
+				if err := fs.Save(ctx, keys[i], `{"/home":{}}`); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			var ops atomic.Int64
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					i := ops.Add(1)
+					key := keys[i%hosts]
+
+					if i%10 == 0 {
+						if err := fs.Save(ctx, key, `{"/home":{}}`); err != nil {
+							b.Error(err)
+						}
+						continue
+					}
+
+					var data map[string]any
+					if err := fs.Get(ctx, key, &data); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
@@ -3,30 +3,15 @@ package filesystem
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sync"
 	"testing"
 	"time"
 )
 
 func TestFileSystem_WriteAndRead(t *testing.T) {
-	// Setup temporary directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("Failed to get current directory: %v", err)
-	}
-
-	tmpDir := filepath.Join(cwd, "assets", "tmp")
-	err = os.MkdirAll(tmpDir, 0755)
-	if err != nil {
-		t.Fatalf("Failed to create temp directory: %v", err)
-	}
-	defer os.RemoveAll(tmpDir)
-
-	// Initialize filesystem
-	fs := NewFileSystem()
+	fs := NewFileSystem(WithDir(t.TempDir()))
 	ctx := context.Background()
 
 	// Data to save
@@ -34,44 +19,28 @@ func TestFileSystem_WriteAndRead(t *testing.T) {
 	fileName := NewFileName("test")
 
 	// Write data
-	err = fs.Save(ctx, fileName, testData)
+	err := fs.Save(ctx, fileName, testData)
 	if err != nil {
 		t.Fatalf("Failed to save data: %v", err)
 	}
 
-	// Read data back
-	data, err := fs.Get(ctx, fileName)
-	if err != nil {
+	// Read data back into a typed value
+	var result struct {
+		Name  string
+		Value int
+	}
+	if err := fs.Get(ctx, fileName, &result); err != nil {
 		t.Fatalf("Failed to get data: %v", err)
 	}
 
 	// Verify data
-	result, ok := data.(map[string]interface{})
-	if !ok {
-		t.Fatal("Invalid data type returned")
-	}
-
-	if result["name"] != "test" || result["value"].(float64) != 123 {
+	if result.Name != "test" || result.Value != 123 {
 		t.Fatalf("Data mismatch. Got: %v", result)
 	}
 }
 
 func TestFileSystem_Concurrency(t *testing.T) {
-	// Setup temporary directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Fatalf("Failed to get current directory: %v", err)
-	}
-
-	tmpDir := filepath.Join(cwd, "assets", "tmp")
-	err = os.MkdirAll(tmpDir, 0755)
-	if err != nil {
-		t.Fatalf("Failed to create temp directory: %v", err)
-	}
-	defer os.RemoveAll(tmpDir)
-
-	// Initialize filesystem
-	fs := NewFileSystem()
+	fs := NewFileSystem(WithDir(t.TempDir()))
 	ctx := context.Background()
 
 	// Number of concurrent operations
@@ -118,18 +87,12 @@ func TestFileSystem_Concurrency(t *testing.T) {
 			fileName := NewFileName(fmt.Sprintf("concurrent_%d", index))
 
 			// Read data
-			data, err := fs.Get(ctx, fileName)
-			if err != nil {
+			var result map[string]interface{}
+			if err := fs.Get(ctx, fileName, &result); err != nil {
 				t.Errorf("Failed to read data: %v", err)
 				return
 			}
 
-			result, ok := data.(map[string]interface{})
-			if !ok {
-				t.Error("Invalid data type returned")
-				return
-			}
-
 			// Verify data
 			id, idOk := result["id"].(float64)
 			value, valueOk := result["value"].(float64)
@@ -152,3 +115,103 @@ func TestFileSystem_Concurrency(t *testing.T) {
 		t.Fatalf("Expected %d successful operations, got %d", numOps, len(results))
 	}
 }
+
+func TestFileSystem_List(t *testing.T) {
+	fs := NewFileSystem(WithDir(t.TempDir()))
+	ctx := context.Background()
+
+	for _, key := range []string{"b.com", "a.com", "captions/123"} {
+		if err := fs.Save(ctx, NewFileName(key), "{}"); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	files, err := fs.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+
+	// subdirectories are not listed
+	if len(files) != 2 || files[0].Key() != "a.com" || files[1].Key() != "b.com" {
+		t.Fatalf("Unexpected files: %v", files)
+	}
+
+	if files[0] != NewFileName("a.com") {
+		t.Fatalf("Unexpected file name: %s", files[0])
+	}
+}
+
+func TestFileSystem_Delete(t *testing.T) {
+	fs := NewFileSystem(WithDir(t.TempDir()))
+	ctx := context.Background()
+	fileName := NewFileName("videos/summer")
+
+	if err := fs.Save(ctx, fileName, "{}"); err != nil {
+		t.Fatalf("Failed to save data: %v", err)
+	}
+
+	if err := fs.Delete(ctx, fileName); err != nil {
+		t.Fatalf("Failed to delete file: %v", err)
+	}
+
+	if exists, _ := fs.FileExists(ctx, fileName); exists {
+		t.Fatal("File still exists after delete")
+	}
+
+	if err := fs.Delete(ctx, fileName); !errors.Is(err, ErrFileNotFound) {
+		t.Fatalf("Expected ErrFileNotFound, got: %v", err)
+	}
+}
+
+func TestFileSystem_Update(t *testing.T) {
+	fs := NewFileSystem(WithDir(t.TempDir()))
+	ctx := context.Background()
+	fileName := NewFileName("counter")
+
+	// every increment reads the value written by the previous one
+	const increments = 100
+	var wg sync.WaitGroup
+	for range increments {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := fs.Update(ctx, fileName, func(current DecodeFunc) (any, error) {
+				var counter struct{ Count int }
+				if current != nil {
+					if err := current(&counter); err != nil {
+						return nil, err
+					}
+				}
+				return map[string]any{"count": counter.Count + 1}, nil
+			})
+			if err != nil {
+				t.Errorf("Failed to update data: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var data map[string]any
+	if err := fs.Get(ctx, fileName, &data); err != nil {
+		t.Fatalf("Failed to get data: %v", err)
+	}
+
+	if count := data["count"]; count != float64(increments) {
+		t.Fatalf("Expected count %d, got %v", increments, count)
+	}
+
+	// an error returned by fn leaves the file untouched
+	errAbort := errors.New("abort")
+	err := fs.Update(ctx, fileName, func(current DecodeFunc) (any, error) {
+		return map[string]any{"count": 0}, errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Expected the error of fn, got: %v", err)
+	}
+
+	fs.Get(ctx, fileName, &data)
+	if count := data["count"]; count != float64(increments) {
+		t.Fatalf("Expected count %d after aborted update, got %v", increments, count)
+	}
+}
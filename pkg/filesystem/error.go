@@ -2,4 +2,9 @@ package filesystem
 
 import "errors"
 
-var ErrFileNotFound = errors.New("file not found")
+var (
+	ErrFileNotFound  = errors.New("file not found")
+	ErrInvalidBlobId = errors.New("invalid blob id")
+	// ErrInvalidFileName is returned for keys that can't be stored as a file.
+	ErrInvalidFileName = errors.New("invalid file name")
+)
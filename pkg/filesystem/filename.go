@@ -0,0 +1,153 @@
+package filesystem
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxSegmentLength caps each "/" separated part of an encoded name, so
+	// the file name, with fileNameExt and the suffix of its temporary file,
+	// stays under the 255 bytes most filesystems accept.
+	maxSegmentLength = 200
+	// maxNameLength caps the whole encoded name.
+	maxNameLength = 1024
+)
+
+// FileName identifies a file of the filesystem by its key, such as a host
+// or "captions/<id>". It holds the key encoded to be safe as a path, and
+// where the file is stored depends on the Layout.
+type FileName string
+
+// NewFileName creates the FileName of the given key. Each "/" separated
+// part of the key is escaped, so it can't be "." or "..", hold a path
+// separator or differ from another part only by case. Names that can't be
+// stored, such as empty or too long ones, fail their Validate.
+func NewFileName(input string) FileName {
+	segments := strings.Split(input, "/")
+	for i, segment := range segments {
+		segments[i] = escapeSegment(segment)
+	}
+
+	return FileName(strings.Join(segments, "/"))
+}
+
+// Key returns the input the FileName was created from.
+func (fn FileName) Key() string {
+	key, err := unescapeName(string(fn))
+	if err != nil {
+		return string(fn)
+	}
+
+	return key
+}
+
+// String returns the encoded name, which the Layout turns into a path.
+func (fn FileName) String() string {
+	return string(fn)
+}
+
+// Validate returns ErrInvalidFileName if the name can't be stored: when a
+// part of it is empty or too long, or it was not created by NewFileName.
+func (fn FileName) Validate() error {
+	name := string(fn)
+	if len(name) > maxNameLength {
+		return fmt.Errorf("%w: longer than %d bytes", ErrInvalidFileName, maxNameLength)
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" {
+			return fmt.Errorf("%w: empty part in %q", ErrInvalidFileName, name)
+		}
+
+		if len(segment) > maxSegmentLength {
+			return fmt.Errorf("%w: part longer than %d bytes", ErrInvalidFileName, maxSegmentLength)
+		}
+
+		// the encoding is canonical, anything else wasn't made by NewFileName
+		if key, err := unescapeSegment(segment); err != nil || escapeSegment(key) != segment {
+			return fmt.Errorf("%w: %q is not encoded", ErrInvalidFileName, segment)
+		}
+	}
+
+	return nil
+}
+
+// isSafeByte reports whether c is kept as is in an encoded name. Upper case
+// letters are escaped, so keys differing by case don't collide on
+// case-insensitive filesystems.
+func isSafeByte(c byte) bool {
+	return 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':'
+}
+
+// escapeSegment encodes every unsafe byte, and a leading ".", as "%XX".
+// Escaping the leading dot rules out "." and "..", and hidden names such
+// as the ones of the temporary files.
+func escapeSegment(segment string) string {
+	const hex = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if isSafeByte(c) && !(i == 0 && c == '.') {
+			b.WriteByte(c)
+			continue
+		}
+
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xF])
+	}
+
+	return b.String()
+}
+
+func unescapeSegment(segment string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+
+		if i+2 >= len(segment) {
+			return "", fmt.Errorf("%w: truncated escape in %q", ErrInvalidFileName, segment)
+		}
+
+		hi, lo := unhex(segment[i+1]), unhex(segment[i+2])
+		if hi < 0 || lo < 0 {
+			return "", fmt.Errorf("%w: invalid escape in %q", ErrInvalidFileName, segment)
+		}
+
+		b.WriteByte(byte(hi<<4 | lo))
+		i += 2
+	}
+
+	return b.String(), nil
+}
+
+func unescapeName(name string) (string, error) {
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		s, err := unescapeSegment(segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = s
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+// unhex returns the value of an upper case hex digit, -1 for other bytes.
+func unhex(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'A' <= c && c <= 'F':
+		return int(c-'A') + 10
+	}
+
+	return -1
+}
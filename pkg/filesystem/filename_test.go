@@ -0,0 +1,129 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFileName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want FileName
+	}{
+		{key: "shop.com", want: "shop.com"},
+		{key: "localhost:8080", want: "localhost:8080"},
+		{key: "captions/123", want: "captions/123"},
+		{key: "Shop.com", want: "%53hop.com"},
+		{key: "..", want: "%2E."},
+		{key: "../../etc/passwd", want: "%2E./%2E./etc/passwd"},
+		{key: ".hidden", want: "%2Ehidden"},
+		{key: `a\b%2F`, want: "a%5Cb%252%46"},
+		{key: "café.com", want: "caf%C3%A9.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			name := NewFileName(tt.key)
+			if name != tt.want {
+				t.Fatalf("Expected %s, got %s", tt.want, name)
+			}
+
+			if err := name.Validate(); err != nil {
+				t.Fatalf("Expected a valid name, got %v", err)
+			}
+
+			if name.Key() != tt.key {
+				t.Fatalf("Expected key %s, got %s", tt.key, name.Key())
+			}
+		})
+	}
+}
+
+func TestFileName_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		file FileName
+	}{
+		{name: "empty", file: NewFileName("")},
+		{name: "empty part", file: NewFileName("captions//123")},
+		{name: "trailing separator", file: NewFileName("captions/")},
+		{name: "long part", file: NewFileName(strings.Repeat("a", maxSegmentLength+1))},
+		{name: "long name", file: NewFileName(strings.Repeat("a/", maxNameLength))},
+		{name: "parent directory", file: FileName("../shop.com")},
+		{name: "unescaped", file: FileName("Shop.com")},
+		{name: "invalid escape", file: FileName("shop%ZZ.com")},
+		{name: "truncated escape", file: FileName("shop%2")},
+		{name: "needless escape", file: FileName("shop%2Ecom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.file.Validate(); !errors.Is(err, ErrInvalidFileName) {
+				t.Fatalf("Expected ErrInvalidFileName, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileSystem_InvalidFileName(t *testing.T) {
+	ctx := context.Background()
+	fs := NewFileSystem(WithDir(t.TempDir()))
+	fileName := NewFileName(strings.Repeat("a", maxSegmentLength+1))
+
+	if err := fs.Save(ctx, fileName, "{}"); !errors.Is(err, ErrInvalidFileName) {
+		t.Fatalf("Expected ErrInvalidFileName on save, got %v", err)
+	}
+
+	if err := fs.Get(ctx, fileName, &map[string]any{}); !errors.Is(err, ErrInvalidFileName) {
+		t.Fatalf("Expected ErrInvalidFileName on get, got %v", err)
+	}
+
+	if _, err := fs.FileExists(ctx, fileName); !errors.Is(err, ErrInvalidFileName) {
+		t.Fatalf("Expected ErrInvalidFileName on exists, got %v", err)
+	}
+}
+
+// FuzzNewFileName checks that any key is either rejected or stored at a
+// path inside the directory, from which the key is read back.
+func FuzzNewFileName(f *testing.F) {
+	for _, seed := range []string{
+		"shop.com", "captions/123", "..", "../..", "./.", "/etc/passwd", `..\..\windows`,
+		"%2e%2e%2f", "a/../../b", "\x00", ".tmp-1", strings.Repeat("x", 300),
+	} {
+		f.Add(seed)
+	}
+
+	dir := f.TempDir()
+	f.Fuzz(func(t *testing.T, key string) {
+		name := NewFileName(key)
+		if err := name.Validate(); err != nil {
+			return
+		}
+
+		if name.Key() != key {
+			t.Fatalf("Expected key %q, got %q", key, name.Key())
+		}
+
+		for _, layout := range []Layout{FlatLayout, ShardedLayout} {
+			path := filepath.Join(dir, layout.Path(name))
+
+			rel, err := filepath.Rel(dir, path)
+			if err != nil || rel == "." || strings.HasPrefix(rel, "..") || filepath.IsAbs(rel) {
+				t.Fatalf("Path %q of %q leaves the directory", path, key)
+			}
+
+			for _, part := range strings.Split(filepath.ToSlash(rel), "/") {
+				if strings.HasPrefix(part, ".") || len(part) > 255-len(".x.tmp-")-20 {
+					t.Fatalf("Unsafe part %q in the path of %q", part, key)
+				}
+			}
+
+			if got, ok := layout.Name(rel); !ok || got != name {
+				t.Fatalf("Expected the name %q back from %q, got %q", name, rel, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const fileNameExt = ".json"
+
+// Layout maps the name of a file to its path in the directory of the
+// FileSystem. With Levels > 0 the files are fanned out to nested
+// directories named after the SHA-256 of the name, two hex characters
+// per level, e.g. "ab/cd/shop.com.json", so that no directory holds
+// millions of files.
+type Layout struct {
+	Levels int
+}
+
+var (
+	// FlatLayout stores every file directly in the directory, e.g. "shop.com.json".
+	FlatLayout = Layout{}
+	// ShardedLayout spreads the files over 65536 directories, e.g. "ab/cd/shop.com.json".
+	ShardedLayout = Layout{Levels: 2}
+)
+
+// ParseLayout returns the layout with the given name, "flat" or "sharded".
+func ParseLayout(name string) (Layout, error) {
+	switch name {
+	case "", "flat":
+		return FlatLayout, nil
+	case "sharded":
+		return ShardedLayout, nil
+	}
+
+	return Layout{}, fmt.Errorf("unknown layout %q", name)
+}
+
+// String returns the name of the layout.
+func (l Layout) String() string {
+	switch l {
+	case FlatLayout:
+		return "flat"
+	case ShardedLayout:
+		return "sharded"
+	}
+
+	return fmt.Sprintf("sharded(%d)", l.Levels)
+}
+
+// shards returns the directories of the name, from the top level down.
+func (l Layout) shards(name FileName) []string {
+	sum := sha256.Sum256([]byte(name))
+	digest := hex.EncodeToString(sum[:])
+
+	output := make([]string, l.Levels)
+	for i := range output {
+		output[i] = digest[i*2 : i*2+2]
+	}
+
+	return output
+}
+
+// Path returns the path of the file of name, relative to the directory.
+// The name is expected to be valid, the path is then always inside the
+// directory.
+func (l Layout) Path(name FileName) string {
+	parts := append(l.shards(name), filepath.FromSlash(name.String())+fileNameExt)
+	return filepath.Join(parts...)
+}
+
+// Name returns the name of the file at path, relative to the directory.
+// It returns false when the file was not stored with this layout.
+func (l Layout) Name(path string) (FileName, bool) {
+	path = filepath.ToSlash(path)
+	if !strings.HasSuffix(path, fileNameExt) {
+		return "", false
+	}
+
+	parts := strings.SplitN(path, "/", l.Levels+1)
+	if len(parts) != l.Levels+1 {
+		return "", false
+	}
+
+	name := FileName(strings.TrimSuffix(parts[l.Levels], fileNameExt))
+	if name.Validate() != nil || filepath.ToSlash(l.Path(name)) != path {
+		return "", false
+	}
+
+	return name, true
+}
+
+// legacyName returns the name of the file at path when it was stored with
+// this layout under its key as is, before the keys were escaped, e.g.
+// "Shop.com.json" for "%53hop.com.json". It returns false for the files
+// of Name.
+func (l Layout) legacyName(path string) (FileName, bool) {
+	path = filepath.ToSlash(path)
+	if !strings.HasSuffix(path, fileNameExt) {
+		return "", false
+	}
+
+	if _, ok := l.Name(path); ok {
+		return "", false
+	}
+
+	parts := strings.SplitN(path, "/", l.Levels+1)
+	if len(parts) != l.Levels+1 {
+		return "", false
+	}
+
+	// the path was made from the key, the shards from its hash
+	key := strings.TrimSuffix(parts[l.Levels], fileNameExt)
+	name := NewFileName(key)
+	if name.Validate() != nil || filepath.ToSlash(l.Path(FileName(key))) != path {
+		return "", false
+	}
+
+	return name, true
+}
@@ -0,0 +1,101 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLayout_Path(t *testing.T) {
+	tests := []struct {
+		layout Layout
+		key    string
+		want   string
+	}{
+		{layout: FlatLayout, key: "shop.com", want: "shop.com.json"},
+		{layout: FlatLayout, key: "captions/123", want: "captions/123.json"},
+		{layout: ShardedLayout, key: "shop.com", want: "a2/69/shop.com.json"},
+		{layout: ShardedLayout, key: "captions/123", want: "10/34/captions/123.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout.String()+"/"+tt.key, func(t *testing.T) {
+			path := tt.layout.Path(NewFileName(tt.key))
+			if filepath.ToSlash(path) != tt.want {
+				t.Fatalf("Expected path %s, got %s", tt.want, path)
+			}
+
+			name, ok := tt.layout.Name(path)
+			if !ok || name.Key() != tt.key {
+				t.Fatalf("Expected key %s, got %s, %v", tt.key, name, ok)
+			}
+		})
+	}
+}
+
+func TestLayout_NameOfOtherLayout(t *testing.T) {
+	for _, path := range []string{
+		"shop.com.json",
+		"00/00/shop.com.json", // wrong shard
+		"a2/69/shop.com.txt",
+		"a2/69/.shop.com.json.tmp-123",
+	} {
+		if name, ok := ShardedLayout.Name(path); ok {
+			t.Fatalf("Expected %s to be rejected, got name %s", path, name)
+		}
+	}
+}
+
+func TestFileSystem_ShardedLayout(t *testing.T) {
+	ctx := context.Background()
+	fs := NewFileSystem(WithDir(t.TempDir()), WithLayout(ShardedLayout), WithFileMode(0600, 0700))
+
+	for _, key := range []string{"b.com", "a.com", "c.com", "captions/123"} {
+		if err := fs.Save(ctx, NewFileName(key), `{"key":"`+key+`"}`); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	files, err := fs.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+
+	if len(files) != 3 || files[0] != "a.com" || files[1] != "b.com" || files[2] != "c.com" {
+		t.Fatalf("Unexpected files: %v", files)
+	}
+
+	path, _ := fs.getFullPath(NewFileName("a.com"))
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	if stat.Mode().Perm() != 0600 {
+		t.Fatalf("Expected mode 0600, got %v", stat.Mode().Perm())
+	}
+
+	stat, err = os.Stat(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("Failed to stat directory: %v", err)
+	}
+
+	if stat.Mode().Perm() != 0700 {
+		t.Fatalf("Expected directory mode 0700, got %v", stat.Mode().Perm())
+	}
+}
+
+func TestFileSystem_IndependentInstances(t *testing.T) {
+	ctx := context.Background()
+	first := NewFileSystem(WithDir(t.TempDir()))
+	second := NewFileSystem(WithDir(t.TempDir()))
+
+	if err := first.Save(ctx, NewFileName("shop.com"), "{}"); err != nil {
+		t.Fatalf("Failed to save data: %v", err)
+	}
+
+	if exists, _ := second.FileExists(ctx, NewFileName("shop.com")); exists {
+		t.Fatal("Expected the instances not to share their files")
+	}
+}
@@ -0,0 +1,29 @@
+package filesystem
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// lockStripes is the number of locks shared by all the files. Two keys only
+// contend when they hash to the same stripe, which is rare with many more
+// stripes than files written at the same time.
+const lockStripes = 256
+
+// keyLocks guards the files by key with a fixed set of locks, so a write to
+// one file doesn't block the reads of the others. The lock of a key is never
+// taken twice by the same operation: RWMutex is not reentrant, and a second
+// RLock waits for any writer queued after the first one.
+type keyLocks []sync.RWMutex
+
+func newKeyLocks(stripes int) keyLocks {
+	return make(keyLocks, stripes)
+}
+
+// get returns the lock of the key.
+func (l keyLocks) get(key FileName) *sync.RWMutex {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+
+	return &l[h.Sum32()%uint32(len(l))]
+}
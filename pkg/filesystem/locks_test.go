@@ -0,0 +1,86 @@
+package filesystem
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFileSystem_LocksPerKey(t *testing.T) {
+	fs := newFileSystem(t.TempDir(), osFileOps{})
+	ctx := context.Background()
+
+	busy, other := NewFileName("busy.com"), NewFileName("other.com")
+	if fs.locks.get(busy) == fs.locks.get(other) {
+		t.Fatal("Expected the keys to use different stripes")
+	}
+
+	for _, key := range []FileName{busy, other} {
+		if err := fs.Save(ctx, key, "{}"); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	// a slow write holds the lock of busy.com
+	fs.locks.get(busy).Lock()
+
+	done := make(chan error, 2)
+	go func() {
+		var data map[string]any
+		done <- fs.Get(ctx, other, &data)
+	}()
+	go func() {
+		done <- fs.Save(ctx, other, `{"changed":true}`)
+	}()
+
+	for range 2 {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Failed to access other.com: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Access to other.com blocked by the lock of busy.com")
+		}
+	}
+
+	fs.locks.get(busy).Unlock()
+}
+
+func TestFileSystem_GetWithWriterWaiting(t *testing.T) {
+	fs := newFileSystem(t.TempDir(), osFileOps{})
+	ctx := context.Background()
+	key := NewFileName("shop.com")
+
+	if err := fs.Save(ctx, key, "{}"); err != nil {
+		t.Fatalf("Failed to save data: %v", err)
+	}
+
+	// a reader holds the lock while a writer waits for it: a Get that
+	// took the read lock twice would queue behind the writer forever
+	lock := fs.locks.get(key)
+	lock.RLock()
+
+	saved := make(chan error, 1)
+	go func() { saved <- fs.Save(ctx, key, "{}") }()
+	time.Sleep(10 * time.Millisecond)
+
+	got := make(chan error, 1)
+	go func() {
+		var data map[string]any
+		got <- fs.Get(ctx, key, &data)
+	}()
+
+	lock.RUnlock()
+
+	for _, ch := range []chan error{saved, got} {
+		select {
+		case err := <-ch:
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Deadlock between Get and Save")
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// relayoutMove is a file to move from its path in the previous layout.
+type relayoutMove struct {
+	key  FileName
+	path string
+}
+
+// Relayout moves the files stored in the directory with the from layout
+// to the layout of fs, and returns the number of files moved. Files already
+// in the layout of fs are left in place, so an interrupted Relayout can be
+// run again. The directories emptied by the moves are removed. A key stored
+// with both layouts is an error, as it's unknown which file is the latest.
+// Each file is moved by a rename, but the files are not all moved at once:
+// a FileSystem using either layout misses part of them until it returns.
+func (fs *FileSystem) Relayout(ctx context.Context, from Layout) (int, error) {
+	if from == fs.layout {
+		return 0, nil
+	}
+
+	// the moves are planned before any is done, so the walk
+	// doesn't see the files already moved
+	var moves []relayoutMove
+	var dirs []string
+	err := filepath.WalkDir(fs.dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(fs.dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+
+		if entry.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+
+		// a path valid in both layouts, such as "ab/cd/shop.com.json" which
+		// is also a flat name, belongs to the one with more levels: it can't
+		// match the hash of its key by chance
+		name, ok := from.Name(rel)
+		if !ok {
+			name, ok = from.legacyName(rel)
+		}
+		if _, other := fs.layout.Name(rel); !ok || (other && fs.layout.Levels > from.Levels) {
+			return nil
+		}
+
+		moves = append(moves, relayoutMove{key: name, path: path})
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	moved := 0
+	for _, move := range moves {
+		if err := ctx.Err(); err != nil {
+			return moved, err
+		}
+
+		if err := fs.move(move); err != nil {
+			return moved, fmt.Errorf("failed to move %s: %w", move.key, err)
+		}
+		moved++
+	}
+
+	// deepest first, a directory is removed only when empty
+	slices.Reverse(dirs)
+	for _, dir := range dirs {
+		os.Remove(dir)
+	}
+
+	return moved, nil
+}
+
+// move renames the file to its path in the layout of fs.
+func (fs *FileSystem) move(move relayoutMove) error {
+	lock := fs.locks.get(move.key)
+	lock.Lock()
+	defer lock.Unlock()
+
+	path, err := fs.getFullPath(move.key)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s is already stored", path)
+	}
+
+	if err := fs.ensureDir(path); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	if err := fs.ops.Rename(move.path, path); err != nil {
+		return err
+	}
+
+	return fs.ops.SyncDir(filepath.Dir(path))
+}
+
+// renameLegacyFiles moves the files stored under their key as is, before
+// the keys were escaped, to their escaped name, e.g. "Shop.com.json" to
+// "%53hop.com.json", so they are found again. A file whose escaped name is
+// already stored is left in place.
+func (fs *FileSystem) renameLegacyFiles() (int, error) {
+	var moves []relayoutMove
+	err := filepath.WalkDir(fs.dir, func(path string, entry os.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(fs.dir, path)
+		if err != nil {
+			return err
+		}
+
+		if name, ok := fs.layout.legacyName(rel); ok {
+			moves = append(moves, relayoutMove{key: name, path: path})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	moved := 0
+	for _, move := range moves {
+		if err := fs.move(move); err != nil {
+			log.Printf("[WARNING] Failed to rename %s: %v", move.path, err)
+			continue
+		}
+		moved++
+	}
+
+	return moved, nil
+}
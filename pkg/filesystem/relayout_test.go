@@ -0,0 +1,152 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileSystem_Relayout(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	keys := []string{"shop.com", "other.com", "captions/123", "videos/intro"}
+
+	flat := NewFileSystem(WithDir(dir))
+	for _, key := range keys {
+		if err := flat.Save(ctx, NewFileName(key), `{"key":"`+key+`"}`); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	sharded := NewFileSystem(WithDir(dir), WithLayout(ShardedLayout))
+	moved, err := sharded.Relayout(ctx, FlatLayout)
+	if err != nil {
+		t.Fatalf("Failed to relayout: %v", err)
+	}
+
+	if moved != len(keys) {
+		t.Fatalf("Expected %d files to be moved, got %d", len(keys), moved)
+	}
+
+	for _, key := range keys {
+		var data map[string]any
+		if err := sharded.Get(ctx, NewFileName(key), &data); err != nil {
+			t.Fatalf("Failed to get %s: %v", key, err)
+		}
+
+		if data["key"] != key {
+			t.Fatalf("Unexpected content of %s: %v", key, data)
+		}
+
+		if exists, _ := flat.FileExists(ctx, NewFileName(key)); exists {
+			t.Fatalf("Expected %s to be moved out of the flat layout", key)
+		}
+	}
+
+	// the emptied "captions" and "videos" directories are removed
+	if _, err := os.Stat(dir + "/captions"); !os.IsNotExist(err) {
+		t.Fatalf("Expected the captions directory to be removed, got %v", err)
+	}
+
+	// running it again moves nothing
+	moved, err = sharded.Relayout(ctx, FlatLayout)
+	if err != nil || moved != 0 {
+		t.Fatalf("Expected nothing to move, got %d, %v", moved, err)
+	}
+
+	// and back
+	moved, err = flat.Relayout(ctx, ShardedLayout)
+	if err != nil || moved != len(keys) {
+		t.Fatalf("Expected %d files to be moved back, got %d, %v", len(keys), moved, err)
+	}
+
+	files, err := flat.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list files: %v", err)
+	}
+
+	if len(files) != 2 || files[0] != "other.com" || files[1] != "shop.com" {
+		t.Fatalf("Unexpected files: %v", files)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 4 {
+		t.Fatalf("Expected the shard directories to be removed, got %d entries", len(entries))
+	}
+}
+
+func TestFileSystem_RelayoutConflict(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	flat := NewFileSystem(WithDir(dir))
+	sharded := NewFileSystem(WithDir(dir), WithLayout(ShardedLayout))
+	for _, fs := range []*FileSystem{flat, sharded} {
+		if err := fs.Save(ctx, NewFileName("shop.com"), "{}"); err != nil {
+			t.Fatalf("Failed to save data: %v", err)
+		}
+	}
+
+	if _, err := sharded.Relayout(ctx, FlatLayout); err == nil {
+		t.Fatal("Expected a key stored with both layouts to fail")
+	}
+}
+
+func TestFileSystem_RenameLegacyFiles(t *testing.T) {
+	ctx := context.Background()
+	keys := []string{"Shop.com", "captions/AbC", "shop.com"}
+
+	for _, layout := range []Layout{FlatLayout, ShardedLayout} {
+		t.Run(layout.String(), func(t *testing.T) {
+			dir := t.TempDir()
+
+			// files written before the keys were escaped, e.g. "Shop.com.json"
+			for _, key := range keys {
+				path := filepath.Join(dir, layout.Path(FileName(key)))
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatalf("Failed to create directory: %v", err)
+				}
+
+				if err := os.WriteFile(path, []byte(`{"key":"`+key+`"}`), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			fs := NewFileSystem(WithDir(dir), WithLayout(layout))
+			for _, key := range keys {
+				var data map[string]any
+				if err := fs.Get(ctx, NewFileName(key), &data); err != nil {
+					t.Fatalf("Failed to get %s: %v", key, err)
+				}
+
+				if data["key"] != key {
+					t.Fatalf("Unexpected content of %s: %v", key, data)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, layout.Path(FileName("Shop.com")))); !os.IsNotExist(err) {
+				t.Fatalf("Expected the legacy file to be renamed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFileSystem_RelayoutLegacyFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "Shop.com.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	sharded := NewFileSystem(WithDir(dir), WithLayout(ShardedLayout))
+	moved, err := sharded.Relayout(ctx, FlatLayout)
+	if err != nil || moved != 1 {
+		t.Fatalf("Expected the legacy file to be moved, got %d, %v", moved, err)
+	}
+
+	if exists, err := sharded.FileExists(ctx, NewFileName("Shop.com")); err != nil || !exists {
+		t.Fatalf("Expected Shop.com in the sharded layout, got %v, %v", exists, err)
+	}
+}
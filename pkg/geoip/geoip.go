@@ -0,0 +1,184 @@
+// Package geoip resolves the region of a client from a local
+// MaxMind-format (.mmdb) database loaded at startup.
+// No network lookup is ever made.
+package geoip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Region identifies where a client is, using ISO 3166 codes.
+type Region struct {
+	Country     string // ISO 3166-1 alpha-2, e.g. "BR"
+	Subdivision string // ISO 3166-2 subdivision without the country, e.g. "SP"
+}
+
+// Code returns the region as "BR-SP", or only "BR" when the
+// subdivision is unknown. It returns "" for an empty region.
+func (r Region) Code() string {
+	if r.Country == "" {
+		return ""
+	}
+
+	if r.Subdivision == "" {
+		return r.Country
+	}
+
+	return r.Country + "-" + r.Subdivision
+}
+
+// record is the subset of the GeoIP2/GeoLite2 City and Country
+// schema needed to build a Region.
+type record struct {
+	Country struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		IsoCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Resolver looks up regions in an mmdb file and extracts the client IP
+// from requests, honouring X-Forwarded-For only from trusted proxies.
+type Resolver struct {
+	db             *maxminddb.Reader
+	trustedProxies []netip.Prefix
+}
+
+// NewResolver opens the database at dbPath. trustedProxies is a list of
+// IPs or CIDRs whose X-Forwarded-For header can be trusted.
+func NewResolver(dbPath string, trustedProxies []string) (*Resolver, error) {
+	prefixes, err := parsePrefixes(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := maxminddb.Open(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+
+	return &Resolver{db: db, trustedProxies: prefixes}, nil
+}
+
+// Close releases the database.
+func (r *Resolver) Close() error {
+	return r.db.Close()
+}
+
+// Lookup returns the region of the given IP.
+// The boolean is false when the IP is not in the database.
+func (r *Resolver) Lookup(ip netip.Addr) (Region, bool, error) {
+	var rec record
+	if err := r.db.Lookup(net.IP(ip.Unmap().AsSlice()), &rec); err != nil {
+		return Region{}, false, fmt.Errorf("failed to lookup ip: %w", err)
+	}
+
+	if rec.Country.IsoCode == "" {
+		return Region{}, false, nil
+	}
+
+	region := Region{Country: strings.ToUpper(rec.Country.IsoCode)}
+	if len(rec.Subdivisions) > 0 {
+		region.Subdivision = strings.ToUpper(rec.Subdivisions[0].IsoCode)
+	}
+
+	return region, true, nil
+}
+
+// Resolve returns the region code of the client that sent the request.
+func (r *Resolver) Resolve(req *http.Request) (string, bool) {
+	ip, ok := r.ClientIP(req)
+	if !ok {
+		return "", false
+	}
+
+	region, found, err := r.Lookup(ip)
+	if err != nil || !found {
+		return "", false
+	}
+
+	return region.Code(), true
+}
+
+// ClientIP returns the IP of the client. The X-Forwarded-For chain is only
+// followed while the hop that appended it is a trusted proxy, so clients
+// cannot spoof their address by sending the header themselves.
+func (r *Resolver) ClientIP(req *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	if !r.isTrusted(ip) {
+		return ip.Unmap(), true
+	}
+
+	var hops []string
+	for _, header := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+
+		ip = hop
+		if !r.isTrusted(hop) {
+			break
+		}
+	}
+
+	return ip.Unmap(), true
+}
+
+func (r *Resolver) isTrusted(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range r.trustedProxies {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
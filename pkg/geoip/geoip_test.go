@@ -0,0 +1,114 @@
+package geoip
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testdata/geoip-test.mmdb is a GeoIP2-City shaped database containing:
+//
+//	81.2.69.0/24   -> GB-ENG
+//	177.10.0.0/16  -> BR-SP
+//	200.100.0.0/16 -> BR (no subdivision)
+//	2001:db8::/32  -> US-CA
+const testDatabase = "testdata/geoip-test.mmdb"
+
+func TestResolver_Lookup(t *testing.T) {
+	resolver, err := NewResolver(testDatabase, nil)
+	require.NoError(t, err)
+	defer resolver.Close()
+
+	tests := []struct {
+		name      string
+		ip        string
+		wantFound bool
+		wantCode  string
+	}{
+		{name: "country and subdivision", ip: "177.10.20.30", wantFound: true, wantCode: "BR-SP"},
+		{name: "country only", ip: "200.100.1.1", wantFound: true, wantCode: "BR"},
+		{name: "ipv6", ip: "2001:db8::1", wantFound: true, wantCode: "US-CA"},
+		{name: "ipv4 mapped in ipv6", ip: "::ffff:81.2.69.10", wantFound: true, wantCode: "GB-ENG"},
+		{name: "unknown ip", ip: "10.0.0.1", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			region, found, err := resolver.Lookup(netip.MustParseAddr(tt.ip))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.wantCode, region.Code())
+		})
+	}
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver, err := NewResolver(testDatabase, []string{"10.0.0.0/8", "192.168.1.1"})
+	require.NoError(t, err)
+	defer resolver.Close()
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		wantFound    bool
+		wantCode     string
+	}{
+		{
+			name:       "direct client",
+			remoteAddr: "177.10.0.1:5555",
+			wantFound:  true,
+			wantCode:   "BR-SP",
+		},
+		{
+			name:         "forwarded header from untrusted client is ignored",
+			remoteAddr:   "200.100.0.1:5555",
+			forwardedFor: []string{"177.10.0.1"},
+			wantFound:    true,
+			wantCode:     "BR",
+		},
+		{
+			name:         "forwarded by trusted proxy",
+			remoteAddr:   "10.1.2.3:5555",
+			forwardedFor: []string{"81.2.69.1"},
+			wantFound:    true,
+			wantCode:     "GB-ENG",
+		},
+		{
+			name:         "spoofed hop before the real client is ignored",
+			remoteAddr:   "10.1.2.3:5555",
+			forwardedFor: []string{"177.10.0.1, 200.100.0.1", "192.168.1.1"},
+			wantFound:    true,
+			wantCode:     "BR",
+		},
+		{
+			name:       "not in database",
+			remoteAddr: "8.8.8.8:5555",
+			wantFound:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, h := range tt.forwardedFor {
+				req.Header.Add("X-Forwarded-For", h)
+			}
+
+			code, found := resolver.Resolve(req)
+
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.wantCode, code)
+		})
+	}
+}
+
+func TestNewResolver_InvalidProxy(t *testing.T) {
+	_, err := NewResolver(testDatabase, []string{"not-an-ip"})
+	assert.Error(t, err)
+}
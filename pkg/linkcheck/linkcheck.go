@@ -0,0 +1,345 @@
+// Package linkcheck checks that urls are still reachable with HEAD
+// requests, limiting their rate and concurrency and retrying transient
+// failures with exponential backoff. The urls come from the content
+// registered, so by default only public addresses are requested.
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultConcurrency   = 4
+	DefaultRatePerSecond = 10
+	DefaultRetries       = 2
+	DefaultBackoff       = time.Second
+	DefaultMaxBackoff    = 30 * time.Second
+	DefaultTimeout       = 10 * time.Second
+)
+
+// Config tunes the Checker, zero values use the defaults.
+type Config struct {
+	// Concurrency is the number of requests in flight at the same time.
+	Concurrency int
+	// RatePerSecond is the maximum number of requests started per second,
+	// retries included.
+	RatePerSecond float64
+	// Retries is how many times a transient failure (network error, 429 or
+	// 5xx) is retried. Negative disables retries.
+	Retries int
+	// Backoff is the wait before the first retry, doubled on each retry up to
+	// MaxBackoff. A Retry-After header from the server takes precedence.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout limits each request.
+	Timeout time.Duration
+	// Client sends the requests, NewPublicClient() when nil.
+	Client *http.Client
+}
+
+// Result is the outcome of checking a url.
+type Result struct {
+	Url         string
+	StatusCode  int
+	ContentType string
+	// Size is the length of the file, -1 when the server doesn't tell.
+	Size      int64
+	Err       error
+	Attempts  int
+	CheckedAt time.Time
+}
+
+// Broken reports whether the url could not be reached or answered with an
+// error status. Skipped urls are not broken, they were not requested.
+func (r Result) Broken() bool {
+	if r.Skipped() {
+		return false
+	}
+
+	return r.Err != nil || r.StatusCode >= http.StatusBadRequest
+}
+
+// Skipped reports whether the url was refused without being requested, as
+// the urls of an address that isn't public.
+func (r Result) Skipped() bool {
+	return errors.Is(r.Err, ErrForbiddenAddress)
+}
+
+type Checker struct {
+	cfg     Config
+	limiter *limiter
+}
+
+func NewChecker(cfg Config) *Checker {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = DefaultConcurrency
+	}
+
+	if cfg.RatePerSecond <= 0 {
+		cfg.RatePerSecond = DefaultRatePerSecond
+	}
+
+	switch {
+	case cfg.Retries == 0:
+		cfg.Retries = DefaultRetries
+	case cfg.Retries < 0:
+		cfg.Retries = 0
+	}
+
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = DefaultBackoff
+	}
+
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = NewPublicClient()
+	}
+
+	return &Checker{
+		cfg:     cfg,
+		limiter: &limiter{interval: time.Duration(float64(time.Second) / cfg.RatePerSecond)},
+	}
+}
+
+// Check checks the urls and returns their results in the same order.
+// When ctx is done, the urls left unchecked have ctx.Err() as their error.
+func (c *Checker) Check(ctx context.Context, urls []string) []Result {
+	results := make([]Result, len(urls))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(c.cfg.Concurrency, len(urls)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = c.check(ctx, urls[i])
+			}
+		}()
+	}
+
+	for i := range urls {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
+
+func (c *Checker) check(ctx context.Context, rawUrl string) Result {
+	backoff := c.cfg.Backoff
+
+	for attempt := 1; ; attempt++ {
+		result, retryAfter, retry := c.request(ctx, rawUrl)
+		result.Attempts = attempt
+
+		if !retry || attempt > c.cfg.Retries {
+			return result
+		}
+
+		wait := backoff
+		if retryAfter > 0 {
+			wait = min(retryAfter, c.cfg.MaxBackoff)
+		}
+		backoff = min(backoff*2, c.cfg.MaxBackoff)
+
+		if err := sleep(ctx, wait); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+}
+
+// request sends a HEAD request, or a single byte GET when the server
+// doesn't support HEAD. It reports whether the failure is worth a retry.
+func (c *Checker) request(ctx context.Context, rawUrl string) (Result, time.Duration, bool) {
+	result := Result{Url: rawUrl, Size: -1}
+
+	res, err := c.do(ctx, http.MethodHead, rawUrl)
+	if err == nil && (res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented) {
+		res, err = c.do(ctx, http.MethodGet, rawUrl)
+	}
+	result.CheckedAt = time.Now()
+
+	if err != nil {
+		result.Err = err
+		// cancellation of the whole check, invalid urls and forbidden
+		// addresses are final
+		return result, 0, ctx.Err() == nil && !errors.Is(err, errInvalidRequest) && !errors.Is(err, ErrForbiddenAddress)
+	}
+
+	result.StatusCode = res.StatusCode
+	result.ContentType = res.Header.Get("Content-Type")
+	result.Size = size(res)
+
+	retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= http.StatusInternalServerError
+	return result, retryAfter(res.Header.Get("Retry-After")), retry
+}
+
+var errInvalidRequest = errors.New("linkcheck: invalid request")
+
+func (c *Checker) do(ctx context.Context, method, rawUrl string) (*http.Response, error) {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidRequest, err)
+	}
+
+	if method == http.MethodGet {
+		req.Header.Set("Range", "bytes=0-0")
+	}
+
+	res, err := c.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// the body is drained so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<10))
+	res.Body.Close()
+
+	return res, nil
+}
+
+// ErrForbiddenAddress is the error of the requests to an address that isn't
+// public, such as loopback, private networks or the link-local metadata
+// services of the cloud providers (169.254.169.254).
+var ErrForbiddenAddress = errors.New("linkcheck: forbidden address")
+
+// sharedAddressSpace is the carrier-grade NAT range, not public either.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewPublicClient returns a client that only connects to public addresses.
+// The address is checked when connecting, after the name is resolved and
+// for every redirect, so a name resolving to an internal address is
+// refused as well. Proxies from the environment are not used, the address
+// checked would be the one of the proxy.
+func NewPublicClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   publicOnly,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Transport: transport}
+}
+
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+
+	ip, err := netip.ParseAddr(host)
+	if err != nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+	}
+
+	return nil
+}
+
+// IsPublic reports whether ip is a public unicast address.
+func IsPublic(ip netip.Addr) bool {
+	ip = ip.Unmap()
+
+	return ip.IsGlobalUnicast() &&
+		!ip.IsPrivate() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// size returns the length of the file, which is the total of the
+// Content-Range when the server answered a range request.
+func size(res *http.Response) int64 {
+	if res.StatusCode == http.StatusPartialContent {
+		_, total, ok := strings.Cut(res.Header.Get("Content-Range"), "/")
+		if n, err := strconv.ParseInt(total, 10, 64); ok && err == nil {
+			return n
+		}
+
+		return -1
+	}
+
+	return res.ContentLength
+}
+
+// retryAfter parses the seconds or the date of a Retry-After header.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// limiter spaces the requests by interval, shared by every worker.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		return sleep(ctx, d)
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,213 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Check(t *testing.T) {
+	var flaky atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/video.mp4", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodHead, r.Method)
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Length", "1024")
+	})
+	mux.HandleFunc("/flaky.jpg", func(w http.ResponseWriter, r *http.Request) {
+		if flaky.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "image/jpeg")
+	})
+	mux.HandleFunc("/no-head.mp4", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		assert.Equal(t, "bytes=0-0", r.Header.Get("Range"))
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("Content-Range", "bytes 0-0/4096")
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write([]byte{0})
+	})
+	mux.HandleFunc("/down.mp4", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	checker := NewChecker(Config{RatePerSecond: 1000, Backoff: time.Millisecond, Client: server.Client()})
+
+	results := checker.Check(context.Background(), []string{
+		server.URL + "/video.mp4",
+		server.URL + "/missing.mp4",
+		server.URL + "/flaky.jpg",
+		server.URL + "/no-head.mp4",
+		server.URL + "/down.mp4",
+		"://invalid",
+	})
+
+	assert.Len(t, results, 6)
+
+	ok := results[0]
+	assert.False(t, ok.Broken())
+	assert.Equal(t, server.URL+"/video.mp4", ok.Url)
+	assert.Equal(t, http.StatusOK, ok.StatusCode)
+	assert.Equal(t, "video/mp4", ok.ContentType)
+	assert.Equal(t, int64(1024), ok.Size)
+	assert.Equal(t, 1, ok.Attempts)
+	assert.False(t, ok.CheckedAt.IsZero())
+
+	missing := results[1]
+	assert.True(t, missing.Broken())
+	assert.Equal(t, http.StatusNotFound, missing.StatusCode)
+	assert.Equal(t, 1, missing.Attempts, "client errors are not retried")
+
+	retried := results[2]
+	assert.False(t, retried.Broken())
+	assert.Equal(t, 3, retried.Attempts)
+
+	noHead := results[3]
+	assert.False(t, noHead.Broken())
+	assert.Equal(t, int64(4096), noHead.Size)
+
+	down := results[4]
+	assert.True(t, down.Broken())
+	assert.Equal(t, http.StatusBadGateway, down.StatusCode)
+	assert.Equal(t, 1+DefaultRetries, down.Attempts)
+
+	invalid := results[5]
+	assert.True(t, invalid.Broken())
+	assert.Error(t, invalid.Err)
+	assert.Equal(t, 1, invalid.Attempts)
+}
+
+func TestChecker_Limits(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 20)
+	for i := range urls {
+		urls[i] = server.URL
+	}
+
+	start := time.Now()
+	results := NewChecker(Config{Concurrency: 2, RatePerSecond: 200, Client: server.Client()}).Check(context.Background(), urls)
+
+	for _, r := range results {
+		assert.False(t, r.Broken())
+	}
+
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+	// 20 requests spaced by 5ms
+	assert.GreaterOrEqual(t, time.Since(start), 19*5*time.Millisecond)
+}
+
+func TestChecker_RetryAfter(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	checker := NewChecker(Config{RatePerSecond: 1000, Backoff: time.Millisecond, MaxBackoff: 20 * time.Millisecond, Client: server.Client()})
+
+	start := time.Now()
+	result := checker.Check(context.Background(), []string{server.URL})[0]
+
+	assert.False(t, result.Broken())
+	assert.Equal(t, 2, result.Attempts)
+	// Retry-After is capped by MaxBackoff
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 20*time.Millisecond)
+	assert.Less(t, elapsed, time.Second)
+}
+
+func TestChecker_Cancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result := NewChecker(Config{Backoff: time.Hour, Client: server.Client()}).Check(ctx, []string{server.URL})[0]
+
+	assert.True(t, result.Broken())
+	assert.ErrorIs(t, result.Err, context.DeadlineExceeded)
+}
+
+func TestChecker_ForbiddenAddress(t *testing.T) {
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer server.Close()
+
+	// the default client refuses the loopback server, and the metadata
+	// service of the cloud providers
+	results := NewChecker(Config{RatePerSecond: 1000, Backoff: time.Millisecond}).Check(context.Background(), []string{
+		server.URL,
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/video.mp4",
+		"http://[::1]/video.mp4",
+	})
+
+	for _, result := range results {
+		assert.True(t, result.Skipped(), result.Url)
+		assert.False(t, result.Broken(), result.Url)
+		assert.ErrorIs(t, result.Err, ErrForbiddenAddress, result.Url)
+		assert.Equal(t, 1, result.Attempts, result.Url)
+	}
+	assert.Zero(t, calls.Load())
+}
+
+func TestIsPublic(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.64.0.1":       false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+		"224.0.0.1":        false,
+	} {
+		assert.Equal(t, want, IsPublic(netip.MustParseAddr(addr)), addr)
+	}
+}
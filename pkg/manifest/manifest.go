@@ -0,0 +1,195 @@
+// Package manifest generates adaptive streaming manifests: the HLS
+// master playlist (RFC 8216) and the MPEG-DASH MPD (ISO/IEC 23009-1).
+package manifest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HLSContentType  = "application/vnd.apple.mpegurl"
+	DASHContentType = "application/dash+xml"
+
+	defaultMimeType = "video/mp4"
+)
+
+var ErrNoVariants = errors.New("manifest: no variants")
+
+// codecsPattern is a comma separated list of RFC 6381 codecs, which are
+// written as is in the quoted CODECS attribute of HLS and the codecs
+// attribute of DASH.
+var codecsPattern = regexp.MustCompile(`^[A-Za-z0-9.+-]+(,[A-Za-z0-9.+-]+)*$`)
+
+// ValidCodecs reports whether codecs is a list of RFC 6381 codecs, e.g.
+// "avc1.64001f,mp4a.40.2", without spaces.
+func ValidCodecs(codecs string) bool {
+	return codecsPattern.MatchString(codecs)
+}
+
+// Variant is one encoding (rendition) of the same content.
+type Variant struct {
+	Uri       string  // media playlist for HLS, media file for DASH
+	Bandwidth int     // peak bits per second
+	Width     int     // optional
+	Height    int     // optional
+	Codecs    string  // RFC 6381 codecs, e.g. "avc1.64001f,mp4a.40.2"
+	FrameRate float64 // optional
+	MimeType  string  // DASH only, defaults to video/mp4
+}
+
+// HLSMaster returns the master playlist listing every variant in the given order.
+func HLSMaster(variants []Variant) ([]byte, error) {
+	if len(variants) == 0 {
+		return nil, ErrNoVariants
+	}
+
+	var b bytes.Buffer
+	b.WriteString("#EXTM3U\n")
+	b.WriteString("#EXT-X-VERSION:3\n")
+	b.WriteString("#EXT-X-INDEPENDENT-SEGMENTS\n")
+
+	for _, v := range variants {
+		if v.Uri == "" || v.Bandwidth <= 0 {
+			return nil, fmt.Errorf("manifest: variant requires uri and bandwidth")
+		}
+
+		attrs := []string{"BANDWIDTH=" + strconv.Itoa(v.Bandwidth)}
+		if v.Width > 0 && v.Height > 0 {
+			attrs = append(attrs, fmt.Sprintf("RESOLUTION=%dx%d", v.Width, v.Height))
+		}
+		if v.Codecs != "" {
+			if !ValidCodecs(v.Codecs) {
+				return nil, fmt.Errorf("manifest: invalid codecs %q", v.Codecs)
+			}
+			attrs = append(attrs, `CODECS="`+v.Codecs+`"`)
+		}
+		if v.FrameRate > 0 {
+			attrs = append(attrs, "FRAME-RATE="+strconv.FormatFloat(v.FrameRate, 'f', 3, 64))
+		}
+
+		b.WriteString("#EXT-X-STREAM-INF:" + strings.Join(attrs, ",") + "\n")
+		b.WriteString(v.Uri + "\n")
+	}
+
+	return b.Bytes(), nil
+}
+
+type mpd struct {
+	XMLName                   xml.Name `xml:"MPD"`
+	Xmlns                     string   `xml:"xmlns,attr"`
+	Profiles                  string   `xml:"profiles,attr"`
+	Type                      string   `xml:"type,attr"`
+	MediaPresentationDuration string   `xml:"mediaPresentationDuration,attr"`
+	MinBufferTime             string   `xml:"minBufferTime,attr"`
+	Period                    period   `xml:"Period"`
+}
+
+type period struct {
+	Id             string          `xml:"id,attr"`
+	Start          string          `xml:"start,attr"`
+	AdaptationSets []adaptationSet `xml:"AdaptationSet"`
+}
+
+type adaptationSet struct {
+	Id               int              `xml:"id,attr"`
+	ContentType      string           `xml:"contentType,attr"`
+	MimeType         string           `xml:"mimeType,attr"`
+	SegmentAlignment bool             `xml:"segmentAlignment,attr"`
+	StartWithSAP     int              `xml:"startWithSAP,attr"`
+	Representations  []representation `xml:"Representation"`
+}
+
+type representation struct {
+	Id        string `xml:"id,attr"`
+	Bandwidth int    `xml:"bandwidth,attr"`
+	Width     int    `xml:"width,attr,omitempty"`
+	Height    int    `xml:"height,attr,omitempty"`
+	Codecs    string `xml:"codecs,attr,omitempty"`
+	FrameRate string `xml:"frameRate,attr,omitempty"`
+	BaseURL   string `xml:"BaseURL"`
+}
+
+// DASH returns a static on-demand MPD. Variants are grouped in one
+// adaptation set per mime type, keeping the order they were given.
+func DASH(variants []Variant, duration time.Duration) ([]byte, error) {
+	if len(variants) == 0 {
+		return nil, ErrNoVariants
+	}
+
+	if duration <= 0 {
+		return nil, errors.New("manifest: DASH requires the media duration")
+	}
+
+	doc := mpd{
+		Xmlns:                     "urn:mpeg:dash:schema:mpd:2011",
+		Profiles:                  "urn:mpeg:dash:profile:isoff-on-demand:2011",
+		Type:                      "static",
+		MediaPresentationDuration: isoDuration(duration),
+		MinBufferTime:             "PT2S",
+		Period:                    period{Id: "0", Start: "PT0S"},
+	}
+
+	sets := map[string]int{}
+	for i, v := range variants {
+		if v.Uri == "" || v.Bandwidth <= 0 {
+			return nil, fmt.Errorf("manifest: variant requires uri and bandwidth")
+		}
+
+		mimeType := v.MimeType
+		if mimeType == "" {
+			mimeType = defaultMimeType
+		}
+
+		idx, ok := sets[mimeType]
+		if !ok {
+			idx = len(doc.Period.AdaptationSets)
+			sets[mimeType] = idx
+			contentType, _, _ := strings.Cut(mimeType, "/")
+			doc.Period.AdaptationSets = append(doc.Period.AdaptationSets, adaptationSet{
+				Id:               idx,
+				ContentType:      contentType,
+				MimeType:         mimeType,
+				SegmentAlignment: true,
+				StartWithSAP:     1,
+			})
+		}
+
+		rep := representation{
+			Id:        strconv.Itoa(i),
+			Bandwidth: v.Bandwidth,
+			Width:     v.Width,
+			Height:    v.Height,
+			Codecs:    v.Codecs,
+			BaseURL:   v.Uri,
+		}
+		if v.FrameRate > 0 {
+			rep.FrameRate = strconv.FormatFloat(v.FrameRate, 'f', -1, 64)
+		}
+
+		doc.Period.AdaptationSets[idx].Representations = append(doc.Period.AdaptationSets[idx].Representations, rep)
+	}
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("manifest: failed to encode mpd: %w", err)
+	}
+	b.WriteString("\n")
+
+	return b.Bytes(), nil
+}
+
+// isoDuration formats d as an ISO 8601 duration in seconds, e.g. "PT31.5S".
+func isoDuration(d time.Duration) string {
+	return "PT" + strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "S"
+}
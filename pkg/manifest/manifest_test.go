@@ -0,0 +1,93 @@
+package manifest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+var variants = []Variant{
+	{
+		Uri:       "https://cdn.com/video/360p.m3u8",
+		Bandwidth: 800000,
+		Width:     640,
+		Height:    360,
+		Codecs:    "avc1.4d401e,mp4a.40.2",
+		FrameRate: 30,
+	},
+	{
+		Uri:       "https://cdn.com/video/720p.m3u8",
+		Bandwidth: 2800000,
+		Width:     1280,
+		Height:    720,
+		Codecs:    "avc1.4d401f,mp4a.40.2",
+		FrameRate: 29.97,
+	},
+	{
+		Uri:       "https://cdn.com/video/1080p.webm",
+		Bandwidth: 5000000,
+		Width:     1920,
+		Height:    1080,
+		Codecs:    "vp09.00.40.08",
+		MimeType:  "video/webm",
+	},
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestHLSMaster(t *testing.T) {
+	got, err := HLSMaster(variants)
+
+	require.NoError(t, err)
+	assertGolden(t, "master.m3u8.golden", got)
+}
+
+func TestDASH(t *testing.T) {
+	dashVariants := make([]Variant, len(variants))
+	for i, v := range variants {
+		v.Uri = strings.Replace(v.Uri, ".m3u8", ".mp4", 1)
+		dashVariants[i] = v
+	}
+
+	got, err := DASH(dashVariants, 31500*time.Millisecond)
+
+	require.NoError(t, err)
+	assertGolden(t, "manifest.mpd.golden", got)
+}
+
+func TestManifestErrors(t *testing.T) {
+	_, err := HLSMaster(nil)
+	assert.ErrorIs(t, err, ErrNoVariants)
+
+	_, err = DASH(nil, time.Second)
+	assert.ErrorIs(t, err, ErrNoVariants)
+
+	_, err = DASH(variants, 0)
+	assert.ErrorContains(t, err, "duration")
+
+	_, err = HLSMaster([]Variant{{Uri: "https://cdn.com/a.m3u8"}})
+	assert.ErrorContains(t, err, "bandwidth")
+
+	// a quote would end the attribute and a new line start another tag
+	_, err = HLSMaster([]Variant{{Uri: "https://cdn.com/a.m3u8", Bandwidth: 1, Codecs: "avc1\"\n#EXT-X-STREAM-INF:BANDWIDTH=1\nhttps://evil.com/a.m3u8"}})
+	assert.ErrorContains(t, err, "codecs")
+}
@@ -1,6 +1,7 @@
 package serverhttp
 
 import (
+	"github.com/IsaacDSC/search_content/internal/content/health"
 	"github.com/IsaacDSC/search_content/internal/content/infra/api/handler"
 	"github.com/IsaacDSC/search_content/internal/content/infra/container"
 	"github.com/IsaacDSC/search_content/internal/content/reader"
@@ -8,11 +9,11 @@ import (
 	"net/http"
 )
 
-func GetRouters(strategies container.CacheStrategies, wh writer.Handler, rh reader.Handler) *http.ServeMux {
+func GetRouters(strategies container.CacheStrategies, wh writer.Handler, rh reader.Handler, hh health.Handler) *http.ServeMux {
 	m := http.NewServeMux()
 
 	cacheMw := reader.NewCacheMiddleware(strategies.LRUCache)
-	videoHandler := handler.NewHandler(wh, rh)
+	videoHandler := handler.NewHandler(wh, rh, hh)
 
 	for path, fn := range videoHandler.GetRoutes() {
 		m.HandleFunc(path, func(responseWriter http.ResponseWriter, request *http.Request) {
@@ -1,24 +1,49 @@
 package serverhttp
 
 import (
+	"context"
 	"github.com/IsaacDSC/search_content/internal/content/infra/container"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout is how long the requests in progress have to finish
+// once the server is asked to stop.
+const shutdownTimeout = 10 * time.Second
+
+// StartServer serves until it fails or receives SIGINT or SIGTERM, then
+// shuts down gracefully.
 func StartServer(handlers container.Handlers, strategies container.CacheStrategies) error {
-	routers := GetRouters(strategies, handlers.WriterHandler, handlers.ReaderHandler)
+	routers := GetRouters(strategies, handlers.WriterHandler, handlers.ReaderHandler, handlers.HealthHandler)
 
 	server := &http.Server{
 		Addr:    ":8080", //TODO: levar para variavel de ambiente
 		Handler: routers,
 	}
 
-	log.Printf("Server listening on %s", server.Addr)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Printf("Server listening on %s", server.Addr)
+		errs <- server.ListenAndServe()
+	}()
 
-	if err := server.ListenAndServe(); err != nil {
+	select {
+	case err := <-errs:
 		return err
+	case <-ctx.Done():
 	}
 
-	return nil
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	return server.Shutdown(shutdownCtx)
 }
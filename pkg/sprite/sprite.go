@@ -0,0 +1,128 @@
+// Package sprite composes video frames into a sprite sheet, a grid of
+// small images the player crops with the "#xywh=" fragment of a WebVTT
+// track to show previews while seeking.
+package sprite
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"strconv"
+	"time"
+
+	"github.com/IsaacDSC/search_content/pkg/thumbnail"
+	"github.com/IsaacDSC/search_content/pkg/webvtt"
+)
+
+const (
+	DefaultTileWidth = 160
+	DefaultColumns   = 10
+	MinTileWidth     = 32
+	MaxTileWidth     = 640
+	MaxFrames        = 1000
+	// MaxSheetSize is the largest width or height of a sheet, which
+	// browsers decode up to 16384 pixels.
+	MaxSheetSize = 16384
+	// MaxSheetPixels caps the area of a sheet, held in memory at 4 bytes
+	// per pixel while it's composed: 64 MiB, enough for MaxFrames tiles
+	// of the default width.
+	MaxSheetPixels = 4096 * 4096
+)
+
+var ErrInvalidOptions = errors.New("sprite: invalid options")
+
+// Sheet is a grid of equally sized tiles, filled left to right, top to bottom.
+type Sheet struct {
+	image      *image.NRGBA
+	tileWidth  int
+	tileHeight int
+	columns    int
+	count      int
+}
+
+// NewSheet creates a sheet for count frames of frameSize. Tiles are tileWidth
+// wide and keep the aspect ratio of the frames; zero uses the defaults.
+func NewSheet(count, tileWidth, columns int, frameSize image.Point) (*Sheet, error) {
+	if tileWidth == 0 {
+		tileWidth = DefaultTileWidth
+	}
+
+	if columns == 0 {
+		columns = DefaultColumns
+	}
+
+	switch {
+	case count < 1 || count > MaxFrames:
+		return nil, fmt.Errorf("%w: between 1 and %d frames are required", ErrInvalidOptions, MaxFrames)
+	case tileWidth < MinTileWidth || tileWidth > MaxTileWidth:
+		return nil, fmt.Errorf("%w: tile width must be between %d and %d", ErrInvalidOptions, MinTileWidth, MaxTileWidth)
+	case columns < 1:
+		return nil, fmt.Errorf("%w: columns must be positive", ErrInvalidOptions)
+	case frameSize.X <= 0 || frameSize.Y <= 0:
+		return nil, fmt.Errorf("%w: empty frame", ErrInvalidOptions)
+	}
+
+	columns = min(columns, count)
+	rows := (count + columns - 1) / columns
+	tileHeight := max(1, (tileWidth*frameSize.Y+frameSize.X/2)/frameSize.X)
+
+	if columns*tileWidth > MaxSheetSize || rows*tileHeight > MaxSheetSize {
+		return nil, fmt.Errorf("%w: sheet larger than %d pixels", ErrInvalidOptions, MaxSheetSize)
+	}
+
+	if columns*tileWidth*rows*tileHeight > MaxSheetPixels {
+		return nil, fmt.Errorf("%w: sheet of more than %d pixels", ErrInvalidOptions, MaxSheetPixels)
+	}
+
+	return &Sheet{
+		image:      image.NewNRGBA(image.Rect(0, 0, columns*tileWidth, rows*tileHeight)),
+		tileWidth:  tileWidth,
+		tileHeight: tileHeight,
+		columns:    columns,
+		count:      count,
+	}, nil
+}
+
+// Tile returns the region of the sheet of frame i.
+func (s *Sheet) Tile(i int) image.Rectangle {
+	x := (i % s.columns) * s.tileWidth
+	y := (i / s.columns) * s.tileHeight
+
+	return image.Rect(x, y, x+s.tileWidth, y+s.tileHeight)
+}
+
+// Draw scales frame into tile i, cropping it to the tile when the
+// aspect ratio differs from the first frame.
+func (s *Sheet) Draw(i int, frame image.Image) {
+	scaled := thumbnail.Resize(frame, thumbnail.Options{Width: s.tileWidth, Height: s.tileHeight, Fit: thumbnail.FitCover})
+	draw.Draw(s.image, s.Tile(i), scaled, scaled.Bounds().Min, draw.Src)
+}
+
+func (s *Sheet) Image() image.Image {
+	return s.image
+}
+
+// Cues returns the WebVTT cues of the sheet served at spriteUrl, one
+// frame every interval. When duration is known, the last cue ends with
+// the video.
+func (s *Sheet) Cues(spriteUrl string, interval, duration time.Duration) []webvtt.Cue {
+	cues := make([]webvtt.Cue, 0, s.count)
+	for i := range s.count {
+		start := time.Duration(i) * interval
+		end := start + interval
+		if duration > start && end > duration {
+			end = duration
+		}
+
+		tile := s.Tile(i)
+		cues = append(cues, webvtt.Cue{
+			Start: start,
+			End:   end,
+			Text: spriteUrl + "#xywh=" + strconv.Itoa(tile.Min.X) + "," + strconv.Itoa(tile.Min.Y) + "," +
+				strconv.Itoa(tile.Dx()) + "," + strconv.Itoa(tile.Dy()),
+		})
+	}
+
+	return cues
+}
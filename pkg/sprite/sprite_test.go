@@ -0,0 +1,93 @@
+package sprite
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/search_content/pkg/webvtt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frame(c color.Color) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 320, 180))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestSheet(t *testing.T) {
+	colors := []color.NRGBA{
+		{R: 255, A: 255},
+		{G: 255, A: 255},
+		{B: 255, A: 255},
+	}
+
+	sheet, err := NewSheet(len(colors), 80, 2, image.Pt(320, 180))
+	require.NoError(t, err)
+
+	for i, c := range colors {
+		sheet.Draw(i, frame(c))
+	}
+
+	// 2 columns of 80x45 tiles, 2 rows
+	assert.Equal(t, image.Rect(0, 0, 160, 90), sheet.Image().Bounds())
+	assert.Equal(t, image.Rect(80, 0, 160, 45), sheet.Tile(1))
+	assert.Equal(t, image.Rect(0, 45, 80, 90), sheet.Tile(2))
+
+	for i, c := range colors {
+		center := sheet.Tile(i).Min.Add(image.Pt(40, 22))
+		assert.Equal(t, c, sheet.Image().At(center.X, center.Y))
+	}
+
+	cues := sheet.Cues("https://content.com/assets/abc", 2*time.Second, 5*time.Second)
+	assert.Equal(t, []webvtt.Cue{
+		{Start: 0, End: 2 * time.Second, Text: "https://content.com/assets/abc#xywh=0,0,80,45"},
+		{Start: 2 * time.Second, End: 4 * time.Second, Text: "https://content.com/assets/abc#xywh=80,0,80,45"},
+		{Start: 4 * time.Second, End: 5 * time.Second, Text: "https://content.com/assets/abc#xywh=0,45,80,45"},
+	}, cues)
+	assert.NoError(t, webvtt.Validate(webvtt.Encode(cues)))
+}
+
+func TestNewSheet_Defaults(t *testing.T) {
+	sheet, err := NewSheet(25, 0, 0, image.Pt(1920, 1080))
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Rect(0, 0, DefaultColumns*DefaultTileWidth, 3*90), sheet.Image().Bounds())
+}
+
+func TestNewSheet_MaxFramesOfDefaultWidth(t *testing.T) {
+	sheet, err := NewSheet(MaxFrames, 0, 0, image.Pt(1920, 1080))
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Rect(0, 0, 1600, 9000), sheet.Image().Bounds())
+}
+
+func TestNewSheet_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		tileWidth int
+		columns   int
+		size      image.Point
+	}{
+		{name: "no frames", count: 0, size: image.Pt(320, 180)},
+		{name: "too many frames", count: MaxFrames + 1, size: image.Pt(320, 180)},
+		{name: "tile too small", count: 1, tileWidth: 8, size: image.Pt(320, 180)},
+		{name: "tile too large", count: 1, tileWidth: 1000, size: image.Pt(320, 180)},
+		{name: "negative columns", count: 1, columns: -1, size: image.Pt(320, 180)},
+		{name: "empty frame", count: 1, size: image.Pt(0, 0)},
+		{name: "sheet too tall", count: MaxFrames, tileWidth: 640, columns: 1, size: image.Pt(320, 180)},
+		// 16000x14400, each side within MaxSheetSize
+		{name: "sheet too large", count: MaxFrames, tileWidth: 640, columns: 25, size: image.Pt(320, 180)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSheet(tt.count, tt.tileWidth, tt.columns, tt.size)
+			assert.ErrorIs(t, err, ErrInvalidOptions)
+		})
+	}
+}
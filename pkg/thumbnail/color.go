@@ -0,0 +1,53 @@
+package thumbnail
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// DominantColor returns the most common color of img. Colors are grouped
+// in buckets of 4 bits per channel and the winner is the average of its
+// bucket, so noise and gradients don't split the vote. Fully transparent
+// pixels are ignored. Callers should downscale large images first.
+func DominantColor(img image.Image) color.NRGBA {
+	type bucket struct {
+		r, g, b, n int
+	}
+
+	var buckets [1 << 12]bucket
+	best := -1
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A == 0 {
+				continue
+			}
+
+			i := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+			bk := &buckets[i]
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			bk.n++
+
+			if best < 0 || bk.n > buckets[best].n {
+				best = i
+			}
+		}
+	}
+
+	if best < 0 {
+		return color.NRGBA{}
+	}
+
+	bk := buckets[best]
+	return color.NRGBA{R: uint8(bk.r / bk.n), G: uint8(bk.g / bk.n), B: uint8(bk.b / bk.n), A: 0xff}
+}
+
+// HexColor formats c as a CSS "#rrggbb" color.
+func HexColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,223 @@
+// Package thumbnail resizes and crops images in pure Go and encodes
+// them as JPEG or PNG.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // decoders registered for image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+	"strconv"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Fit is how the image is adjusted to the requested box.
+type Fit string
+
+const (
+	// FitCover fills the box, cropping the center of the image.
+	FitCover Fit = "cover"
+	// FitContain fits the image inside the box, keeping its aspect ratio.
+	// The output can be smaller than the box in one dimension.
+	FitContain Fit = "contain"
+	// FitFill stretches the image to the box.
+	FitFill Fit = "fill"
+)
+
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+)
+
+func (f Format) ContentType() string {
+	if f == FormatPNG {
+		return "image/png"
+	}
+
+	return "image/jpeg"
+}
+
+const (
+	DefaultQuality = 80
+	// MaxSourcePixels protects against images that decode to huge bitmaps.
+	MaxSourcePixels = 40_000_000
+)
+
+// DefaultSizes are the widths and heights accepted by Options.Validate.
+var DefaultSizes = []int{64, 100, 128, 160, 200, 240, 320, 400, 480, 640, 800, 960, 1280, 1920}
+
+var (
+	ErrInvalidOptions = errors.New("thumbnail: invalid options")
+	ErrTooLarge       = errors.New("thumbnail: source image too large")
+)
+
+// Options describes the derived image. Width or Height can be zero, in which
+// case it is computed from the other keeping the aspect ratio.
+type Options struct {
+	Width   int
+	Height  int
+	Fit     Fit
+	Quality int // JPEG quality, 1 to 100
+	Format  Format
+}
+
+// Validate checks the options against the allowed sizes and fills the defaults.
+func (o Options) Validate(sizes []int) (Options, error) {
+	if o.Width == 0 && o.Height == 0 {
+		return Options{}, fmt.Errorf("%w: width or height is required", ErrInvalidOptions)
+	}
+
+	for _, v := range []int{o.Width, o.Height} {
+		if v != 0 && !slices.Contains(sizes, v) {
+			return Options{}, fmt.Errorf("%w: size %d is not allowed", ErrInvalidOptions, v)
+		}
+	}
+
+	switch o.Fit {
+	case "":
+		o.Fit = FitCover
+	case FitCover, FitContain, FitFill:
+	default:
+		return Options{}, fmt.Errorf("%w: unknown fit %q", ErrInvalidOptions, o.Fit)
+	}
+
+	switch {
+	case o.Quality == 0:
+		o.Quality = DefaultQuality
+	case o.Quality < 1 || o.Quality > 100:
+		return Options{}, fmt.Errorf("%w: quality must be between 1 and 100", ErrInvalidOptions)
+	}
+
+	switch o.Format {
+	case "", FormatJPEG, FormatPNG:
+	default:
+		return Options{}, fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, o.Format)
+	}
+
+	return o, nil
+}
+
+// Key identifies the output of the options, to be used as a cache key.
+func (o Options) Key() string {
+	return strconv.Itoa(o.Width) + "x" + strconv.Itoa(o.Height) + "-" + string(o.Fit) + "-q" + strconv.Itoa(o.Quality) + "." + string(o.Format)
+}
+
+// Process decodes the image read from r, resizes it and encodes it. When the
+// options have no format, PNG sources stay PNG, keeping their transparency,
+// and anything else becomes JPEG. It returns the format used.
+func Process(r io.Reader, o Options) ([]byte, Format, error) {
+	src, sourceFormat, err := Decode(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	format := o.Format
+	if format == "" {
+		format = FormatJPEG
+		if sourceFormat == "png" {
+			format = FormatPNG
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, Resize(src, o), format, o.Quality); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), format, nil
+}
+
+// Decode decodes the image read from r, refusing sources larger than
+// MaxSourcePixels before allocating them. It returns the source format name.
+func Decode(r io.Reader) (image.Image, string, error) {
+	var head bytes.Buffer
+	cfg, format, err := image.DecodeConfig(io.TeeReader(r, &head))
+	if err != nil {
+		return nil, "", fmt.Errorf("thumbnail: %w", err)
+	}
+
+	if cfg.Width*cfg.Height > MaxSourcePixels {
+		return nil, "", ErrTooLarge
+	}
+
+	img, _, err := image.Decode(io.MultiReader(&head, r))
+	if err != nil {
+		return nil, "", fmt.Errorf("thumbnail: %w", err)
+	}
+
+	return img, format, nil
+}
+
+// Resize scales src to the options with a Catmull-Rom filter.
+func Resize(src image.Image, o Options) image.Image {
+	bounds := src.Bounds()
+	sw, sh := bounds.Dx(), bounds.Dy()
+	if sw == 0 || sh == 0 {
+		return src
+	}
+
+	w, h := o.Width, o.Height
+	switch {
+	case w == 0:
+		w = max(1, divRound(h*sw, sh))
+	case h == 0:
+		h = max(1, divRound(w*sh, sw))
+	}
+
+	crop := bounds
+	switch o.Fit {
+	case FitContain:
+		// shrink the box to the aspect ratio of the source
+		if w*sh > h*sw {
+			w = max(1, divRound(h*sw, sh))
+		} else {
+			h = max(1, divRound(w*sh, sw))
+		}
+	case FitCover, "":
+		// crop the source to the aspect ratio of the box
+		if sw*h > sh*w {
+			cw := divRound(sh*w, h)
+			x := bounds.Min.X + (sw-cw)/2
+			crop = image.Rect(x, bounds.Min.Y, x+cw, bounds.Max.Y)
+		} else {
+			ch := divRound(sw*h, w)
+			y := bounds.Min.Y + (sh-ch)/2
+			crop = image.Rect(bounds.Min.X, y, bounds.Max.X, y+ch)
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+
+	return dst
+}
+
+// divRound divides rounding to the nearest integer.
+func divRound(a, b int) int {
+	return (a + b/2) / b
+}
+
+// Encode writes img in the format. JPEG has no transparency, so
+// transparent pixels are composed over white.
+func Encode(w io.Writer, img image.Image, format Format, quality int) error {
+	if format == FormatPNG {
+		return png.Encode(w, img)
+	}
+
+	bounds := img.Bounds()
+	opaque := image.NewRGBA(bounds)
+	draw.Draw(opaque, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(opaque, bounds, img, bounds.Min, draw.Over)
+
+	return jpeg.Encode(w, opaque, &jpeg.Options{Quality: quality})
+}
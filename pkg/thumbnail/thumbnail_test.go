@@ -0,0 +1,142 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// source is a 400x200 image, red on the left half and blue on the right.
+func source(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 400, 200))
+	for y := 0; y < 200; y++ {
+		for x := 0; x < 400; x++ {
+			c := color.NRGBA{R: 255, A: 255}
+			if x >= 200 {
+				c = color.NRGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+
+	return buf.Bytes()
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		wantWidth  int
+		wantHeight int
+		wantFormat Format
+	}{
+		{name: "width only keeps aspect", opts: Options{Width: 200}, wantWidth: 200, wantHeight: 100, wantFormat: FormatPNG},
+		{name: "height only keeps aspect", opts: Options{Height: 100}, wantWidth: 200, wantHeight: 100, wantFormat: FormatPNG},
+		{name: "cover crops to the box", opts: Options{Width: 100, Height: 100, Fit: FitCover}, wantWidth: 100, wantHeight: 100, wantFormat: FormatPNG},
+		{name: "contain fits inside the box", opts: Options{Width: 100, Height: 100, Fit: FitContain}, wantWidth: 100, wantHeight: 50, wantFormat: FormatPNG},
+		{name: "fill stretches", opts: Options{Width: 100, Height: 100, Fit: FitFill}, wantWidth: 100, wantHeight: 100, wantFormat: FormatPNG},
+		{name: "jpeg output", opts: Options{Width: 200, Format: FormatJPEG, Quality: 70}, wantWidth: 200, wantHeight: 100, wantFormat: FormatJPEG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := tt.opts.Validate(DefaultSizes)
+			require.NoError(t, err)
+
+			out, format, err := Process(bytes.NewReader(source(t)), opts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFormat, format)
+
+			img, decodedFormat, err := image.Decode(bytes.NewReader(out))
+			require.NoError(t, err)
+			assert.Equal(t, string(tt.wantFormat), decodedFormat)
+			assert.Equal(t, tt.wantWidth, img.Bounds().Dx())
+			assert.Equal(t, tt.wantHeight, img.Bounds().Dy())
+		})
+	}
+}
+
+func TestResize_CoverKeepsTheCenter(t *testing.T) {
+	src, _, err := image.Decode(bytes.NewReader(source(t)))
+	require.NoError(t, err)
+
+	img := Resize(src, Options{Width: 100, Height: 100, Fit: FitCover})
+
+	// the center 200x200 square has red on the left and blue on the right
+	r, _, b, _ := img.At(10, 50).RGBA()
+	assert.Greater(t, r, b)
+	r, _, b, _ = img.At(90, 50).RGBA()
+	assert.Greater(t, b, r)
+}
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{name: "no size", opts: Options{}},
+		{name: "size not allowed", opts: Options{Width: 201}},
+		{name: "huge size", opts: Options{Width: 100000}},
+		{name: "unknown fit", opts: Options{Width: 200, Fit: "zoom"}},
+		{name: "quality out of range", opts: Options{Width: 200, Quality: 101}},
+		{name: "unknown format", opts: Options{Width: 200, Format: "gif"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.opts.Validate(DefaultSizes)
+			assert.ErrorIs(t, err, ErrInvalidOptions)
+		})
+	}
+
+	opts, err := Options{Width: 200}.Validate(DefaultSizes)
+	require.NoError(t, err)
+	assert.Equal(t, Options{Width: 200, Fit: FitCover, Quality: DefaultQuality}, opts)
+}
+
+func TestProcess_RejectsHugeSources(t *testing.T) {
+	// only the header is read, the pixels of the source are never decoded
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	header := buf.Bytes()
+	// patch the IHDR width and height to 100000x100000
+	copy(header[16:24], []byte{0, 1, 0x86, 0xa0, 0, 1, 0x86, 0xa0})
+	binary.BigEndian.PutUint32(header[29:33], crc32.ChecksumIEEE(header[12:29]))
+
+	_, _, err := Process(bytes.NewReader(header), Options{Width: 200, Fit: FitCover, Quality: DefaultQuality})
+	assert.ErrorIs(t, err, ErrTooLarge)
+}
+
+func TestDominantColor(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 40; x++ {
+			switch {
+			case x < 10:
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			case x < 25:
+				// slightly noisy red falls in the same bucket
+				img.Set(x, y, color.NRGBA{R: 250 + uint8(x%4), A: 255})
+			}
+			// the rest stays transparent and is ignored
+		}
+	}
+
+	c := DominantColor(img)
+	assert.Equal(t, color.NRGBA{R: 251, A: 255}, c)
+	assert.Equal(t, "#fb0000", HexColor(c))
+
+	assert.Equal(t, color.NRGBA{}, DominantColor(image.NewNRGBA(image.Rect(0, 0, 4, 4))))
+}
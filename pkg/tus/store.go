@@ -0,0 +1,220 @@
+package tus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("tus: upload not found")
+
+var uploadIdPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Upload is the state of a resumable upload.
+type Upload struct {
+	Id        string
+	Length    int64
+	Offset    int64
+	Metadata  map[string]string `json:",omitempty"`
+	ExpiresAt time.Time
+	// Url is where the file is served once the upload is complete.
+	Url string `json:",omitempty"`
+}
+
+func (u Upload) IsComplete() bool {
+	return u.Offset == u.Length
+}
+
+// FileStore keeps each upload as two files in dir: <id>.bin with the bytes
+// received so far and <id>.info with the Upload state as JSON.
+type FileStore struct {
+	dir string
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex // serializes the requests of each upload
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("tus: failed to create upload directory: %w", err)
+	}
+
+	return &FileStore{dir: dir, locks: make(map[string]*sync.Mutex)}, nil
+}
+
+func (s *FileStore) dataPath(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+func (s *FileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
+
+// lock returns the lock of the upload, held while a request changes it.
+func (s *FileStore) lock(id string) *sync.Mutex {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[id] = l
+	}
+
+	return l
+}
+
+func (s *FileStore) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.locks, id)
+}
+
+// Create assigns an id to upload and creates its empty data file.
+func (s *FileStore) Create(upload Upload) (Upload, error) {
+	b := make([]byte, 16)
+	rand.Read(b)
+	upload.Id = hex.EncodeToString(b)
+	upload.Offset = 0
+
+	file, err := os.OpenFile(s.dataPath(upload.Id), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return Upload{}, fmt.Errorf("tus: failed to create upload: %w", err)
+	}
+	file.Close()
+
+	if err := s.Save(upload); err != nil {
+		os.Remove(s.dataPath(upload.Id))
+		return Upload{}, err
+	}
+
+	return upload, nil
+}
+
+func (s *FileStore) Get(id string) (Upload, error) {
+	if !uploadIdPattern.MatchString(id) {
+		return Upload{}, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return Upload{}, ErrNotFound
+	}
+
+	if err != nil {
+		return Upload{}, fmt.Errorf("tus: failed to read upload: %w", err)
+	}
+
+	var upload Upload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return Upload{}, fmt.Errorf("tus: invalid upload info: %w", err)
+	}
+
+	return upload, nil
+}
+
+// Save writes the state of the upload, replacing the previous one atomically.
+func (s *FileStore) Save(upload Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	tmp := s.infoPath(upload.Id) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("tus: failed to save upload: %w", err)
+	}
+
+	if err := os.Rename(tmp, s.infoPath(upload.Id)); err != nil {
+		return fmt.Errorf("tus: failed to save upload: %w", err)
+	}
+
+	return nil
+}
+
+// WriteChunk appends r to the data of the upload, starting at its offset and
+// never going past its length, and returns the number of bytes written.
+// The bytes are also written to w, used to compute checksums.
+func (s *FileStore) WriteChunk(upload Upload, r io.Reader, w io.Writer) (int64, error) {
+	file, err := os.OpenFile(s.dataPath(upload.Id), os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("tus: failed to open upload: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(upload.Offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	var dst io.Writer = file
+	if w != nil {
+		dst = io.MultiWriter(file, w)
+	}
+
+	n, err := io.Copy(dst, io.LimitReader(r, upload.Length-upload.Offset))
+	if err != nil {
+		return n, err
+	}
+
+	return n, file.Sync()
+}
+
+// Truncate discards the bytes after size, e.g. a chunk that failed the checksum.
+func (s *FileStore) Truncate(id string, size int64) error {
+	return os.Truncate(s.dataPath(id), size)
+}
+
+// Open returns the data of the upload.
+func (s *FileStore) Open(id string) (*os.File, error) {
+	return os.Open(s.dataPath(id))
+}
+
+// Delete removes the upload and its data.
+func (s *FileStore) Delete(id string) error {
+	for _, path := range []string{s.dataPath(id), s.infoPath(id)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("tus: failed to delete upload: %w", err)
+		}
+	}
+	s.forget(id)
+
+	return nil
+}
+
+// DeleteData removes only the data of the upload, once it has been moved
+// to its final storage. The state is kept until the upload expires.
+func (s *FileStore) DeleteData(id string) error {
+	err := os.Remove(s.dataPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// List returns the ids of every upload in the store.
+func (s *FileStore) List() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, e := range entries {
+		if id, ok := strings.CutSuffix(e.Name(), ".info"); ok && uploadIdPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
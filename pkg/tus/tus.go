@@ -0,0 +1,477 @@
+// Package tus implements the server side of the tus resumable upload
+// protocol, version 1.0.0 (https://tus.io/protocols/resumable-upload),
+// with the creation, expiration, checksum and termination extensions.
+//
+// Uploads are kept in a FileStore until they are complete, then handed
+// to the Complete callback, which moves them to their final storage.
+package tus
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Version    = "1.0.0"
+	Extensions = "creation,expiration,checksum,termination"
+
+	offsetContentType = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch is the tus status for a chunk that does not match its Upload-Checksum.
+	StatusChecksumMismatch = 460
+)
+
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"md5":    md5.New,
+}
+
+// ChecksumAlgorithms is the value of the Tus-Checksum-Algorithm header.
+const ChecksumAlgorithms = "sha1,sha256,md5"
+
+// CompleteFunc receives the data of a finished upload and returns the url
+// where it is served from then on.
+type CompleteFunc func(ctx context.Context, upload Upload, data io.Reader) (url string, err error)
+
+type Config struct {
+	// BasePath prefixes the id in the Location of new uploads, e.g. "https://content.example.com/uploads/".
+	BasePath string
+	// MaxSize is the largest Upload-Length accepted.
+	MaxSize int64
+	// Expiration is how long an upload is kept after its last chunk.
+	Expiration time.Duration
+	Complete   CompleteFunc
+}
+
+// Handler serves the tus endpoints. The id of the upload is read from the
+// "id" path value, so routes are registered as e.g. "PATCH /uploads/{id}".
+type Handler struct {
+	store *FileStore
+	cfg   Config
+	now   func() time.Time
+}
+
+func NewHandler(store *FileStore, cfg Config) *Handler {
+	return &Handler{store: store, cfg: cfg, now: time.Now}
+}
+
+// Options describes the server capabilities. It does not require the Tus-Resumable header.
+func (h *Handler) Options(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Resumable", Version)
+	w.Header().Set("Tus-Version", Version)
+	w.Header().Set("Tus-Extension", Extensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.cfg.MaxSize, 10))
+	w.Header().Set("Tus-Checksum-Algorithm", ChecksumAlgorithms)
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// Create starts a new upload of Upload-Length bytes.
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	if !h.checkVersion(w, r) {
+		return nil
+	}
+
+	if r.Header.Get("Upload-Defer-Length") != "" {
+		http.Error(w, "Upload-Defer-Length is not supported", http.StatusBadRequest)
+		return nil
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		http.Error(w, "Invalid Upload-Length", http.StatusBadRequest)
+		return nil
+	}
+
+	if length > h.cfg.MaxSize {
+		http.Error(w, "Upload is too large", http.StatusRequestEntityTooLarge)
+		return nil
+	}
+
+	metadata, err := ParseMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	upload, err := h.store.Create(Upload{
+		Length:    length,
+		Metadata:  metadata,
+		ExpiresAt: h.now().Add(h.cfg.Expiration),
+	})
+	if err != nil {
+		http.Error(w, "Failed to create upload", http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Location", h.cfg.BasePath+upload.Id)
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+
+	return nil
+}
+
+// Head returns the offset of the upload, where the client resumes from.
+func (h *Handler) Head(w http.ResponseWriter, r *http.Request) error {
+	if !h.checkVersion(w, r) {
+		return nil
+	}
+
+	upload, ok := h.get(w, r)
+	if !ok {
+		return nil
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.writeState(w, upload)
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if len(upload.Metadata) > 0 {
+		w.Header().Set("Upload-Metadata", FormatMetadata(upload.Metadata))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// Patch writes a chunk at Upload-Offset. When the last byte arrives the
+// upload is completed, and a failed completion is retried by an empty PATCH.
+func (h *Handler) Patch(w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+
+	if !h.checkVersion(w, r) {
+		return nil
+	}
+
+	if r.Header.Get("Content-Type") != offsetContentType {
+		http.Error(w, "Content-Type must be "+offsetContentType, http.StatusUnsupportedMediaType)
+		return nil
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		http.Error(w, "Invalid Upload-Offset", http.StatusBadRequest)
+		return nil
+	}
+
+	checksum, err := parseChecksum(r.Header.Get("Upload-Checksum"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	// checked before locking so unknown ids never get a lock
+	if _, ok := h.get(w, r); !ok {
+		return nil
+	}
+
+	lock := h.store.lock(r.PathValue("id"))
+	if !lock.TryLock() {
+		http.Error(w, "Upload is locked by another request", http.StatusLocked)
+		return nil
+	}
+	defer lock.Unlock()
+
+	upload, ok := h.get(w, r)
+	if !ok {
+		return nil
+	}
+
+	if offset != upload.Offset {
+		http.Error(w, "Upload-Offset does not match the upload", http.StatusConflict)
+		return nil
+	}
+
+	if !upload.IsComplete() {
+		var sum hash.Hash
+		if checksum != nil {
+			sum = checksum.hash()
+		}
+
+		n, err := h.store.WriteChunk(upload, r.Body, sum)
+		if checksum != nil && err == nil && !checksum.matches(sum) {
+			h.store.Truncate(upload.Id, upload.Offset)
+			http.Error(w, "Checksum Mismatch", StatusChecksumMismatch)
+			return nil
+		}
+
+		// without a checksum the bytes received before a broken connection are kept
+		if checksum != nil && err != nil {
+			h.store.Truncate(upload.Id, upload.Offset)
+			n = 0
+		}
+
+		upload.Offset += n
+		upload.ExpiresAt = h.now().Add(h.cfg.Expiration)
+		if saveErr := h.store.Save(upload); saveErr != nil {
+			http.Error(w, "Failed to save upload", http.StatusInternalServerError)
+			return saveErr
+		}
+
+		if err != nil {
+			log.Printf("tus: upload %s interrupted at offset %d: %v", upload.Id, upload.Offset, err)
+			http.Error(w, "Failed to write chunk", http.StatusInternalServerError)
+			return nil
+		}
+	}
+
+	if upload.IsComplete() && upload.Url == "" {
+		if upload, err = h.complete(r.Context(), upload); err != nil {
+			http.Error(w, "Failed to complete upload", http.StatusInternalServerError)
+			return err
+		}
+	}
+
+	h.writeState(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// Delete terminates the upload and discards its data.
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) error {
+	if !h.checkVersion(w, r) {
+		return nil
+	}
+
+	if _, err := h.store.Get(r.PathValue("id")); err != nil {
+		http.NotFound(w, r)
+		return nil
+	}
+
+	lock := h.store.lock(r.PathValue("id"))
+	if !lock.TryLock() {
+		http.Error(w, "Upload is locked by another request", http.StatusLocked)
+		return nil
+	}
+	defer lock.Unlock()
+
+	if err := h.store.Delete(r.PathValue("id")); err != nil {
+		http.Error(w, "Failed to delete upload", http.StatusInternalServerError)
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// Cleanup deletes the uploads that expired and returns how many were removed.
+// The files of completed uploads were already moved, so only their state is removed.
+func (h *Handler) Cleanup() (int, error) {
+	ids, err := h.store.List()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, id := range ids {
+		lock := h.store.lock(id)
+		if !lock.TryLock() {
+			continue // being written right now
+		}
+
+		upload, err := h.store.Get(id)
+		if err == nil && h.now().After(upload.ExpiresAt) {
+			if err = h.store.Delete(id); err == nil {
+				removed++
+			}
+		}
+		lock.Unlock()
+
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// StartCleanup runs Cleanup every interval until stop is called.
+func (h *Handler) StartCleanup(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if n, err := h.Cleanup(); err != nil {
+					log.Printf("tus: failed to clean up expired uploads: %v", err)
+				} else if n > 0 {
+					log.Printf("tus: removed %d expired uploads", n)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	return func() { close(done) }
+}
+
+func (h *Handler) complete(ctx context.Context, upload Upload) (Upload, error) {
+	file, err := h.store.Open(upload.Id)
+	if err != nil {
+		return upload, err
+	}
+	defer file.Close()
+
+	url, err := h.cfg.Complete(ctx, upload, file)
+	if err != nil {
+		return upload, err
+	}
+
+	upload.Url = url
+	if err := h.store.Save(upload); err != nil {
+		return upload, err
+	}
+
+	if err := h.store.DeleteData(upload.Id); err != nil {
+		log.Printf("tus: failed to delete data of completed upload %s: %v", upload.Id, err)
+	}
+
+	return upload, nil
+}
+
+// get loads the upload of the request, answering 404 or 410 when it
+// is unknown or expired.
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) (Upload, bool) {
+	upload, err := h.store.Get(r.PathValue("id"))
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return Upload{}, false
+	}
+
+	if err != nil {
+		log.Printf("tus: %v", err)
+		http.Error(w, "Failed to read upload", http.StatusInternalServerError)
+		return Upload{}, false
+	}
+
+	if !upload.IsComplete() && h.now().After(upload.ExpiresAt) {
+		http.Error(w, "Upload expired", http.StatusGone)
+		return Upload{}, false
+	}
+
+	return upload, true
+}
+
+// writeState sets the offset, the expiration and, once complete,
+// the Content-Location where the file is served.
+func (h *Handler) writeState(w http.ResponseWriter, upload Upload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	if upload.Url != "" {
+		w.Header().Set("Content-Location", upload.Url)
+		return
+	}
+
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+func (h *Handler) checkVersion(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", Version)
+
+	if r.Header.Get("Tus-Resumable") != Version {
+		w.Header().Set("Tus-Version", Version)
+		http.Error(w, "Unsupported tus version", http.StatusPreconditionFailed)
+		return false
+	}
+
+	return true
+}
+
+type checksum struct {
+	hash     func() hash.Hash
+	expected []byte
+}
+
+func (c *checksum) matches(h hash.Hash) bool {
+	return string(h.Sum(nil)) == string(c.expected)
+}
+
+// parseChecksum parses the Upload-Checksum header, "<algorithm> <base64 digest>".
+func parseChecksum(value string) (*checksum, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	algorithm, encoded, ok := strings.Cut(value, " ")
+	if !ok {
+		return nil, errors.New("invalid Upload-Checksum")
+	}
+
+	newHash, ok := checksumAlgorithms[algorithm]
+	if !ok {
+		return nil, fmt.Errorf("unsupported checksum algorithm %q", algorithm)
+	}
+
+	expected, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("invalid Upload-Checksum")
+	}
+
+	return &checksum{hash: newHash, expected: expected}, nil
+}
+
+// ParseMetadata parses the Upload-Metadata header: comma separated pairs
+// of a key and an optional base64 encoded value.
+func ParseMetadata(value string) (map[string]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("invalid Upload-Metadata")
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Upload-Metadata value for %q", key)
+		}
+
+		metadata[key] = string(decoded)
+	}
+
+	return metadata, nil
+}
+
+// FormatMetadata is the inverse of ParseMetadata.
+func FormatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(metadata))
+	for _, k := range keys {
+		if metadata[k] == "" {
+			pairs = append(pairs, k)
+			continue
+		}
+		pairs = append(pairs, k+" "+base64.StdEncoding.EncodeToString([]byte(metadata[k])))
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,227 @@
+package tus
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testServer struct {
+	handler   *Handler
+	mux       *http.ServeMux
+	completed map[string]string
+	now       time.Time
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	ts := &testServer{completed: make(map[string]string), now: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)}
+	ts.handler = NewHandler(store, Config{
+		BasePath:   "/uploads/",
+		MaxSize:    1024,
+		Expiration: time.Hour,
+		Complete: func(_ context.Context, upload Upload, data io.Reader) (string, error) {
+			body, err := io.ReadAll(data)
+			if err != nil {
+				return "", err
+			}
+			ts.completed[upload.Id] = string(body)
+			return "/assets/" + upload.Id, nil
+		},
+	})
+	ts.handler.now = func() time.Time { return ts.now }
+
+	ts.mux = http.NewServeMux()
+	wrap := func(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { fn(w, r) }
+	}
+	ts.mux.HandleFunc("OPTIONS /uploads", wrap(ts.handler.Options))
+	ts.mux.HandleFunc("POST /uploads", wrap(ts.handler.Create))
+	ts.mux.HandleFunc("HEAD /uploads/{id}", wrap(ts.handler.Head))
+	ts.mux.HandleFunc("PATCH /uploads/{id}", wrap(ts.handler.Patch))
+	ts.mux.HandleFunc("DELETE /uploads/{id}", wrap(ts.handler.Delete))
+
+	return ts
+}
+
+func (ts *testServer) do(method, path string, header http.Header, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Tus-Resumable", Version)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	rec := httptest.NewRecorder()
+	ts.mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func (ts *testServer) create(t *testing.T, length int) string {
+	t.Helper()
+
+	rec := ts.do(http.MethodPost, "/uploads", http.Header{
+		"Upload-Length":   {strconv.Itoa(length)},
+		"Upload-Metadata": {"filename " + base64.StdEncoding.EncodeToString([]byte("video.mp4")) + ",is_public"},
+	}, "")
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	return rec.Header().Get("Location")
+}
+
+func patchHeader(offset int) http.Header {
+	return http.Header{
+		"Content-Type":  {offsetContentType},
+		"Upload-Offset": {strconv.Itoa(offset)},
+	}
+}
+
+func TestHandler_ResumableUpload(t *testing.T) {
+	ts := newTestServer(t)
+	location := ts.create(t, 10)
+	id := strings.TrimPrefix(location, "/uploads/")
+
+	rec := ts.do(http.MethodPatch, location, patchHeader(0), "hello")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+
+	// the client lost the connection and asks where to resume from
+	rec = ts.do(http.MethodHead, location, nil, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+	assert.Equal(t, "10", rec.Header().Get("Upload-Length"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "filename dmlkZW8ubXA0,is_public", rec.Header().Get("Upload-Metadata"))
+
+	rec = ts.do(http.MethodPatch, location, patchHeader(0), "hello")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = ts.do(http.MethodPatch, location, patchHeader(5), "world and more")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "10", rec.Header().Get("Upload-Offset"))
+	assert.Equal(t, "/assets/"+id, rec.Header().Get("Content-Location"))
+	assert.Equal(t, "helloworld", ts.completed[id])
+
+	rec = ts.do(http.MethodHead, location, nil, "")
+	assert.Equal(t, "/assets/"+id, rec.Header().Get("Content-Location"))
+}
+
+func TestHandler_Checksum(t *testing.T) {
+	ts := newTestServer(t)
+	location := ts.create(t, 5)
+
+	sum := sha1.Sum([]byte("hello"))
+	valid := "sha1 " + base64.StdEncoding.EncodeToString(sum[:])
+
+	header := patchHeader(0)
+	header.Set("Upload-Checksum", valid)
+	rec := ts.do(http.MethodPatch, location, header, "hellO")
+	assert.Equal(t, StatusChecksumMismatch, rec.Code)
+
+	rec = ts.do(http.MethodHead, location, nil, "")
+	assert.Equal(t, "0", rec.Header().Get("Upload-Offset"))
+
+	rec = ts.do(http.MethodPatch, location, header, "hello")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Upload-Offset"))
+
+	header.Set("Upload-Checksum", "crc32 AAAA")
+	rec = ts.do(http.MethodPatch, location, header, "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_Expiration(t *testing.T) {
+	ts := newTestServer(t)
+	location := ts.create(t, 10)
+	done := ts.create(t, 2)
+	ts.do(http.MethodPatch, done, patchHeader(0), "ok")
+
+	ts.now = ts.now.Add(2 * time.Hour)
+
+	rec := ts.do(http.MethodPatch, location, patchHeader(0), "hello")
+	assert.Equal(t, http.StatusGone, rec.Code)
+
+	removed, err := ts.handler.Cleanup()
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	rec = ts.do(http.MethodHead, location, nil, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_Protocol(t *testing.T) {
+	ts := newTestServer(t)
+
+	rec := ts.do(http.MethodOptions, "/uploads", nil, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, Extensions, rec.Header().Get("Tus-Extension"))
+	assert.Equal(t, "1024", rec.Header().Get("Tus-Max-Size"))
+
+	rec = ts.do(http.MethodPost, "/uploads", http.Header{"Tus-Resumable": {"0.2.2"}, "Upload-Length": {"1"}}, "")
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, Version, rec.Header().Get("Tus-Version"))
+
+	rec = ts.do(http.MethodPost, "/uploads", http.Header{"Upload-Length": {"2048"}}, "")
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	location := ts.create(t, 4)
+
+	rec = ts.do(http.MethodPatch, location, http.Header{"Upload-Offset": {"0"}}, "data")
+	assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+
+	rec = ts.do(http.MethodDelete, location, nil, "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = ts.do(http.MethodHead, location, nil, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = ts.do(http.MethodHead, "/uploads/../../etc", nil, "")
+	assert.NotEqual(t, http.StatusOK, rec.Code)
+}
+
+func TestParseMetadata(t *testing.T) {
+	metadata, err := ParseMetadata("filename dmlkZW8ubXA0, is_public")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"filename": "video.mp4", "is_public": ""}, metadata)
+
+	_, err = ParseMetadata("filename not-base64!")
+	assert.Error(t, err)
+}
+
+func TestHandler_CompleteRetry(t *testing.T) {
+	ts := newTestServer(t)
+
+	fail := true
+	complete := ts.handler.cfg.Complete
+	ts.handler.cfg.Complete = func(ctx context.Context, upload Upload, data io.Reader) (string, error) {
+		if fail {
+			return "", errors.New("storage unavailable")
+		}
+		return complete(ctx, upload, data)
+	}
+
+	location := ts.create(t, 4)
+
+	rec := ts.do(http.MethodPatch, location, patchHeader(0), "data")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	fail = false
+	rec = ts.do(http.MethodPatch, location, patchHeader(4), "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Content-Location"))
+}
@@ -0,0 +1,198 @@
+// Package urlsign signs urls with an expiry and an HMAC-SHA256 signature,
+// and verifies them. It is shared by the content service, which signs the
+// video urls it returns, and by the edge, which verifies them.
+//
+// The signature covers the host, the path and every query parameter except
+// the signature itself, so neither the expiry nor the key id can be changed.
+// The scheme is left out, letting the same url be served over http and https.
+package urlsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrMissingSignature = errors.New("urlsign: missing signature or expiry")
+	ErrExpired          = errors.New("urlsign: url expired")
+	ErrUnknownKey       = errors.New("urlsign: unknown key")
+	ErrInvalidSignature = errors.New("urlsign: invalid signature")
+)
+
+// Key is a secret identified by Id. The id travels in the url so the
+// verifier knows which of its active keys to use.
+type Key struct {
+	Id     string
+	Secret []byte
+}
+
+// Params are the names of the query parameters added to signed urls.
+type Params struct {
+	Expires   string
+	Signature string
+	KeyId     string
+}
+
+// DefaultParams are used for the names left empty.
+var DefaultParams = Params{Expires: "exp", Signature: "sig", KeyId: "kid"}
+
+func (p Params) withDefaults() Params {
+	if p.Expires == "" {
+		p.Expires = DefaultParams.Expires
+	}
+	if p.Signature == "" {
+		p.Signature = DefaultParams.Signature
+	}
+	if p.KeyId == "" {
+		p.KeyId = DefaultParams.KeyId
+	}
+
+	return p
+}
+
+func (p Params) validate() error {
+	if p.Expires == p.Signature || p.Expires == p.KeyId || p.Signature == p.KeyId {
+		return errors.New("urlsign: query parameter names must be distinct")
+	}
+
+	return nil
+}
+
+// Signer signs urls with a single key, valid for ttl.
+type Signer struct {
+	key    Key
+	ttl    time.Duration
+	params Params
+}
+
+func NewSigner(key Key, ttl time.Duration, params Params) (*Signer, error) {
+	if key.Id == "" || len(key.Secret) == 0 {
+		return nil, errors.New("urlsign: key id and secret are required")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("urlsign: ttl must be positive")
+	}
+
+	params = params.withDefaults()
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Signer{key: key, ttl: ttl, params: params}, nil
+}
+
+// Sign returns rawUrl with the expiry, key id and signature parameters,
+// replacing them if rawUrl was already signed.
+func (s *Signer) Sign(rawUrl string) (string, error) {
+	return s.SignAt(rawUrl, time.Now())
+}
+
+func (s *Signer) SignAt(rawUrl string, now time.Time) (string, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", fmt.Errorf("urlsign: %w", err)
+	}
+
+	if u.Host == "" {
+		return "", fmt.Errorf("urlsign: url %q is not absolute", rawUrl)
+	}
+
+	query := u.Query()
+	query.Del(s.params.Signature)
+	query.Set(s.params.Expires, strconv.FormatInt(now.Add(s.ttl).Unix(), 10))
+	query.Set(s.params.KeyId, s.key.Id)
+	query.Set(s.params.Signature, sign(s.key.Secret, u, query))
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// Verifier checks signed urls against a set of active keys. During a key
+// rotation both the new and the old keys are active, so urls signed before
+// the rotation stay valid until they expire.
+type Verifier struct {
+	keys   map[string]Key
+	params Params
+}
+
+func NewVerifier(params Params, keys ...Key) (*Verifier, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("urlsign: at least one key is required")
+	}
+
+	params = params.withDefaults()
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]Key, len(keys))
+	for _, k := range keys {
+		if k.Id == "" || len(k.Secret) == 0 {
+			return nil, errors.New("urlsign: key id and secret are required")
+		}
+		if _, ok := byId[k.Id]; ok {
+			return nil, fmt.Errorf("urlsign: duplicated key %q", k.Id)
+		}
+		byId[k.Id] = k
+	}
+
+	return &Verifier{keys: byId, params: params}, nil
+}
+
+func (v *Verifier) Verify(u *url.URL) error {
+	return v.VerifyAt(u, time.Now())
+}
+
+func (v *Verifier) VerifyAt(u *url.URL, now time.Time) error {
+	query := u.Query()
+
+	signature := query.Get(v.params.Signature)
+	expires := query.Get(v.params.Expires)
+	if signature == "" || expires == "" {
+		return ErrMissingSignature
+	}
+
+	key, ok := v.keys[query.Get(v.params.KeyId)]
+	if !ok {
+		return ErrUnknownKey
+	}
+
+	// the signature is checked before the expiry so a forged expiry
+	// is reported as invalid rather than expired
+	query.Del(v.params.Signature)
+	expected := sign(key.Secret, u, query)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if !now.Before(time.Unix(unix, 0)) {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+// sign computes the signature of u with query, which must not
+// contain the signature parameter.
+func sign(secret []byte, u *url.URL, query url.Values) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(u.Host))
+	mac.Write([]byte(u.EscapedPath()))
+	mac.Write([]byte{'?'})
+	mac.Write([]byte(query.Encode())) // Encode sorts by key
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package urlsign
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	now    = time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	oldKey = Key{Id: "2024-09", Secret: []byte("old-secret")}
+	newKey = Key{Id: "2024-10", Secret: []byte("new-secret")}
+)
+
+func signAt(t *testing.T, key Key, rawUrl string, at time.Time) *url.URL {
+	t.Helper()
+
+	signer, err := NewSigner(key, time.Hour, Params{})
+	require.NoError(t, err)
+
+	signed, err := signer.SignAt(rawUrl, at)
+	require.NoError(t, err)
+
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+
+	return u
+}
+
+func TestSignAt(t *testing.T) {
+	u := signAt(t, newKey, "https://cdn.example.com/videos/a.mp4?quality=hd", now)
+
+	query := u.Query()
+	assert.Equal(t, "hd", query.Get("quality"))
+	assert.Equal(t, "1727787600", query.Get("exp"))
+	assert.Equal(t, "2024-10", query.Get("kid"))
+	assert.NotEmpty(t, query.Get("sig"))
+}
+
+func TestVerifyAt(t *testing.T) {
+	verifier, err := NewVerifier(Params{}, newKey, oldKey)
+	require.NoError(t, err)
+
+	tamper := func(u *url.URL, name, value string) *url.URL {
+		copied := *u
+		query := copied.Query()
+		query.Set(name, value)
+		copied.RawQuery = query.Encode()
+		return &copied
+	}
+
+	signed := signAt(t, newKey, "https://cdn.example.com/videos/a.mp4?quality=hd", now)
+
+	tests := []struct {
+		name    string
+		url     *url.URL
+		at      time.Time
+		wantErr error
+	}{
+		{name: "valid", url: signed, at: now.Add(59 * time.Minute)},
+		{name: "http and https share the signature", url: func() *url.URL { c := *signed; c.Scheme = "http"; return &c }(), at: now},
+		{name: "signed with a rotated key", url: signAt(t, oldKey, "https://cdn.example.com/videos/a.mp4", now), at: now},
+		{name: "expired", url: signed, at: now.Add(time.Hour), wantErr: ErrExpired},
+		{name: "extended expiry", url: tamper(signed, "exp", "1893456000"), at: now, wantErr: ErrInvalidSignature},
+		{name: "changed parameter", url: tamper(signed, "quality", "4k"), at: now, wantErr: ErrInvalidSignature},
+		{name: "changed path", url: func() *url.URL { c := *signed; c.Path = "/videos/b.mp4"; return &c }(), at: now, wantErr: ErrInvalidSignature},
+		{name: "swapped key id", url: tamper(signed, "kid", oldKey.Id), at: now, wantErr: ErrInvalidSignature},
+		{name: "retired key", url: signAt(t, Key{Id: "2024-08", Secret: []byte("x")}, "https://cdn.example.com/a.mp4", now), at: now, wantErr: ErrUnknownKey},
+		{name: "unsigned", url: &url.URL{Scheme: "https", Host: "cdn.example.com", Path: "/a.mp4"}, at: now, wantErr: ErrMissingSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifier.VerifyAt(tt.url, tt.at)
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSignAt_ResignReplacesParams(t *testing.T) {
+	first := signAt(t, oldKey, "https://cdn.example.com/a.mp4", now)
+	second := signAt(t, newKey, first.String(), now.Add(time.Minute))
+
+	verifier, err := NewVerifier(Params{}, newKey)
+	require.NoError(t, err)
+
+	assert.Len(t, second.Query()["sig"], 1)
+	assert.NoError(t, verifier.VerifyAt(second, now.Add(time.Minute)))
+}
+
+func TestCustomParams(t *testing.T) {
+	params := Params{Expires: "Expires", Signature: "Signature", KeyId: "Key-Pair-Id"}
+
+	signer, err := NewSigner(newKey, time.Minute, params)
+	require.NoError(t, err)
+
+	signed, err := signer.SignAt("https://cdn.example.com/a.mp4", now)
+	require.NoError(t, err)
+
+	u, _ := url.Parse(signed)
+	assert.NotEmpty(t, u.Query().Get("Signature"))
+
+	verifier, err := NewVerifier(params, newKey)
+	require.NoError(t, err)
+	assert.NoError(t, verifier.VerifyAt(u, now))
+
+	_, err = NewSigner(newKey, time.Minute, Params{Expires: "sig"})
+	assert.Error(t, err)
+}
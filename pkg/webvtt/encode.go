@@ -0,0 +1,40 @@
+package webvtt
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// Cue is a timed block of text of a track.
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// Encode writes the cues as a WebVTT file.
+func Encode(cues []Cue) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("WEBVTT\n")
+
+	for _, c := range cues {
+		fmt.Fprintf(&buf, "\n%s --> %s\n%s\n", FormatTimestamp(c.Start), FormatTimestamp(c.End), c.Text)
+	}
+
+	return buf.Bytes()
+}
+
+// FormatTimestamp formats d as "hh:mm:ss.ttt".
+func FormatTimestamp(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, d/time.Millisecond)
+}
@@ -0,0 +1,230 @@
+// Package webvtt validates and writes WebVTT (Web Video Text Tracks) files
+// following the syntax of https://www.w3.org/TR/webvtt1/.
+package webvtt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxSize is the largest track accepted by Validate.
+const MaxSize = 1 << 20 // 1MB
+
+var (
+	ErrEmpty    = errors.New("webvtt: empty file")
+	ErrTooLarge = fmt.Errorf("webvtt: file larger than %d bytes", MaxSize)
+	ErrEncoding = errors.New("webvtt: file is not valid UTF-8")
+
+	timestampPattern = regexp.MustCompile(`^(?:(\d{2,}):)?([0-5]\d):([0-5]\d)\.(\d{3})$`)
+)
+
+// SyntaxError reports the line where the track is malformed.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("webvtt: line %d: %s", e.Line, e.Msg)
+}
+
+// Validate checks that body is a well formed WebVTT file: the signature,
+// the header, and for each cue a valid timing line with end after start and
+// start times in non-decreasing order. NOTE, STYLE and REGION blocks are accepted.
+func Validate(body []byte) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ErrEmpty
+	}
+
+	if len(body) > MaxSize {
+		return ErrTooLarge
+	}
+
+	if !utf8.Valid(body) {
+		return ErrEncoding
+	}
+
+	body = bytes.TrimPrefix(body, []byte("\uFEFF"))
+	body = bytes.ReplaceAll(body, []byte("\r\n"), []byte("\n"))
+	body = bytes.ReplaceAll(body, []byte("\r"), []byte("\n"))
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxSize)
+
+	p := &parser{scanner: scanner}
+
+	return p.parse()
+}
+
+type parser struct {
+	scanner   *bufio.Scanner
+	line      int
+	lastStart time.Duration
+	seenCue   bool
+}
+
+func (p *parser) next() (string, bool) {
+	if !p.scanner.Scan() {
+		return "", false
+	}
+	p.line++
+
+	return p.scanner.Text(), true
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parse() error {
+	signature, _ := p.next()
+	if signature != "WEBVTT" && !strings.HasPrefix(signature, "WEBVTT ") && !strings.HasPrefix(signature, "WEBVTT\t") {
+		return p.errorf("missing WEBVTT signature")
+	}
+
+	// header lines go until the first blank line
+	for {
+		line, ok := p.next()
+		if !ok {
+			return p.scanner.Err()
+		}
+		if line == "" {
+			break
+		}
+		if strings.Contains(line, "-->") {
+			return p.errorf("cue found before the blank line that ends the header")
+		}
+	}
+
+	for {
+		line, ok := p.next()
+		if !ok {
+			return p.scanner.Err()
+		}
+
+		if line == "" {
+			continue
+		}
+
+		if err := p.block(line); err != nil {
+			return err
+		}
+	}
+}
+
+// block parses a block that starts at line and consumes it until the next blank line.
+func (p *parser) block(first string) error {
+	switch {
+	case first == "NOTE" || strings.HasPrefix(first, "NOTE ") || strings.HasPrefix(first, "NOTE\t"):
+		return p.skipBlock(false)
+	case first == "STYLE" || first == "REGION":
+		if p.seenCue {
+			return p.errorf("%s block after the first cue", first)
+		}
+		return p.skipBlock(true)
+	}
+
+	timing := first
+	if !strings.Contains(first, "-->") {
+		// the first line is the cue identifier
+		line, ok := p.next()
+		if !ok || line == "" {
+			return p.errorf("cue identifier %q without timing line", first)
+		}
+		timing = line
+	}
+
+	if err := p.timing(timing); err != nil {
+		return err
+	}
+	p.seenCue = true
+
+	for {
+		line, ok := p.next()
+		if !ok || line == "" {
+			return p.scanner.Err()
+		}
+		if strings.Contains(line, "-->") {
+			return p.errorf("cue payload must not contain \"-->\"")
+		}
+	}
+}
+
+func (p *parser) skipBlock(rejectArrow bool) error {
+	for {
+		line, ok := p.next()
+		if !ok || line == "" {
+			return p.scanner.Err()
+		}
+		if rejectArrow && strings.Contains(line, "-->") {
+			return p.errorf("block must not contain \"-->\"")
+		}
+	}
+}
+
+func (p *parser) timing(line string) error {
+	startRaw, rest, found := strings.Cut(line, "-->")
+	if !found {
+		return p.errorf("invalid cue timing %q", line)
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return p.errorf("missing cue end time")
+	}
+
+	start, err := parseTimestamp(strings.TrimSpace(startRaw))
+	if err != nil {
+		return p.errorf("invalid cue start time: %v", err)
+	}
+
+	end, err := parseTimestamp(fields[0])
+	if err != nil {
+		return p.errorf("invalid cue end time: %v", err)
+	}
+
+	if end <= start {
+		return p.errorf("cue end time must be after its start time")
+	}
+
+	if start < p.lastStart {
+		return p.errorf("cue starts before the previous cue")
+	}
+	p.lastStart = start
+
+	for _, setting := range fields[1:] {
+		name, value, ok := strings.Cut(setting, ":")
+		if !ok || name == "" || value == "" {
+			return p.errorf("invalid cue setting %q", setting)
+		}
+	}
+
+	return nil
+}
+
+func parseTimestamp(value string) (time.Duration, error) {
+	m := timestampPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, fmt.Errorf("%q is not a timestamp", value)
+	}
+
+	var hours int
+	if m[1] != "" {
+		hours, _ = strconv.Atoi(m[1])
+	}
+	minutes, _ := strconv.Atoi(m[2])
+	seconds, _ := strconv.Atoi(m[3])
+	millis, _ := strconv.Atoi(m[4])
+
+	return time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds)*time.Second +
+		time.Duration(millis)*time.Millisecond, nil
+}
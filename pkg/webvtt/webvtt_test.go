@@ -0,0 +1,93 @@
+package webvtt
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantErr   error
+		wantLine  int
+		wantValid bool
+	}{
+		{
+			name:      "minimal file",
+			body:      "WEBVTT\n",
+			wantValid: true,
+		},
+		{
+			name: "cues with identifiers, settings and notes",
+			body: "\uFEFFWEBVTT - Legendas\r\nKind: captions\r\n\r\n" +
+				"STYLE\n::cue { color: yellow }\n\n" +
+				"NOTE revisado\n\n" +
+				"intro\n00:00.000 --> 00:02.500 align:start line:90%\nOlá!\n\n" +
+				"00:00:02.500 --> 00:00:05.000\n<v Ana>Tudo bem?\nSim.\n",
+			wantValid: true,
+		},
+		{
+			name:      "hours with more than two digits",
+			body:      "WEBVTT\n\n100:00:00.000 --> 100:00:01.000\nlong\n",
+			wantValid: true,
+		},
+		{name: "empty", body: "  \n", wantErr: ErrEmpty},
+		{name: "invalid utf-8", body: "WEBVTT\n\n\xff", wantErr: ErrEncoding},
+		{name: "missing signature", body: "00:00.000 --> 00:01.000\nhi\n", wantLine: 1},
+		{name: "signature without separator", body: "WEBVTTX\n", wantLine: 1},
+		{name: "cue inside header", body: "WEBVTT\n00:00.000 --> 00:01.000\n", wantLine: 2},
+		{name: "malformed timestamp", body: "WEBVTT\n\n00:00 --> 00:01.000\nhi\n", wantLine: 3},
+		{name: "minutes out of range", body: "WEBVTT\n\n00:61.000 --> 01:01.000\nhi\n", wantLine: 3},
+		{name: "end before start", body: "WEBVTT\n\n00:02.000 --> 00:01.000\nhi\n", wantLine: 3},
+		{
+			name:     "cues out of order",
+			body:     "WEBVTT\n\n00:05.000 --> 00:06.000\na\n\n00:01.000 --> 00:02.000\nb\n",
+			wantLine: 6,
+		},
+		{name: "identifier without timing", body: "WEBVTT\n\nintro\n\n", wantLine: 4},
+		{name: "arrow in payload", body: "WEBVTT\n\n00:00.000 --> 00:01.000\na --> b\n", wantLine: 4},
+		{name: "invalid setting", body: "WEBVTT\n\n00:00.000 --> 00:01.000 align\nhi\n", wantLine: 3},
+		{
+			name:     "style after cue",
+			body:     "WEBVTT\n\n00:00.000 --> 00:01.000\nhi\n\nSTYLE\n::cue {}\n",
+			wantLine: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate([]byte(tt.body))
+
+			if tt.wantValid {
+				assert.NoError(t, err)
+				return
+			}
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			var syntaxErr *SyntaxError
+			if assert.True(t, errors.As(err, &syntaxErr), "expected syntax error, got %v", err) {
+				assert.Equal(t, tt.wantLine, syntaxErr.Line)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	body := Encode([]Cue{
+		{Start: 0, End: 2 * time.Second, Text: "sprite.jpg#xywh=0,0,160,90"},
+		{Start: 2 * time.Second, End: time.Hour + 61*time.Second + 500*time.Millisecond, Text: "sprite.jpg#xywh=160,0,160,90"},
+	})
+
+	assert.Equal(t, "WEBVTT\n\n"+
+		"00:00:00.000 --> 00:00:02.000\nsprite.jpg#xywh=0,0,160,90\n\n"+
+		"00:00:02.000 --> 01:01:01.500\nsprite.jpg#xywh=160,0,160,90\n", string(body))
+	assert.NoError(t, Validate(body))
+}